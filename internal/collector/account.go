@@ -0,0 +1,57 @@
+package collector
+
+import (
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	unitSystems        = []string{"metric", "imperial"}
+	unitsWind          = []string{"kph", "mph", "ms", "beaufort", "knot"}
+	unitsPressure      = []string{"mbar", "inhg", "mmhg"}
+	feelLikeAlgorithms = []string{"humidex", "heat_index"}
+)
+
+// homesUser contains the settings of the account returned in the user block of homesdata. The e-mail address is
+// not decoded, so that it can not end up in a metric.
+type homesUser struct {
+	UnitSystem        *int `json:"unit_system"`
+	UnitWind          *int `json:"unit_wind"`
+	UnitPressure      *int `json:"unit_pressure"`
+	FeelLikeAlgorithm *int `json:"feel_like_algorithm"`
+}
+
+// settingName returns the name of a setting, the number if it is not in names or an empty string if the setting
+// is not set.
+func settingName(names []string, value *int) string {
+	switch {
+	case value == nil:
+		return ""
+	case *value >= 0 && *value < len(names):
+		return names[*value]
+	default:
+		return strconv.Itoa(*value)
+	}
+}
+
+// collectAccount reports the settings of the account from the last successful homesdata request.
+func (c *ThermostatCollector) collectAccount(ch chan<- prometheus.Metric) {
+	c.homesLock.Lock()
+	user := c.cachedUser
+	c.homesLock.Unlock()
+
+	if user == nil {
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		c.descs.accountInfo,
+		prometheus.GaugeValue,
+		1,
+		settingName(unitSystems, user.UnitSystem),
+		settingName(unitsWind, user.UnitWind),
+		settingName(unitsPressure, user.UnitPressure),
+		settingName(feelLikeAlgorithms, user.FeelLikeAlgorithm),
+	)
+}
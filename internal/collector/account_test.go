@@ -0,0 +1,50 @@
+package collector
+
+import (
+	"testing"
+)
+
+func TestSettingName(t *testing.T) {
+	intPtr := func(i int) *int {
+		return &i
+	}
+
+	tt := []struct {
+		desc  string
+		value *int
+		want  string
+	}{
+		{
+			desc:  "known value",
+			value: intPtr(1),
+			want:  "mph",
+		},
+		{
+			desc:  "unknown value",
+			value: intPtr(7),
+			want:  "7",
+		},
+		{
+			desc:  "negative value",
+			value: intPtr(-1),
+			want:  "-1",
+		},
+		{
+			desc:  "not set",
+			value: nil,
+			want:  "",
+		},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			got := settingName(unitsWind, tc.value)
+			if got != tc.want {
+				t.Errorf("got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,294 @@
+package collector
+
+import (
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	apiRequestsDesc = newDesc(
+		prefix+"api_requests_total",
+		"Number of requests made to the NetAtmo API by endpoint.",
+		[]string{"endpoint"},
+	)
+
+	apiResponsesDesc = newDesc(
+		prefix+"api_responses_total",
+		"Number of responses from the NetAtmo API by endpoint and HTTP status code. Requests failing without a response use the code 0.",
+		[]string{"endpoint", "code"},
+	)
+
+	apiRetriesDesc = newDesc(
+		prefix+"api_retries_total",
+		"Number of requests to the NetAtmo API retried after a transient error by endpoint.",
+		[]string{"endpoint"},
+	)
+
+	apiRateLimitRemainingDesc = newDesc(
+		prefix+"api_rate_limit_remaining",
+		"Number of requests remaining in the current rate-limit window as reported by the last NetAtmo API response.",
+		nil,
+	)
+
+	apiDeprecatedDesc = newDesc(
+		prefix+"api_deprecated",
+		"Contains 1 if the last response of the NetAtmo API endpoint contained a deprecation notice, 0 otherwise.",
+		[]string{"endpoint"},
+	)
+
+	tokenRefreshFailingDesc = newDesc(
+		prefix+"token_refresh_failing",
+		"Contains 1 if requests to the NetAtmo API are repeatedly skipped, because the token has expired and is not refreshed, 0 otherwise.",
+		nil,
+	)
+
+	// rateLimitRemainingHeaders contains the headers which are checked for the number of remaining requests.
+	rateLimitRemainingHeaders = []string{"X-RateLimit-Remaining", "RateLimit-Remaining"}
+
+	// deprecationHeaders contains the headers announcing the deprecation (RFC 9745) or removal (RFC 8594) of an
+	// endpoint. A "Warning" header is only treated as a deprecation notice if it mentions the deprecation.
+	deprecationHeaders = []string{"Deprecation", "Sunset"}
+)
+
+// tokenExpiredThreshold is the number of requests in a row skipped because of an expired token after which the
+// token refresh is considered to be failing.
+const tokenExpiredThreshold = 5
+
+// APIStats counts the requests made to the NetAtmo API and keeps the rate-limit reported in the responses.
+// The NetAtmo API does not document rate-limit headers, so the request counter is always reported as well.
+type APIStats struct {
+	log logrus.FieldLogger
+
+	lock        sync.Mutex
+	requests    map[string]float64
+	responses   map[responseKey]float64
+	retries     map[string]float64
+	remaining   *float64
+	seenHeaders map[string]bool
+	deprecated  map[string]bool
+	warned      map[string]bool
+
+	// expiredTokens counts the requests skipped in a row, because the token had expired.
+	expiredTokens int
+
+	perHomeLatency bool
+	durations      *prometheus.HistogramVec
+}
+
+type responseKey struct {
+	endpoint string
+	code     int
+}
+
+// NewAPIStats creates a new APIStats. If perHomeLatency is set, the duration of homestatus requests is additionally
+// labeled with the ID of the home, which creates one histogram per home.
+func NewAPIStats(log logrus.FieldLogger, perHomeLatency bool) *APIStats {
+	labels := []string{"endpoint"}
+	if perHomeLatency {
+		labels = append(labels, "home_id")
+	}
+
+	return &APIStats{
+		log:            log,
+		requests:       map[string]float64{},
+		responses:      map[responseKey]float64{},
+		retries:        map[string]float64{},
+		seenHeaders:    map[string]bool{},
+		deprecated:     map[string]bool{},
+		warned:         map[string]bool{},
+		perHomeLatency: perHomeLatency,
+		durations: newHistogramVec(
+			prefix+"api_request_duration_seconds",
+			"Duration of requests to the NetAtmo API in seconds by endpoint, including requests failing without a response.",
+			prometheus.DefBuckets,
+			labels,
+		),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (s *APIStats) Describe(ch chan<- *prometheus.Desc) {
+	ch <- apiRequestsDesc
+	ch <- apiResponsesDesc
+	ch <- apiRetriesDesc
+	ch <- apiRateLimitRemainingDesc
+	ch <- apiDeprecatedDesc
+	ch <- tokenRefreshFailingDesc
+	s.durations.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (s *APIStats) Collect(ch chan<- prometheus.Metric) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for endpoint, count := range s.requests {
+		ch <- prometheus.MustNewConstMetric(apiRequestsDesc, prometheus.CounterValue, count, endpoint)
+	}
+
+	for key, count := range s.responses {
+		ch <- prometheus.MustNewConstMetric(apiResponsesDesc, prometheus.CounterValue, count, key.endpoint, strconv.Itoa(key.code))
+	}
+
+	for endpoint, count := range s.retries {
+		ch <- prometheus.MustNewConstMetric(apiRetriesDesc, prometheus.CounterValue, count, endpoint)
+	}
+
+	for endpoint, deprecated := range s.deprecated {
+		value := 0.0
+		if deprecated {
+			value = 1.0
+		}
+		ch <- prometheus.MustNewConstMetric(apiDeprecatedDesc, prometheus.GaugeValue, value, endpoint)
+	}
+
+	sendOptional(ch, apiRateLimitRemainingDesc, s.remaining)
+
+	refreshFailing := 0.0
+	if s.expiredTokens >= tokenExpiredThreshold {
+		refreshFailing = 1.0
+	}
+	ch <- prometheus.MustNewConstMetric(tokenRefreshFailingDesc, prometheus.GaugeValue, refreshFailing)
+
+	s.durations.Collect(ch)
+}
+
+// observe records a request to the endpoint with the status code and the rate-limit headers of its response.
+// A code of zero means that there was no response.
+func (s *APIStats) observe(endpoint string, code int, header http.Header) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.requests[endpoint]++
+	s.responses[responseKey{endpoint: endpoint, code: code}]++
+
+	if code != 0 {
+		s.observeDeprecation(endpoint, header)
+	}
+
+	for name := range header {
+		lower := strings.ToLower(name)
+		if s.seenHeaders[name] || !(strings.Contains(lower, "ratelimit") || strings.Contains(lower, "rate-limit")) {
+			continue
+		}
+
+		s.seenHeaders[name] = true
+		s.log.Debugf("APIStats: observed rate-limit header %s: %s", name, header.Get(name))
+	}
+
+	for _, name := range rateLimitRemainingHeaders {
+		value := header.Get(name)
+		if value == "" {
+			continue
+		}
+
+		remaining, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			s.log.Debugf("APIStats: can not parse %s header %q: %v", name, value, err)
+			continue
+		}
+
+		s.remaining = &remaining
+		return
+	}
+}
+
+// observeDuration records the duration of a request to the endpoint. homeID is only used for homestatus requests
+// and only if the per-home latency is enabled, because other endpoints are not specific to a home.
+func (s *APIStats) observeDuration(endpoint, homeID string, duration time.Duration) {
+	labels := prometheus.Labels{"endpoint": endpoint}
+	if s.perHomeLatency {
+		if endpoint != "homestatus" {
+			homeID = ""
+		}
+		labels["home_id"] = homeID
+	}
+
+	s.durations.With(labels).Observe(duration.Seconds())
+}
+
+// retried records that a request to the endpoint is retried.
+func (s *APIStats) retried(endpoint string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.retries[endpoint]++
+}
+
+// tokenChecked records whether the token was still valid before a request. An expired token or an error returned
+// by the token source means that the token source did not manage to refresh it, which is only logged as an error
+// once it happened tokenExpiredThreshold times in a row. The state recovers as soon as a valid token is returned again.
+func (s *APIStats) tokenChecked(valid bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if valid {
+		if s.expiredTokens >= tokenExpiredThreshold {
+			s.log.Info("APIStats: token has been refreshed, requests to the NetAtmo API are made again")
+		}
+		s.expiredTokens = 0
+		return
+	}
+
+	s.expiredTokens++
+	if s.expiredTokens == tokenExpiredThreshold {
+		s.log.Errorf("APIStats: skipped %d requests in a row because the token has expired and is not refreshed, check the connection to the NetAtmo API or authenticate the exporter again", s.expiredTokens)
+	}
+}
+
+// observeDeprecation checks the response headers for deprecation notices. Each notice is only logged once per
+// endpoint to not flood the log on every scrape.
+func (s *APIStats) observeDeprecation(endpoint string, header http.Header) {
+	notices := []string{}
+	for _, name := range deprecationHeaders {
+		if value := header.Get(name); value != "" {
+			notices = append(notices, name+": "+value)
+		}
+	}
+
+	for _, value := range header.Values("Warning") {
+		if strings.Contains(strings.ToLower(value), "deprecat") {
+			notices = append(notices, "Warning: "+value)
+		}
+	}
+
+	s.deprecated[endpoint] = len(notices) > 0
+	for _, notice := range notices {
+		key := endpoint + " " + notice
+		if s.warned[key] {
+			continue
+		}
+
+		s.warned[key] = true
+		s.log.Warnf("APIStats: endpoint %s of the NetAtmo API is deprecated: %s", endpoint, notice)
+	}
+}
+
+// statsTransport records all requests going through it in APIStats.
+type statsTransport struct {
+	base  http.RoundTripper
+	stats *APIStats
+}
+
+func (t *statsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	endpoint := path.Base(req.URL.Path)
+
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	t.stats.observeDuration(endpoint, req.URL.Query().Get("home_id"), time.Since(start))
+
+	if err != nil {
+		t.stats.observe(endpoint, 0, nil)
+		return nil, err
+	}
+
+	t.stats.observe(endpoint, resp.StatusCode, resp.Header)
+	return resp, nil
+}
@@ -0,0 +1,275 @@
+package collector
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sirupsen/logrus"
+)
+
+func TestAPIStats(t *testing.T) {
+	type response struct {
+		code   int
+		header http.Header
+	}
+
+	tt := []struct {
+		desc        string
+		responses   []response
+		wantMetrics string
+	}{
+		{
+			desc: "no rate-limit header",
+			responses: []response{
+				{code: http.StatusOK, header: http.Header{}},
+				{code: 0, header: nil},
+			},
+			wantMetrics: `# HELP netatmo_api_deprecated Contains 1 if the last response of the NetAtmo API endpoint contained a deprecation notice, 0 otherwise.
+# TYPE netatmo_api_deprecated gauge
+netatmo_api_deprecated{endpoint="homesdata"} 0
+# HELP netatmo_api_requests_total Number of requests made to the NetAtmo API by endpoint.
+# TYPE netatmo_api_requests_total counter
+netatmo_api_requests_total{endpoint="homesdata"} 2
+# HELP netatmo_api_responses_total Number of responses from the NetAtmo API by endpoint and HTTP status code. Requests failing without a response use the code 0.
+# TYPE netatmo_api_responses_total counter
+netatmo_api_responses_total{code="0",endpoint="homesdata"} 1
+netatmo_api_responses_total{code="200",endpoint="homesdata"} 1
+# HELP netatmo_token_refresh_failing Contains 1 if requests to the NetAtmo API are repeatedly skipped, because the token has expired and is not refreshed, 0 otherwise.
+# TYPE netatmo_token_refresh_failing gauge
+netatmo_token_refresh_failing 0
+`,
+		},
+		{
+			desc: "last remaining value",
+			responses: []response{
+				{code: http.StatusOK, header: http.Header{"X-Ratelimit-Remaining": {"50"}}},
+				{code: http.StatusTooManyRequests, header: http.Header{"Ratelimit-Remaining": {"49"}}},
+			},
+			wantMetrics: `# HELP netatmo_api_deprecated Contains 1 if the last response of the NetAtmo API endpoint contained a deprecation notice, 0 otherwise.
+# TYPE netatmo_api_deprecated gauge
+netatmo_api_deprecated{endpoint="homesdata"} 0
+# HELP netatmo_api_rate_limit_remaining Number of requests remaining in the current rate-limit window as reported by the last NetAtmo API response.
+# TYPE netatmo_api_rate_limit_remaining gauge
+netatmo_api_rate_limit_remaining 49
+# HELP netatmo_api_requests_total Number of requests made to the NetAtmo API by endpoint.
+# TYPE netatmo_api_requests_total counter
+netatmo_api_requests_total{endpoint="homesdata"} 2
+# HELP netatmo_api_responses_total Number of responses from the NetAtmo API by endpoint and HTTP status code. Requests failing without a response use the code 0.
+# TYPE netatmo_api_responses_total counter
+netatmo_api_responses_total{code="200",endpoint="homesdata"} 1
+netatmo_api_responses_total{code="429",endpoint="homesdata"} 1
+# HELP netatmo_token_refresh_failing Contains 1 if requests to the NetAtmo API are repeatedly skipped, because the token has expired and is not refreshed, 0 otherwise.
+# TYPE netatmo_token_refresh_failing gauge
+netatmo_token_refresh_failing 0
+`,
+		},
+		{
+			desc: "invalid value",
+			responses: []response{
+				{code: http.StatusOK, header: http.Header{"X-Ratelimit-Remaining": {"many"}}},
+			},
+			wantMetrics: `# HELP netatmo_api_deprecated Contains 1 if the last response of the NetAtmo API endpoint contained a deprecation notice, 0 otherwise.
+# TYPE netatmo_api_deprecated gauge
+netatmo_api_deprecated{endpoint="homesdata"} 0
+# HELP netatmo_api_requests_total Number of requests made to the NetAtmo API by endpoint.
+# TYPE netatmo_api_requests_total counter
+netatmo_api_requests_total{endpoint="homesdata"} 1
+# HELP netatmo_api_responses_total Number of responses from the NetAtmo API by endpoint and HTTP status code. Requests failing without a response use the code 0.
+# TYPE netatmo_api_responses_total counter
+netatmo_api_responses_total{code="200",endpoint="homesdata"} 1
+# HELP netatmo_token_refresh_failing Contains 1 if requests to the NetAtmo API are repeatedly skipped, because the token has expired and is not refreshed, 0 otherwise.
+# TYPE netatmo_token_refresh_failing gauge
+netatmo_token_refresh_failing 0
+`,
+		},
+		{
+			desc: "deprecation notice",
+			responses: []response{
+				{code: http.StatusOK, header: http.Header{}},
+				{code: http.StatusOK, header: http.Header{
+					"Deprecation": {"@1735689600"},
+					"Warning":     {`299 - "Endpoint is deprecated"`, `299 - "Unrelated"`},
+				}},
+			},
+			wantMetrics: `# HELP netatmo_api_deprecated Contains 1 if the last response of the NetAtmo API endpoint contained a deprecation notice, 0 otherwise.
+# TYPE netatmo_api_deprecated gauge
+netatmo_api_deprecated{endpoint="homesdata"} 1
+# HELP netatmo_api_requests_total Number of requests made to the NetAtmo API by endpoint.
+# TYPE netatmo_api_requests_total counter
+netatmo_api_requests_total{endpoint="homesdata"} 2
+# HELP netatmo_api_responses_total Number of responses from the NetAtmo API by endpoint and HTTP status code. Requests failing without a response use the code 0.
+# TYPE netatmo_api_responses_total counter
+netatmo_api_responses_total{code="200",endpoint="homesdata"} 2
+# HELP netatmo_token_refresh_failing Contains 1 if requests to the NetAtmo API are repeatedly skipped, because the token has expired and is not refreshed, 0 otherwise.
+# TYPE netatmo_token_refresh_failing gauge
+netatmo_token_refresh_failing 0
+`,
+		},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			stats := NewAPIStats(logrus.New(), false)
+			for _, resp := range tc.responses {
+				stats.observe("homesdata", resp.code, resp.header)
+			}
+
+			if err := testutil.CollectAndCompare(stats, strings.NewReader(tc.wantMetrics)); err != nil {
+				t.Errorf("metrics differ: %s", err)
+			}
+		})
+	}
+}
+
+func TestAPIStats_TokenRefreshFailing(t *testing.T) {
+	tt := []struct {
+		desc   string
+		checks []bool
+		want   string
+	}{
+		{
+			desc:   "valid token",
+			checks: []bool{true, true},
+			want:   "0",
+		},
+		{
+			desc:   "below threshold",
+			checks: []bool{false, false, false, false},
+			want:   "0",
+		},
+		{
+			desc:   "expired repeatedly",
+			checks: []bool{true, false, false, false, false, false},
+			want:   "1",
+		},
+		{
+			desc:   "recovered",
+			checks: []bool{false, false, false, false, false, true},
+			want:   "0",
+		},
+		{
+			desc:   "not in a row",
+			checks: []bool{false, false, false, true, false, false},
+			want:   "0",
+		},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			stats := NewAPIStats(logrus.New(), false)
+			for _, valid := range tc.checks {
+				stats.tokenChecked(valid)
+			}
+
+			want := `# HELP netatmo_token_refresh_failing Contains 1 if requests to the NetAtmo API are repeatedly skipped, because the token has expired and is not refreshed, 0 otherwise.
+# TYPE netatmo_token_refresh_failing gauge
+netatmo_token_refresh_failing ` + tc.want + "\n"
+			if err := testutil.CollectAndCompare(stats, strings.NewReader(want), "netatmo_token_refresh_failing"); err != nil {
+				t.Errorf("metrics differ: %s", err)
+			}
+		})
+	}
+}
+
+func TestAPIStats_RequestDuration(t *testing.T) {
+	tt := []struct {
+		desc           string
+		perHomeLatency bool
+		wantMetrics    string
+	}{
+		{
+			desc:           "by endpoint",
+			perHomeLatency: false,
+			wantMetrics: `# HELP netatmo_api_request_duration_seconds Duration of requests to the NetAtmo API in seconds by endpoint, including requests failing without a response.
+# TYPE netatmo_api_request_duration_seconds histogram
+netatmo_api_request_duration_seconds_bucket{endpoint="homesdata",le="0.005"} 0
+netatmo_api_request_duration_seconds_bucket{endpoint="homesdata",le="0.01"} 0
+netatmo_api_request_duration_seconds_bucket{endpoint="homesdata",le="0.025"} 0
+netatmo_api_request_duration_seconds_bucket{endpoint="homesdata",le="0.05"} 0
+netatmo_api_request_duration_seconds_bucket{endpoint="homesdata",le="0.1"} 0
+netatmo_api_request_duration_seconds_bucket{endpoint="homesdata",le="0.25"} 0
+netatmo_api_request_duration_seconds_bucket{endpoint="homesdata",le="0.5"} 0
+netatmo_api_request_duration_seconds_bucket{endpoint="homesdata",le="1"} 1
+netatmo_api_request_duration_seconds_bucket{endpoint="homesdata",le="2.5"} 1
+netatmo_api_request_duration_seconds_bucket{endpoint="homesdata",le="5"} 1
+netatmo_api_request_duration_seconds_bucket{endpoint="homesdata",le="10"} 1
+netatmo_api_request_duration_seconds_bucket{endpoint="homesdata",le="+Inf"} 1
+netatmo_api_request_duration_seconds_sum{endpoint="homesdata"} 1
+netatmo_api_request_duration_seconds_count{endpoint="homesdata"} 1
+netatmo_api_request_duration_seconds_bucket{endpoint="homestatus",le="0.005"} 0
+netatmo_api_request_duration_seconds_bucket{endpoint="homestatus",le="0.01"} 0
+netatmo_api_request_duration_seconds_bucket{endpoint="homestatus",le="0.025"} 0
+netatmo_api_request_duration_seconds_bucket{endpoint="homestatus",le="0.05"} 0
+netatmo_api_request_duration_seconds_bucket{endpoint="homestatus",le="0.1"} 0
+netatmo_api_request_duration_seconds_bucket{endpoint="homestatus",le="0.25"} 1
+netatmo_api_request_duration_seconds_bucket{endpoint="homestatus",le="0.5"} 1
+netatmo_api_request_duration_seconds_bucket{endpoint="homestatus",le="1"} 1
+netatmo_api_request_duration_seconds_bucket{endpoint="homestatus",le="2.5"} 1
+netatmo_api_request_duration_seconds_bucket{endpoint="homestatus",le="5"} 1
+netatmo_api_request_duration_seconds_bucket{endpoint="homestatus",le="10"} 1
+netatmo_api_request_duration_seconds_bucket{endpoint="homestatus",le="+Inf"} 1
+netatmo_api_request_duration_seconds_sum{endpoint="homestatus"} 0.25
+netatmo_api_request_duration_seconds_count{endpoint="homestatus"} 1
+`,
+		},
+		{
+			desc:           "per home",
+			perHomeLatency: true,
+			wantMetrics: `# HELP netatmo_api_request_duration_seconds Duration of requests to the NetAtmo API in seconds by endpoint, including requests failing without a response.
+# TYPE netatmo_api_request_duration_seconds histogram
+netatmo_api_request_duration_seconds_bucket{endpoint="homesdata",home_id="",le="0.005"} 0
+netatmo_api_request_duration_seconds_bucket{endpoint="homesdata",home_id="",le="0.01"} 0
+netatmo_api_request_duration_seconds_bucket{endpoint="homesdata",home_id="",le="0.025"} 0
+netatmo_api_request_duration_seconds_bucket{endpoint="homesdata",home_id="",le="0.05"} 0
+netatmo_api_request_duration_seconds_bucket{endpoint="homesdata",home_id="",le="0.1"} 0
+netatmo_api_request_duration_seconds_bucket{endpoint="homesdata",home_id="",le="0.25"} 0
+netatmo_api_request_duration_seconds_bucket{endpoint="homesdata",home_id="",le="0.5"} 0
+netatmo_api_request_duration_seconds_bucket{endpoint="homesdata",home_id="",le="1"} 1
+netatmo_api_request_duration_seconds_bucket{endpoint="homesdata",home_id="",le="2.5"} 1
+netatmo_api_request_duration_seconds_bucket{endpoint="homesdata",home_id="",le="5"} 1
+netatmo_api_request_duration_seconds_bucket{endpoint="homesdata",home_id="",le="10"} 1
+netatmo_api_request_duration_seconds_bucket{endpoint="homesdata",home_id="",le="+Inf"} 1
+netatmo_api_request_duration_seconds_sum{endpoint="homesdata",home_id=""} 1
+netatmo_api_request_duration_seconds_count{endpoint="homesdata",home_id=""} 1
+netatmo_api_request_duration_seconds_bucket{endpoint="homestatus",home_id="home",le="0.005"} 0
+netatmo_api_request_duration_seconds_bucket{endpoint="homestatus",home_id="home",le="0.01"} 0
+netatmo_api_request_duration_seconds_bucket{endpoint="homestatus",home_id="home",le="0.025"} 0
+netatmo_api_request_duration_seconds_bucket{endpoint="homestatus",home_id="home",le="0.05"} 0
+netatmo_api_request_duration_seconds_bucket{endpoint="homestatus",home_id="home",le="0.1"} 0
+netatmo_api_request_duration_seconds_bucket{endpoint="homestatus",home_id="home",le="0.25"} 1
+netatmo_api_request_duration_seconds_bucket{endpoint="homestatus",home_id="home",le="0.5"} 1
+netatmo_api_request_duration_seconds_bucket{endpoint="homestatus",home_id="home",le="1"} 1
+netatmo_api_request_duration_seconds_bucket{endpoint="homestatus",home_id="home",le="2.5"} 1
+netatmo_api_request_duration_seconds_bucket{endpoint="homestatus",home_id="home",le="5"} 1
+netatmo_api_request_duration_seconds_bucket{endpoint="homestatus",home_id="home",le="10"} 1
+netatmo_api_request_duration_seconds_bucket{endpoint="homestatus",home_id="home",le="+Inf"} 1
+netatmo_api_request_duration_seconds_sum{endpoint="homestatus",home_id="home"} 0.25
+netatmo_api_request_duration_seconds_count{endpoint="homestatus",home_id="home"} 1
+`,
+		},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			stats := NewAPIStats(logrus.New(), tc.perHomeLatency)
+			stats.observeDuration("homesdata", "", time.Second)
+			stats.observeDuration("homestatus", "home", 250*time.Millisecond)
+
+			if err := testutil.CollectAndCompare(stats, strings.NewReader(tc.wantMetrics), "netatmo_api_request_duration_seconds"); err != nil {
+				t.Errorf("metrics differ: %s", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package collector
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	weatherModuleNeedsAttentionDesc = newDesc(
+		prefix+"weather_module_needs_attention",
+		"Netatmo Weather set to 1 if a module has a low battery, is unreachable or has a poor signal, 0 otherwise.",
+		[]string{"home_id", "home_name", "module_id", "module_name"},
+	)
+)
+
+// AttentionThresholds contains the limits used to decide whether a module needs attention. A threshold of zero
+// disables the check. The signal strengths use the scale of the Netatmo API, where higher values mean a weaker signal.
+type AttentionThresholds struct {
+	// BatteryPercent is the battery level of weather modules at or below which the battery is considered low.
+	// Thermostat modules only report a battery state, which is considered low if it is "low" or "very_low".
+	BatteryPercent int
+	// RFStrength is the radio signal strength at or above which the signal is considered poor.
+	RFStrength int
+	// WifiStrength is the Wi-Fi signal strength at or above which the signal is considered poor.
+	WifiStrength int
+}
+
+// DefaultAttentionThresholds are the limits documented by Netatmo for a low battery and a poor signal.
+var DefaultAttentionThresholds = AttentionThresholds{
+	BatteryPercent: 10,
+	RFStrength:     90,
+	WifiStrength:   86,
+}
+
+// moduleHealth contains the values of a module which are checked for the attention roll-up. Missing values are
+// not checked.
+type moduleHealth struct {
+	BatteryPercent *int
+	BatteryState   string
+	Reachable      *bool
+	RFStrength     *int
+	WifiStrength   *int
+}
+
+// needsAttention returns true if any of the values of the module crosses its threshold.
+func (t AttentionThresholds) needsAttention(h moduleHealth) bool {
+	switch {
+	case h.Reachable != nil && !*h.Reachable:
+		return true
+	case h.BatteryState == "low" || h.BatteryState == "very_low":
+		return true
+	case t.BatteryPercent > 0 && h.BatteryPercent != nil && *h.BatteryPercent <= t.BatteryPercent:
+		return true
+	case t.RFStrength > 0 && h.RFStrength != nil && *h.RFStrength >= t.RFStrength:
+		return true
+	case t.WifiStrength > 0 && h.WifiStrength != nil && *h.WifiStrength >= t.WifiStrength:
+		return true
+	default:
+		return false
+	}
+}
+
+func sendNeedsAttention(ch chan<- prometheus.Metric, desc *prometheus.Desc, thresholds AttentionThresholds, health moduleHealth, labels ...string) {
+	value := 0.0
+	if thresholds.needsAttention(health) {
+		value = 1.0
+	}
+
+	ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, value, labels...)
+}
@@ -0,0 +1,102 @@
+package collector
+
+import (
+	"testing"
+)
+
+func TestNeedsAttention(t *testing.T) {
+	intPtr := func(i int) *int {
+		return &i
+	}
+	boolPtr := func(b bool) *bool {
+		return &b
+	}
+
+	tt := []struct {
+		desc       string
+		thresholds AttentionThresholds
+		health     moduleHealth
+		want       bool
+	}{
+		{
+			desc:       "no values",
+			thresholds: DefaultAttentionThresholds,
+			health:     moduleHealth{},
+			want:       false,
+		},
+		{
+			desc:       "healthy",
+			thresholds: DefaultAttentionThresholds,
+			health: moduleHealth{
+				BatteryPercent: intPtr(80),
+				BatteryState:   "full",
+				Reachable:      boolPtr(true),
+				RFStrength:     intPtr(70),
+				WifiStrength:   intPtr(56),
+			},
+			want: false,
+		},
+		{
+			desc:       "unreachable",
+			thresholds: DefaultAttentionThresholds,
+			health: moduleHealth{
+				Reachable: boolPtr(false),
+			},
+			want: true,
+		},
+		{
+			desc:       "low battery state",
+			thresholds: DefaultAttentionThresholds,
+			health: moduleHealth{
+				BatteryState: "very_low",
+			},
+			want: true,
+		},
+		{
+			desc:       "low battery percent",
+			thresholds: DefaultAttentionThresholds,
+			health: moduleHealth{
+				BatteryPercent: intPtr(10),
+			},
+			want: true,
+		},
+		{
+			desc:       "poor radio signal",
+			thresholds: DefaultAttentionThresholds,
+			health: moduleHealth{
+				RFStrength: intPtr(92),
+			},
+			want: true,
+		},
+		{
+			desc:       "poor wifi signal",
+			thresholds: DefaultAttentionThresholds,
+			health: moduleHealth{
+				WifiStrength: intPtr(86),
+			},
+			want: true,
+		},
+		{
+			desc:       "disabled thresholds",
+			thresholds: AttentionThresholds{},
+			health: moduleHealth{
+				BatteryPercent: intPtr(5),
+				RFStrength:     intPtr(95),
+				WifiStrength:   intPtr(90),
+			},
+			want: false,
+		},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			got := tc.thresholds.needsAttention(tc.health)
+			if got != tc.want {
+				t.Errorf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package collector
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	weatherModuleBatteryVoltageDesc = newMeasurementDesc(
+		prefix+"weather_module_battery_voltage",
+		"Netatmo Weather battery voltage of a battery-powered module in volts.",
+		[]string{"home_id", "home_name", "module_id", "module_name"},
+	)
+
+	weatherModuleBatteryLowDesc = newDesc(
+		prefix+"weather_module_battery_low",
+		"Netatmo Weather set to 1 if the battery voltage of a linked module is below the low level documented by Netatmo for its type, 0 otherwise.",
+		weatherLabels,
+	)
+)
+
+// batteryStatePercent maps the battery states of Netatmo Energy modules to an approximate battery level in percent.
+// The API only reports a battery state and a voltage for these modules, whose range depends on the module type, so
+// the percentage is derived from the state. The values are listed in the README.
+var batteryStatePercent = map[string]float64{
+	"max":      100,
+	"full":     90,
+	"high":     75,
+	"medium":   50,
+	"low":      25,
+	"very_low": 10,
+}
+
+// weatherBatteryLowMillivolts maps the types of battery-powered weather modules to the battery_vp in millivolts
+// below which Netatmo documents the battery level as low. The modules use different voltage ranges, so a single
+// threshold does not fit all of them. The values are listed in the README.
+var weatherBatteryLowMillivolts = map[string]int{
+	"NAModule1": 4500, // Outdoor module
+	"NAModule2": 4770, // Wind gauge
+	"NAModule3": 4500, // Rain gauge
+	"NAModule4": 4920, // Additional indoor module
+}
+
+// weatherBatteryLow returns 1 if the battery voltage in millivolts is below the low level of the module type, 0
+// otherwise. It returns false for module types without a documented level.
+func weatherBatteryLow(moduleType string, millivolts int) (float64, bool) {
+	threshold, ok := weatherBatteryLowMillivolts[moduleType]
+	if !ok {
+		return 0, false
+	}
+
+	if millivolts < threshold {
+		return 1, true
+	}
+
+	return 0, true
+}
+
+// sendBatteryVoltage reports the battery voltage of a module, which the Netatmo API reports in millivolts. Nothing
+// is sent for modules without a battery.
+func sendBatteryVoltage(ch chan<- prometheus.Metric, desc *prometheus.Desc, millivolts *int, labels ...string) {
+	if millivolts == nil {
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, float64(*millivolts)/1000, labels...)
+}
@@ -0,0 +1,34 @@
+package collector
+
+import "sync"
+
+// boilerCycleState contains the last boiler status and the number of times the boiler switched on for each home.
+type boilerCycleState struct {
+	sync.Mutex
+	homes map[string]*boilerCycles
+}
+
+type boilerCycles struct {
+	on     bool
+	cycles float64
+}
+
+// observe records the boiler status of a home and returns the number of times the boiler switched on. The first
+// status of a home does not count as switching on, because the previous status is not known.
+func (s *boilerCycleState) observe(homeID string, on bool) float64 {
+	s.Lock()
+	defer s.Unlock()
+
+	home, ok := s.homes[homeID]
+	if !ok {
+		home = &boilerCycles{on: on}
+		s.homes[homeID] = home
+	}
+
+	if on && !home.on {
+		home.cycles++
+	}
+	home.on = on
+
+	return home.cycles
+}
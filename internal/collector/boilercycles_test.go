@@ -0,0 +1,49 @@
+package collector
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sirupsen/logrus"
+)
+
+func TestThermostatCollector_BoilerCycles(t *testing.T) {
+	boilerOn := `{"body":{"home":{"id":"home","modules":[{"id":"relay","type":"NAPlug","boiler_status":true}]}}}`
+	boilerOff := `{"body":{"home":{"id":"home","modules":[{"id":"relay","type":"NAPlug","boiler_status":false}]}}}`
+
+	client := &fakeClient{
+		homesData: mustDecode[HomesDataResponse](t, `{"body":{"homes":[{"id":"home","name":"Home"}]}}`),
+		homeStatus: map[string]*HomeStatusResponse{
+			"home": mustDecode[HomeStatusResponse](t, boilerOn),
+		},
+	}
+	c := NewThermostatCollector(logrus.New(), client, WithBoilerSampleInterval(time.Minute))
+
+	want := func(cycles string) string {
+		return `# HELP netatmo_boiler_cycles_total Netatmo Energy number of times the boiler of a home was observed switching on.
+# TYPE netatmo_boiler_cycles_total counter
+netatmo_boiler_cycles_total{home_id="home",home_name="Home"} ` + cycles + "\n"
+	}
+
+	if err := testutil.CollectAndCompare(c, strings.NewReader(want("0")), "netatmo_boiler_cycles_total"); err != nil {
+		t.Errorf("metrics of first scrape differ: %s", err)
+	}
+
+	for _, status := range []string{boilerOff, boilerOn, boilerOff, boilerOn, boilerOn} {
+		client.homeStatus["home"] = mustDecode[HomeStatusResponse](t, status)
+		c.sampleBoilers(context.Background())
+	}
+	client.homeStatus["home"] = mustDecode[HomeStatusResponse](t, boilerOff)
+
+	if err := testutil.CollectAndCompare(c, strings.NewReader(want("2")), "netatmo_boiler_cycles_total"); err != nil {
+		t.Errorf("metrics after sampling differ: %s", err)
+	}
+
+	client.homeStatus["home"] = mustDecode[HomeStatusResponse](t, boilerOn)
+	if err := testutil.CollectAndCompare(c, strings.NewReader(want("3")), "netatmo_boiler_cycles_total"); err != nil {
+		t.Errorf("metrics of scrape with boiler switched on differ: %s", err)
+	}
+}
@@ -0,0 +1,109 @@
+package collector
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	boilerOnLabels = []string{"home_id", "home_name", "module_id"}
+)
+
+// boilerOnTypes contains the module types which report sum_boiler_on in getmeasure.
+var boilerOnTypes = map[string]bool{
+	"NATherm1": true,
+	"OTM":      true,
+}
+
+// boilerOnState keeps the boiler on-time of all thermostats retrieved from getmeasure.
+type boilerOnState struct {
+	sync.Mutex
+	updated time.Time
+	since   map[string]time.Time
+	seconds map[string]float64
+}
+
+// boilerOnDue returns true, if the boiler on-time should be refreshed during this collection.
+func (c *ThermostatCollector) boilerOnDue(now time.Time) bool {
+	if c.boilerOnInterval <= 0 {
+		return false
+	}
+
+	c.boilerOn.Lock()
+	defer c.boilerOn.Unlock()
+
+	if now.Sub(c.boilerOn.updated) < c.boilerOnInterval {
+		return false
+	}
+
+	c.boilerOn.updated = now
+	return true
+}
+
+// collectBoilerOn reports the boiler on-time of the modules in the home. Only modules which are still part of the
+// current homestatus are reported, because the list of modules in home can be outdated if it was cached.
+func (c *ThermostatCollector) collectBoilerOn(ctx context.Context, ch chan<- prometheus.Metric, home homeData, current []moduleStatus, refresh bool, now time.Time) {
+	c.boilerOn.Lock()
+	defer c.boilerOn.Unlock()
+
+	if refresh {
+		c.refreshBoilerOn(ctx, home, now)
+	}
+
+	for _, module := range current {
+		seconds, ok := c.boilerOn.seconds[module.ID]
+		if !ok {
+			continue
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			c.descs.boilerOnSeconds,
+			prometheus.CounterValue,
+			seconds,
+			home.ID, home.Name, module.ID,
+		)
+	}
+}
+
+// refreshBoilerOn retrieves the daily boiler on-time of all thermostats in the home, starting at the day the
+// thermostat was first seen. The total is never decreased, so that it can be used as a counter.
+func (c *ThermostatCollector) refreshBoilerOn(ctx context.Context, home homeData, now time.Time) {
+	for _, module := range home.Modules {
+		if !boilerOnTypes[module.Type] || module.Bridge == "" {
+			continue
+		}
+
+		since, ok := c.boilerOn.since[module.ID]
+		if !ok {
+			year, month, day := now.In(homeLocation(home.Timezone)).Date()
+			since = time.Date(year, month, day, 0, 0, 0, 0, homeLocation(home.Timezone))
+			c.boilerOn.since[module.ID] = since
+		}
+
+		samples, err := c.client.Measure(ctx, MeasureRequest{
+			DeviceID:  module.Bridge,
+			ModuleID:  module.ID,
+			Scale:     "1day",
+			Types:     []string{"sum_boiler_on"},
+			DateBegin: since,
+		})
+		if err != nil {
+			logAPIError(c.log, err, "ThermostatCollector: error fetching boiler on-time for %s", module.ID)
+			continue
+		}
+
+		total := 0.0
+		for _, sample := range samples {
+			if len(sample.Values) > 0 && sample.Values[0] != nil {
+				total += *sample.Values[0]
+			}
+		}
+
+		if total > c.boilerOn.seconds[module.ID] {
+			c.boilerOn.seconds[module.ID] = total
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package collector
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sirupsen/logrus"
+)
+
+func TestThermostatCollector_BoilerOnHomeScrape(t *testing.T) {
+	home := func(homeID string) string {
+		return `{"id":"` + homeID + `","name":"` + homeID + `","modules":[
+			{"id":"relay-` + homeID + `","type":"NAPlug"},
+			{"id":"thermostat-` + homeID + `","type":"NATherm1","bridge":"relay-` + homeID + `"}
+		]}`
+	}
+	status := func(homeID string) *HomeStatusResponse {
+		return mustDecode[HomeStatusResponse](t, `{"body":{"home":{"id":"`+homeID+`","modules":[
+			{"id":"relay-`+homeID+`","type":"NAPlug"},
+			{"id":"thermostat-`+homeID+`","type":"NATherm1","bridge":"relay-`+homeID+`"}
+		]}}}`)
+	}
+	seconds := func(v float64) []MeasureSample {
+		return []MeasureSample{{Values: []*float64{&v}}}
+	}
+
+	client := &fakeClient{
+		homesData: mustDecode[HomesDataResponse](t, `{"body":{"homes":[`+home("first")+`,`+home("second")+`]}}`),
+		homeStatus: map[string]*HomeStatusResponse{
+			"first":  status("first"),
+			"second": status("second"),
+		},
+		measure: map[string][]MeasureSample{
+			"thermostat-first":  seconds(3600),
+			"thermostat-second": seconds(1800),
+		},
+	}
+	c := NewThermostatCollector(logrus.New(), client, WithBoilerOnInterval(time.Hour))
+	now := time.Unix(1704110400, 0)
+	c.clock = func() time.Time {
+		return now
+	}
+
+	// The scrape of a single home, when a refresh is due, must not delay the refresh of the other homes.
+	if got := testutil.CollectAndCount(c.Home("first"), "netatmo_boiler_on_seconds_total"); got != 0 {
+		t.Errorf("got %d boiler on-time series in home scrape, want 0", got)
+	}
+	now = now.Add(time.Minute)
+
+	want := `# HELP netatmo_boiler_on_seconds_total Netatmo Energy total time the boiler was switched on by a thermostat in seconds, starting at the day the exporter was started.
+# TYPE netatmo_boiler_on_seconds_total counter
+netatmo_boiler_on_seconds_total{home_id="first",home_name="first",module_id="thermostat-first"} 3600
+netatmo_boiler_on_seconds_total{home_id="second",home_name="second",module_id="thermostat-second"} 1800
+`
+	if err := testutil.CollectAndCompare(c, strings.NewReader(want), "netatmo_boiler_on_seconds_total"); err != nil {
+		t.Errorf("metrics differ: %s", err)
+	}
+}
@@ -0,0 +1,93 @@
+package collector
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var collectorDataAgeDesc = newDesc(
+	prefix+"collector_data_age_seconds",
+	"Seconds since the metrics of a collector with its own refresh interval were collected.",
+	[]string{"collector"},
+)
+
+// Cached wraps a collector, so that its metrics are only collected once per interval instead of on every scrape.
+// Like the cached data of NetatmoCollector, the metrics are collected again in the background once they are older
+// than the interval, and the previous metrics are reported until the refresh is done. Only the first collection
+// waits for the metrics. The age of the metrics is reported as netatmo_collector_data_age_seconds with the name of
+// the collector. The collector is returned unchanged if the interval is not positive.
+func Cached(c prometheus.Collector, name string, interval time.Duration) prometheus.Collector {
+	if interval <= 0 {
+		return c
+	}
+
+	return &cachedCollector{
+		Collector: c,
+		name:      name,
+		interval:  interval,
+		clock:     time.Now,
+	}
+}
+
+type cachedCollector struct {
+	prometheus.Collector
+	name     string
+	interval time.Duration
+	clock    func() time.Time
+
+	lock       sync.Mutex
+	metrics    []prometheus.Metric
+	updated    time.Time
+	refreshing bool
+	refreshes  sync.WaitGroup
+}
+
+func (c *cachedCollector) Describe(ch chan<- *prometheus.Desc) {
+	c.Collector.Describe(ch)
+	ch <- collectorDataAgeDesc
+}
+
+func (c *cachedCollector) Collect(ch chan<- prometheus.Metric) {
+	c.lock.Lock()
+	if c.updated.IsZero() {
+		c.lock.Unlock()
+		c.refresh()
+		c.lock.Lock()
+	} else if !c.refreshing && c.clock().Sub(c.updated) >= c.interval {
+		c.refreshing = true
+		c.refreshes.Add(1)
+		go func() {
+			defer c.refreshes.Done()
+			c.refresh()
+		}()
+	}
+	metrics, updated := c.metrics, c.updated
+	c.lock.Unlock()
+
+	for _, m := range metrics {
+		ch <- m
+	}
+	ch <- prometheus.MustNewConstMetric(collectorDataAgeDesc, prometheus.GaugeValue, c.clock().Sub(updated).Seconds(), c.name)
+}
+
+func (c *cachedCollector) refresh() {
+	metrics := make(chan prometheus.Metric)
+	go func() {
+		c.Collector.Collect(metrics)
+		close(metrics)
+	}()
+
+	var collected []prometheus.Metric
+	for m := range metrics {
+		collected = append(collected, m)
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.metrics = collected
+	c.updated = c.clock()
+	c.refreshing = false
+}
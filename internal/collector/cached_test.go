@@ -0,0 +1,68 @@
+package collector
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// countingCollector reports the number of times it has been collected.
+type countingCollector struct {
+	collections float64
+}
+
+func (c *countingCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- netatmoUpDesc
+}
+
+func (c *countingCollector) Collect(ch chan<- prometheus.Metric) {
+	c.collections++
+	ch <- prometheus.MustNewConstMetric(netatmoUpDesc, prometheus.GaugeValue, c.collections)
+}
+
+func TestCached(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	wrapped := &countingCollector{}
+	c := Cached(wrapped, "weather", 5*time.Minute).(*cachedCollector)
+	c.clock = func() time.Time {
+		return now
+	}
+
+	want := func(collections, age string) string {
+		return `# HELP netatmo_collector_data_age_seconds Seconds since the metrics of a collector with its own refresh interval were collected.
+# TYPE netatmo_collector_data_age_seconds gauge
+netatmo_collector_data_age_seconds{collector="weather"} ` + age + `
+# HELP netatmo_up Zero if there was an error during the last refresh try.
+# TYPE netatmo_up gauge
+netatmo_up ` + collections + "\n"
+	}
+
+	if err := testutil.CollectAndCompare(c, strings.NewReader(want("1", "0"))); err != nil {
+		t.Errorf("metrics of first scrape differ: %s", err)
+	}
+
+	now = now.Add(4 * time.Minute)
+	if err := testutil.CollectAndCompare(c, strings.NewReader(want("1", "240"))); err != nil {
+		t.Errorf("metrics within interval differ: %s", err)
+	}
+
+	now = now.Add(time.Minute)
+	if err := testutil.CollectAndCompare(c, strings.NewReader(want("1", "300"))); err != nil {
+		t.Errorf("metrics of scrape starting refresh differ: %s", err)
+	}
+	c.refreshes.Wait()
+
+	if err := testutil.CollectAndCompare(c, strings.NewReader(want("2", "0"))); err != nil {
+		t.Errorf("metrics after refresh differ: %s", err)
+	}
+}
+
+func TestCached_NoInterval(t *testing.T) {
+	c := &countingCollector{}
+	if got := Cached(c, "weather", 0); got != prometheus.Collector(c) {
+		t.Errorf("got wrapped collector %#v, want unchanged collector", got)
+	}
+}
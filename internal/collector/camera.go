@@ -0,0 +1,142 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	cameraLabels = []string{"home_id", "home_name", "camera_id", "camera_name"}
+
+	cameraKnownPersonsDesc = newDesc(
+		prefix+"camera_known_persons_total",
+		"Netatmo Security number of known persons in a home.",
+		[]string{"home_id", "home_name"},
+	)
+
+	cameraLastEventDesc = newDesc(
+		prefix+"camera_last_event_seconds",
+		"Netatmo Security unix timestamp of the most recent event of a camera.",
+		cameraLabels,
+	)
+)
+
+// CameraCollector is a Prometheus collector for the persons and events of Netatmo Security cameras.
+type CameraCollector struct {
+	log    logrus.FieldLogger
+	client NetatmoClient
+}
+
+// NewCameraCollector creates a new CameraCollector.
+func NewCameraCollector(log logrus.FieldLogger, client NetatmoClient) *CameraCollector {
+	return &CameraCollector{
+		log:    log,
+		client: client,
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *CameraCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- cameraKnownPersonsDesc
+	ch <- cameraLastEventDesc
+}
+
+// Collect implements prometheus.Collector.
+func (c *CameraCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx := context.Background()
+
+	data, err := c.client.SecurityHomeData(ctx)
+	if err != nil {
+		logAPIError(c.log, err, "CameraCollector: error fetching gethomedata")
+		return
+	}
+
+	for _, home := range data.Body.Homes {
+		knownPersons := 0
+		for _, person := range home.Persons {
+			if person.Pseudo != "" {
+				knownPersons++
+			}
+		}
+		ch <- prometheus.MustNewConstMetric(cameraKnownPersonsDesc, prometheus.GaugeValue, float64(knownPersons), home.ID, home.Name)
+
+		lastEvents := c.lastEvents(home)
+		for _, camera := range home.Cameras {
+			lastEvent, ok := lastEvents[camera.ID]
+			if !ok {
+				continue
+			}
+
+			ch <- prometheus.MustNewConstMetric(
+				cameraLastEventDesc,
+				prometheus.GaugeValue,
+				float64(lastEvent),
+				home.ID, home.Name, camera.ID, camera.Name,
+			)
+		}
+	}
+}
+
+// lastEvents returns the time of the most recent event per camera. The events are decoded one by one, so that
+// events with an unexpected format do not prevent reporting the others.
+func (c *CameraCollector) lastEvents(home securityHome) map[string]int64 {
+	result := map[string]int64{}
+	for _, raw := range home.Events {
+		var event cameraEvent
+		if err := json.Unmarshal(raw, &event); err != nil {
+			c.log.Debugf("CameraCollector: can not decode event in home %s: %v", home.ID, err)
+			continue
+		}
+
+		if event.CameraID == "" || event.Time <= result[event.CameraID] {
+			continue
+		}
+
+		result[event.CameraID] = event.Time
+	}
+
+	return result
+}
+
+// SecurityHomeDataResponse contains the response of the gethomedata endpoint.
+type SecurityHomeDataResponse struct {
+	Body struct {
+		Homes []securityHome `json:"homes"`
+	} `json:"body"`
+}
+
+type securityHome struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Persons []struct {
+		ID     string `json:"id"`
+		Pseudo string `json:"pseudo"`
+	} `json:"persons"`
+	Cameras []struct {
+		ID   string `json:"id"`
+		Type string `json:"type"`
+		Name string `json:"name"`
+	} `json:"cameras"`
+	// Events are decoded separately, because their format depends on the type of the event.
+	Events []json.RawMessage `json:"events"`
+}
+
+type cameraEvent struct {
+	Type     string `json:"type"`
+	Time     int64  `json:"time"`
+	CameraID string `json:"camera_id"`
+}
+
+// SecurityHomeData implements NetatmoClient.
+func (c *httpNetatmoClient) SecurityHomeData(ctx context.Context) (*SecurityHomeDataResponse, error) {
+	var result SecurityHomeDataResponse
+	if err := c.get(ctx, "gethomedata", url.Values{}, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
@@ -0,0 +1,59 @@
+package collector
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sirupsen/logrus"
+)
+
+func TestCameraLastEvents(t *testing.T) {
+	tt := []struct {
+		desc       string
+		home       string
+		wantEvents map[string]int64
+	}{
+		{
+			desc:       "no events",
+			home:       `{"id":"home"}`,
+			wantEvents: map[string]int64{},
+		},
+		{
+			desc: "latest event per camera",
+			home: `{"id":"home","events":[
+				{"type":"person","time":100,"camera_id":"camera1"},
+				{"type":"movement","time":300,"camera_id":"camera1"},
+				{"type":"outdoor","time":200,"camera_id":"camera2"},
+				{"type":"person","time":150,"camera_id":"camera1"}
+			]}`,
+			wantEvents: map[string]int64{
+				"camera1": 300,
+				"camera2": 200,
+			},
+		},
+		{
+			desc: "skip unexpected format",
+			home: `{"id":"home","events":[
+				{"type":"person","time":"yesterday","camera_id":"camera1"},
+				{"type":"person","time":100,"camera_id":"camera2"},
+				{"type":"new_event_type","time":200}
+			]}`,
+			wantEvents: map[string]int64{
+				"camera2": 100,
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			c := NewCameraCollector(logrus.New(), nil)
+			got := c.lastEvents(*mustDecode[securityHome](t, tc.home))
+			if diff := cmp.Diff(got, tc.wantEvents); diff != "" {
+				t.Errorf("events differ: -got+want\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,208 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"time"
+
+	netatmo "github.com/exzz/netatmo-api-go"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/oauth2"
+)
+
+// ErrNoToken is returned by the NetatmoClient when there is no valid token yet, for example before the exporter
+// has been authenticated.
+var ErrNoToken = errors.New("token not available or invalid")
+
+// NetatmoClient provides the endpoints of the Netatmo API used by the collectors in this package.
+// Implementations can wrap the default client returned by NewNetatmoClient, for example to add caching or rate-limiting.
+type NetatmoClient interface {
+	HomesData(ctx context.Context) (*HomesDataResponse, error)
+	// HomeStatus requests the status of a home. If deviceTypes is not empty, only the modules of these types are
+	// returned.
+	HomeStatus(ctx context.Context, homeID string, deviceTypes []string) (*HomeStatusResponse, error)
+	StationsData(ctx context.Context) (*StationsDataResponse, error)
+	Measure(ctx context.Context, params MeasureRequest) ([]MeasureSample, error)
+	PublicData(ctx context.Context, area BoundingBox) (*PublicDataResponse, error)
+	SecurityHomeData(ctx context.Context) (*SecurityHomeDataResponse, error)
+	ThermostatsData(ctx context.Context) (*ThermostatsDataResponse, error)
+}
+
+// RetryConfig configures how often requests failing with a transient error are retried.
+type RetryConfig struct {
+	// MaxRetries is the number of retries after the first attempt. Zero disables retries.
+	MaxRetries int
+	// BaseDelay is the delay before the first retry. It is doubled for every further retry. If the response contains
+	// a Retry-After header, its delay is used instead.
+	BaseDelay time.Duration
+	// MaxDelay limits the delay before a retry. A request is not retried if the API asks to wait longer using the
+	// Retry-After header, because an earlier retry would most likely fail again. Zero means no limit.
+	MaxDelay time.Duration
+}
+
+// DefaultRetryConfig retries a request three times, waiting 500ms, 1s and 2s before the retries, or up to 10s if the
+// API asks for it.
+var DefaultRetryConfig = RetryConfig{
+	MaxRetries: 3,
+	BaseDelay:  500 * time.Millisecond,
+	MaxDelay:   10 * time.Second,
+}
+
+// TransportConfig configures how connections to the Netatmo API are kept open and reused between requests.
+type TransportConfig struct {
+	// MaxIdleConns is the maximum number of idle connections. Zero means no limit.
+	MaxIdleConns int
+	// MaxIdleConnsPerHost is the maximum number of idle connections to the Netatmo API.
+	MaxIdleConnsPerHost int
+	// IdleConnTimeout is the time after which an idle connection is closed. Zero means no limit.
+	IdleConnTimeout time.Duration
+}
+
+// DefaultTransportConfig keeps up to ten connections to the Netatmo API open for five minutes, so that the
+// connections are reused by the following scrapes instead of establishing a new TLS connection for every request.
+var DefaultTransportConfig = TransportConfig{
+	MaxIdleConns:        10,
+	MaxIdleConnsPerHost: 10,
+	IdleConnTimeout:     5 * time.Minute,
+}
+
+func newTransport(cfg TransportConfig) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConns = cfg.MaxIdleConns
+	transport.MaxIdleConnsPerHost = cfg.MaxIdleConnsPerHost
+	transport.IdleConnTimeout = cfg.IdleConnTimeout
+
+	return transport
+}
+
+// httpNetatmoClient implements NetatmoClient using HTTP requests authenticated with the current token.
+type httpNetatmoClient struct {
+	log            logrus.FieldLogger
+	baseURL        string
+	tokenFunc      func() (*oauth2.Token, error)
+	stats          *APIStats
+	requestTimeout time.Duration
+	retry          RetryConfig
+	// transport is used for all requests if it is set, the default transport otherwise.
+	transport http.RoundTripper
+}
+
+// NewNetatmoClient creates a NetatmoClient, which uses the token returned by tokenFunc for all requests.
+// The requests are sent to the API at baseURL, for example a proxy, or to the Netatmo API if baseURL is empty.
+// They are recorded in stats, if it is not nil. Each request is cancelled after requestTimeout, a timeout of zero
+// only uses the deadline of the context passed to the client. Requests failing with a transient error are retried
+// according to retry, the timeout applies to each attempt. The retries are logged to log. The connections of all
+// requests are shared according to transport.
+func NewNetatmoClient(log logrus.FieldLogger, baseURL string, tokenFunc func() (*oauth2.Token, error), stats *APIStats, requestTimeout time.Duration, retry RetryConfig, transport TransportConfig) NetatmoClient {
+	if baseURL == "" {
+		baseURL = apiBaseURL
+	}
+
+	return &httpNetatmoClient{
+		log:            log,
+		baseURL:        baseURL,
+		tokenFunc:      tokenFunc,
+		stats:          stats,
+		requestTimeout: requestTimeout,
+		retry:          retry,
+		transport:      newTransport(transport),
+	}
+}
+
+// get executes a request against the endpoint using the current token. Transient errors are retried with an
+// exponential backoff, or after the delay requested by the API, until the retries are used up or the context is done.
+func (c *httpNetatmoClient) get(ctx context.Context, endpoint string, query url.Values, result any) error {
+	delay := c.retry.BaseDelay
+	for attempt := 0; ; attempt++ {
+		err := c.getOnce(ctx, endpoint, query, result)
+		if err == nil || !IsTransient(err) {
+			return err
+		}
+
+		if attempt >= c.retry.MaxRetries {
+			if attempt > 0 {
+				c.log.Warnf("NetatmoClient: giving up %s request after %d retries: %v", endpoint, attempt, err)
+			}
+			return err
+		}
+
+		wait := delay
+		if c.retry.MaxDelay > 0 && wait > c.retry.MaxDelay {
+			wait = c.retry.MaxDelay
+		}
+		if requested, ok := requestedDelay(err); ok {
+			if c.retry.MaxDelay > 0 && requested > c.retry.MaxDelay {
+				c.log.Warnf("NetatmoClient: giving up %s request, because the API asked to wait %s, which is longer than the maximum delay of %s: %v", endpoint, requested, c.retry.MaxDelay, err)
+				return err
+			}
+			wait = requested
+		}
+		c.log.Debugf("NetatmoClient: retrying %s request in %s (retry %d of %d): %v", endpoint, wait, attempt+1, c.retry.MaxRetries, err)
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(wait):
+		}
+		delay *= 2
+
+		if c.stats != nil {
+			c.stats.retried(endpoint)
+		}
+	}
+}
+
+func (c *httpNetatmoClient) getOnce(ctx context.Context, endpoint string, query url.Values, result any) error {
+	if c.requestTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.requestTimeout)
+		defer cancel()
+	}
+
+	client, err := c.httpClient(endpoint)
+	if err != nil {
+		return err
+	}
+
+	return getJSON(ctx, client, c.baseURL, endpoint, query, result)
+}
+
+func (c *httpNetatmoClient) httpClient(endpoint string) (*http.Client, error) {
+	token, err := c.tokenFunc()
+	if err != nil {
+		// The refreshing token source returns the error of the failed refresh instead of the expired token.
+		// ErrNotAuthenticated only means that the exporter has not been authenticated yet.
+		if c.stats != nil && !errors.Is(err, netatmo.ErrNotAuthenticated) {
+			c.stats.tokenChecked(false)
+		}
+		return nil, newTokenError(endpoint, err)
+	}
+	if token == nil || !token.Valid() {
+		// A missing token only means that the exporter has not been authenticated yet.
+		if token != nil && c.stats != nil {
+			c.stats.tokenChecked(false)
+		}
+		return nil, ErrNoToken
+	}
+	if c.stats != nil {
+		c.stats.tokenChecked(true)
+	}
+
+	// Only the token is added for each request, the connections are kept by the shared transport.
+	client := &http.Client{
+		Transport: &oauth2.Transport{
+			Source: oauth2.StaticTokenSource(token),
+			Base:   c.transport,
+		},
+	}
+	if c.stats != nil {
+		client.Transport = &statsTransport{
+			base:  client.Transport,
+			stats: c.stats,
+		}
+	}
+
+	return client, nil
+}
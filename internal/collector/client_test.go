@@ -0,0 +1,697 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	netatmo "github.com/exzz/netatmo-api-go"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/oauth2"
+)
+
+func TestNetatmoClient_Retry(t *testing.T) {
+	tt := []struct {
+		desc         string
+		failures     int
+		code         int
+		header       http.Header
+		retry        RetryConfig
+		wantRequests int32
+		wantErr      bool
+		wantRetries  string
+	}{
+		{
+			desc:         "success after retries",
+			failures:     2,
+			code:         http.StatusInternalServerError,
+			retry:        RetryConfig{MaxRetries: 3, BaseDelay: time.Millisecond},
+			wantRequests: 3,
+			wantRetries: `# HELP netatmo_api_retries_total Number of requests to the NetAtmo API retried after a transient error by endpoint.
+# TYPE netatmo_api_retries_total counter
+netatmo_api_retries_total{endpoint="homesdata"} 2
+`,
+		},
+		{
+			desc:         "retries used up",
+			failures:     5,
+			code:         http.StatusTooManyRequests,
+			retry:        RetryConfig{MaxRetries: 1, BaseDelay: time.Millisecond},
+			wantRequests: 2,
+			wantErr:      true,
+			wantRetries: `# HELP netatmo_api_retries_total Number of requests to the NetAtmo API retried after a transient error by endpoint.
+# TYPE netatmo_api_retries_total counter
+netatmo_api_retries_total{endpoint="homesdata"} 1
+`,
+		},
+		{
+			desc:         "retry after requested delay",
+			failures:     1,
+			code:         http.StatusTooManyRequests,
+			header:       http.Header{"Retry-After": {"1"}},
+			retry:        RetryConfig{MaxRetries: 3, BaseDelay: time.Hour, MaxDelay: 2 * time.Second},
+			wantRequests: 2,
+			wantRetries: `# HELP netatmo_api_retries_total Number of requests to the NetAtmo API retried after a transient error by endpoint.
+# TYPE netatmo_api_retries_total counter
+netatmo_api_retries_total{endpoint="homesdata"} 1
+`,
+		},
+		{
+			desc:         "requested delay too long",
+			failures:     1,
+			code:         http.StatusTooManyRequests,
+			header:       http.Header{"Retry-After": {"3600"}},
+			retry:        RetryConfig{MaxRetries: 3, BaseDelay: time.Millisecond, MaxDelay: time.Second},
+			wantRequests: 1,
+			wantErr:      true,
+		},
+		{
+			desc:         "permanent error",
+			failures:     1,
+			code:         http.StatusForbidden,
+			retry:        RetryConfig{MaxRetries: 3, BaseDelay: time.Millisecond},
+			wantRequests: 1,
+			wantErr:      true,
+		},
+		{
+			desc:         "retries disabled",
+			failures:     1,
+			code:         http.StatusInternalServerError,
+			retry:        RetryConfig{},
+			wantRequests: 1,
+			wantErr:      true,
+		},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var requests atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				if int(requests.Add(1)) <= tc.failures {
+					for name, values := range tc.header {
+						w.Header()[name] = values
+					}
+					w.WriteHeader(tc.code)
+					return
+				}
+
+				_, _ = w.Write([]byte(`{"body":{"homes":[]}}`))
+			}))
+			defer server.Close()
+
+			stats := NewAPIStats(logrus.New(), false)
+			client := &httpNetatmoClient{
+				log:     logrus.New(),
+				baseURL: server.URL + "/api/",
+				tokenFunc: func() (*oauth2.Token, error) {
+					return &oauth2.Token{
+						AccessToken: "test-token",
+						Expiry:      time.Now().Add(time.Hour),
+					}, nil
+				},
+				stats: stats,
+				retry: tc.retry,
+			}
+
+			_, err := client.HomesData(context.Background())
+			if (err != nil) != tc.wantErr {
+				t.Errorf("got error %v, want error %v", err, tc.wantErr)
+			}
+
+			if got := requests.Load(); got != tc.wantRequests {
+				t.Errorf("got %d requests, want %d", got, tc.wantRequests)
+			}
+
+			if err := testutil.CollectAndCompare(stats, strings.NewReader(tc.wantRetries), "netatmo_api_retries_total"); err != nil {
+				t.Errorf("metrics differ: %s", err)
+			}
+		})
+	}
+}
+
+func TestNetatmoClient_HomeStatusDeviceTypes(t *testing.T) {
+	tt := []struct {
+		desc        string
+		deviceTypes []string
+		wantQuery   string
+	}{
+		{
+			desc:      "all devices",
+			wantQuery: "home_id=home",
+		},
+		{
+			desc:        "energy devices",
+			deviceTypes: []string{"NAPlug", "NATherm1", "NRV"},
+			wantQuery:   "device_types=NAPlug%2CNATherm1%2CNRV&home_id=home",
+		},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var gotQuery string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotQuery = r.URL.RawQuery
+				_, _ = w.Write([]byte(`{"body":{"home":{"id":"home"}}}`))
+			}))
+			defer server.Close()
+
+			client := &httpNetatmoClient{
+				baseURL: server.URL + "/api/",
+				tokenFunc: func() (*oauth2.Token, error) {
+					return &oauth2.Token{
+						AccessToken: "test-token",
+						Expiry:      time.Now().Add(time.Hour),
+					}, nil
+				},
+			}
+
+			if _, err := client.HomeStatus(context.Background(), "home", tc.deviceTypes); err != nil {
+				t.Fatalf("got error: %s", err)
+			}
+
+			if gotQuery != tc.wantQuery {
+				t.Errorf("got query %q, want %q", gotQuery, tc.wantQuery)
+			}
+		})
+	}
+}
+
+func TestGetJSON_NonJSONResponse(t *testing.T) {
+	tt := []struct {
+		desc        string
+		code        int
+		contentType string
+		body        string
+		wantNonJSON bool
+		wantDetail  string
+	}{
+		{
+			desc:        "json",
+			code:        http.StatusOK,
+			contentType: "application/json; charset=utf-8",
+			body:        `{"body":{}}`,
+		},
+		{
+			desc:        "plain text",
+			code:        http.StatusOK,
+			contentType: "text/plain",
+			body:        `{"body":{}}`,
+		},
+		{
+			desc:        "error page",
+			code:        http.StatusOK,
+			contentType: "text/html",
+			body:        "<html><body>Service unavailable</body></html>",
+			wantNonJSON: true,
+			wantDetail:  `content type "text/html", body "<html><body>Service unavailable</body></html>"`,
+		},
+		{
+			desc:        "bad gateway",
+			code:        http.StatusBadGateway,
+			contentType: "text/html; charset=UTF-8",
+			body:        "\n<html>" + strings.Repeat("x", 300),
+			wantNonJSON: true,
+			wantDetail:  `content type "text/html; charset=UTF-8", body "<html>` + strings.Repeat("x", 193) + `"`,
+		},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", tc.contentType)
+				w.WriteHeader(tc.code)
+				_, _ = w.Write([]byte(tc.body))
+			}))
+			defer server.Close()
+
+			var result struct{}
+			err := getJSON(context.Background(), server.Client(), server.URL+"/", "homesdata", nil, &result)
+
+			if got := errors.Is(err, ErrNonJSONResponse); got != tc.wantNonJSON {
+				t.Fatalf("got error %v, want non-JSON error %v", err, tc.wantNonJSON)
+			}
+
+			var apiErr *APIError
+			if tc.wantNonJSON && (!errors.As(err, &apiErr) || apiErr.detail != tc.wantDetail) {
+				t.Errorf("got detail %q, want %q", apiErr.detail, tc.wantDetail)
+			}
+		})
+	}
+}
+
+func TestGetJSON_ErrorBody(t *testing.T) {
+	tt := []struct {
+		desc          string
+		body          string
+		wantErr       string
+		wantTransient bool
+		wantHint      bool
+	}{
+		{
+			desc: "data",
+			body: `{"body":{"homes":[{"id":"home"}]}}`,
+		},
+		{
+			desc:     "missing scope",
+			body:     `{"error":{"code":13,"message":"Application does not have the good scope rights"}}`,
+			wantErr:  "homesdata request failed: API error 13: Application does not have the good scope rights",
+			wantHint: true,
+		},
+		{
+			desc:          "usage reached",
+			body:          `{"error":{"code":26,"message":"User usage reached"}}`,
+			wantErr:       "homesdata request failed: API error 26: User usage reached",
+			wantTransient: true,
+		},
+		{
+			desc:     "malformed",
+			body:     `{"body":}`,
+			wantErr:  "homesdata request failed: decoding response: invalid character '}' looking for beginning of value",
+			wantHint: true,
+		},
+		{
+			desc:          "truncated",
+			body:          `{"body":{"homes":[{"id":"ho`,
+			wantErr:       "homesdata request failed: decoding response: unexpected EOF",
+			wantTransient: true,
+		},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(tc.body))
+			}))
+			defer server.Close()
+
+			var result HomesDataResponse
+			err := getJSON(context.Background(), server.Client(), server.URL+"/", "homesdata", nil, &result)
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Fatalf("got error %v, want none", err)
+				}
+				return
+			}
+
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) || err.Error() != tc.wantErr {
+				t.Fatalf("got error %v, want %q", err, tc.wantErr)
+			}
+
+			if apiErr.Transient() != tc.wantTransient {
+				t.Errorf("got transient %v, want %v", apiErr.Transient(), tc.wantTransient)
+			}
+
+			if got := apiErr.Hint() != ""; got != tc.wantHint {
+				t.Errorf("got hint %q, want hint %v", apiErr.Hint(), tc.wantHint)
+			}
+		})
+	}
+}
+
+func TestGetJSON_StatusErrorBody(t *testing.T) {
+	tt := []struct {
+		desc          string
+		code          int
+		body          string
+		wantErr       string
+		wantTransient bool
+		wantHint      bool
+	}{
+		{
+			desc:    "invalid argument",
+			code:    http.StatusBadRequest,
+			body:    `{"error":{"code":21,"message":"Invalid argument"}}`,
+			wantErr: "homestatus request failed: status 400 Bad Request: API error 21: Invalid argument",
+		},
+		{
+			desc:     "expired token",
+			code:     http.StatusForbidden,
+			body:     `{"error":{"code":3,"message":"Access token expired"}}`,
+			wantErr:  "homestatus request failed: status 403 Forbidden: API error 3: Access token expired",
+			wantHint: true,
+		},
+		{
+			desc:          "usage reached",
+			code:          http.StatusForbidden,
+			body:          `{"error":{"code":26,"message":"User usage reached"}}`,
+			wantErr:       "homestatus request failed: status 403 Forbidden: API error 26: User usage reached",
+			wantTransient: true,
+		},
+		{
+			desc:     "other body",
+			code:     http.StatusBadRequest,
+			body:     `{"status":"error","reason":"` + strings.Repeat("x", 300) + `"}`,
+			wantErr:  `homestatus request failed: status 400 Bad Request: body "{\"status\":\"error\",\"reason\":\"` + strings.Repeat("x", 172) + `"`,
+			wantHint: true,
+		},
+		{
+			desc:     "empty body",
+			code:     http.StatusBadRequest,
+			wantErr:  "homestatus request failed: status 400 Bad Request",
+			wantHint: true,
+		},
+		{
+			desc:          "request timeout",
+			code:          http.StatusRequestTimeout,
+			body:          `{}`,
+			wantErr:       `homestatus request failed: status 408 Request Timeout: body "{}"`,
+			wantTransient: true,
+		},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tc.code)
+				_, _ = w.Write([]byte(tc.body))
+			}))
+			defer server.Close()
+
+			var result HomeStatusResponse
+			err := getJSON(context.Background(), server.Client(), server.URL+"/", "homestatus", nil, &result)
+
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) || err.Error() != tc.wantErr {
+				t.Fatalf("got error %v, want %q", err, tc.wantErr)
+			}
+
+			if apiErr.StatusCode != tc.code {
+				t.Errorf("got status code %d, want %d", apiErr.StatusCode, tc.code)
+			}
+
+			if apiErr.Transient() != tc.wantTransient {
+				t.Errorf("got transient %v, want %v", apiErr.Transient(), tc.wantTransient)
+			}
+
+			if got := apiErr.Hint() != ""; got != tc.wantHint {
+				t.Errorf("got hint %q, want hint %v", apiErr.Hint(), tc.wantHint)
+			}
+		})
+	}
+}
+
+func TestNetatmoClient_ReusesConnections(t *testing.T) {
+	var connections atomic.Int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"body":{"homes":[]}}` + "\n"))
+	}))
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			connections.Add(1)
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	client := &httpNetatmoClient{
+		baseURL: server.URL + "/api/",
+		tokenFunc: func() (*oauth2.Token, error) {
+			return &oauth2.Token{
+				AccessToken: "test-token",
+				Expiry:      time.Now().Add(time.Hour),
+			}, nil
+		},
+		transport: newTransport(DefaultTransportConfig),
+	}
+
+	for range 3 {
+		if _, err := client.HomesData(context.Background()); err != nil {
+			t.Fatalf("got error: %s", err)
+		}
+	}
+
+	if got := connections.Load(); got != 1 {
+		t.Errorf("got %d connections, want 1", got)
+	}
+}
+
+func TestThermostatCollector_ReusesConnections(t *testing.T) {
+	var connections atomic.Int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/homesdata"):
+			_, _ = w.Write([]byte(`{"body":{"homes":[{"id":"home","name":"Home","rooms":[{"id":"living","name":"Living Room"}]}]}}`))
+		default:
+			_, _ = w.Write([]byte(`{"body":{"home":{"id":"home","rooms":[{"id":"living","therm_measured_temperature":20.5}]}}}`))
+		}
+	}))
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			connections.Add(1)
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	tokenFunc := func() (*oauth2.Token, error) {
+		return &oauth2.Token{
+			AccessToken: "test-token",
+			Expiry:      time.Now().Add(time.Hour),
+		}, nil
+	}
+	client := NewNetatmoClient(logrus.New(), server.URL+"/api/", tokenFunc, NewAPIStats(logrus.New(), false), 0, RetryConfig{}, DefaultTransportConfig)
+	c := NewThermostatCollector(logrus.New(), client)
+
+	for range 3 {
+		if got := testutil.CollectAndCount(c, "netatmo_thermostat_temperature"); got != 1 {
+			t.Fatalf("got %d temperatures, want 1", got)
+		}
+	}
+
+	if got := connections.Load(); got != 1 {
+		t.Errorf("got %d connections for three scrapes, want 1", got)
+	}
+}
+
+func TestNetatmoClient_RequestTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/homesdata"):
+			_, _ = w.Write([]byte(`{"body":{"homes":[{"id":"hung","name":"Hung"},{"id":"home","name":"Home"}]}}`))
+		case r.URL.Query().Get("home_id") == "hung":
+			// Simulates a connection which never responds.
+			<-r.Context().Done()
+		default:
+			_, _ = w.Write([]byte(`{"body":{"home":{"id":"home","rooms":[{"id":"living","name":"Living Room","therm_measured_temperature":20.5}]}}}`))
+		}
+	}))
+	defer server.Close()
+
+	client := &httpNetatmoClient{
+		baseURL: server.URL + "/api/",
+		tokenFunc: func() (*oauth2.Token, error) {
+			return &oauth2.Token{
+				AccessToken: "test-token",
+				Expiry:      time.Now().Add(time.Hour),
+			}, nil
+		},
+		requestTimeout: 20 * time.Millisecond,
+		transport:      newTransport(DefaultTransportConfig),
+	}
+	c := NewThermostatCollector(logrus.New(), client)
+
+	want := `# HELP netatmo_thermostat_temperature Netatmo Energy measured room temperature in degrees Celsius.
+# TYPE netatmo_thermostat_temperature gauge
+netatmo_thermostat_temperature{home_id="home",home_name="Home",room_id="living",room_name="Living Room"} 20.5
+`
+	if err := testutil.CollectAndCompare(c, strings.NewReader(want), "netatmo_thermostat_temperature"); err != nil {
+		t.Errorf("metrics differ: %s", err)
+	}
+}
+
+func TestNewNetatmoClient_BaseURL(t *testing.T) {
+	var path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"body":{"homes":[]}}`))
+	}))
+	defer server.Close()
+
+	tokenFunc := func() (*oauth2.Token, error) {
+		return &oauth2.Token{
+			AccessToken: "test-token",
+			Expiry:      time.Now().Add(time.Hour),
+		}, nil
+	}
+
+	client := NewNetatmoClient(logrus.New(), server.URL+"/proxy/api/", tokenFunc, nil, 0, RetryConfig{}, DefaultTransportConfig)
+	if _, err := client.HomesData(context.Background()); err != nil {
+		t.Fatalf("got error: %s", err)
+	}
+
+	if want := "/proxy/api/homesdata"; path != want {
+		t.Errorf("got path %q, want %q", path, want)
+	}
+
+	if got := NewNetatmoClient(logrus.New(), "", tokenFunc, nil, 0, RetryConfig{}, DefaultTransportConfig).(*httpNetatmoClient).baseURL; got != apiBaseURL {
+		t.Errorf("got default base URL %q, want %q", got, apiBaseURL)
+	}
+}
+
+func TestNetatmoClient_RefreshedToken(t *testing.T) {
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"body":{"homes":[]}}`))
+	}))
+	defer server.Close()
+
+	// The token function of the NetAtmo client returns the token of its token source, which refreshes an expired
+	// token, so the client needs to ask for the token again before every request.
+	current := &oauth2.Token{
+		AccessToken: "expired-token",
+		Expiry:      time.Now().Add(-time.Minute),
+	}
+	client := &httpNetatmoClient{
+		baseURL: server.URL + "/api/",
+		tokenFunc: func() (*oauth2.Token, error) {
+			return current, nil
+		},
+	}
+
+	if _, err := client.HomesData(context.Background()); !errors.Is(err, ErrNoToken) {
+		t.Fatalf("got error %v with expired token, want %v", err, ErrNoToken)
+	}
+
+	current = &oauth2.Token{
+		AccessToken: "refreshed-token",
+		Expiry:      time.Now().Add(time.Hour),
+	}
+	if _, err := client.HomesData(context.Background()); err != nil {
+		t.Fatalf("got error %v with refreshed token", err)
+	}
+
+	if want := "Bearer refreshed-token"; authorization != want {
+		t.Errorf("got authorization %q, want %q", authorization, want)
+	}
+}
+
+func TestNetatmoClient_TokenRefreshFailing(t *testing.T) {
+	tt := []struct {
+		desc     string
+		tokenErr error
+		want     string
+	}{
+		{
+			desc:     "refresh failing",
+			tokenErr: errors.New("oauth2: cannot fetch token: connection refused"),
+			want:     "1",
+		},
+		{
+			desc:     "not authenticated",
+			tokenErr: netatmo.ErrNotAuthenticated,
+			want:     "0",
+		},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			stats := NewAPIStats(logrus.New(), false)
+			client := &httpNetatmoClient{
+				tokenFunc: func() (*oauth2.Token, error) {
+					return nil, tc.tokenErr
+				},
+				stats: stats,
+			}
+
+			for range tokenExpiredThreshold {
+				if _, err := client.HomesData(context.Background()); err == nil {
+					t.Fatal("got no error")
+				}
+			}
+
+			want := `# HELP netatmo_token_refresh_failing Contains 1 if requests to the NetAtmo API are repeatedly skipped, because the token has expired and is not refreshed, 0 otherwise.
+# TYPE netatmo_token_refresh_failing gauge
+netatmo_token_refresh_failing ` + tc.want + "\n"
+			if err := testutil.CollectAndCompare(stats, strings.NewReader(want), "netatmo_token_refresh_failing"); err != nil {
+				t.Errorf("metrics differ: %s", err)
+			}
+		})
+	}
+}
+
+func TestNetatmoClient_TokenError(t *testing.T) {
+	tt := []struct {
+		desc          string
+		tokenErr      error
+		wantTransient bool
+		wantHint      bool
+	}{
+		{
+			desc:     "refresh rejected",
+			tokenErr: &oauth2.RetrieveError{ErrorCode: "invalid_grant"},
+			wantHint: true,
+		},
+		{
+			desc:          "network error",
+			tokenErr:      &url.Error{Op: "Post", URL: "https://api.netatmo.com/oauth2/token", Err: errors.New("connection refused")},
+			wantTransient: true,
+		},
+		{
+			desc:     "not authenticated",
+			tokenErr: netatmo.ErrNotAuthenticated,
+		},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			client := &httpNetatmoClient{
+				tokenFunc: func() (*oauth2.Token, error) {
+					return nil, tc.tokenErr
+				},
+			}
+
+			_, err := client.HomesData(context.Background())
+
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) || !errors.Is(err, tc.tokenErr) {
+				t.Fatalf("got error %v, want APIError containing %v", err, tc.tokenErr)
+			}
+
+			if apiErr.Transient() != tc.wantTransient {
+				t.Errorf("got transient %v, want %v", apiErr.Transient(), tc.wantTransient)
+			}
+
+			if got := apiErr.Hint() != ""; got != tc.wantHint {
+				t.Errorf("got hint %q, want hint %v", apiErr.Hint(), tc.wantHint)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package collector
+
+import (
+	"errors"
+	"net/http"
+
+	netatmo "github.com/exzz/netatmo-api-go"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/oauth2"
+)
+
+const (
+	collectionStateOK           = "ok"
+	collectionStatePartial      = "partial"
+	collectionStateAuthError    = "auth_error"
+	collectionStateRateLimited  = "rate_limited"
+	collectionStateNetworkError = "network_error"
+)
+
+var (
+	// collectionStates contains all states of desc.
+	collectionStates = []string{
+		collectionStateOK,
+		collectionStatePartial,
+		collectionStateAuthError,
+		collectionStateRateLimited,
+		collectionStateNetworkError,
+	}
+)
+
+// collectionState classifies an error which failed the whole collection. Errors which are neither caused by the
+// authentication nor the rate limit, including server errors and timeouts, are reported as network errors.
+func collectionState(err error) string {
+	// The token function of the client returns ErrNotAuthenticated until the exporter has been authenticated.
+	if errors.Is(err, ErrNoToken) || errors.Is(err, netatmo.ErrNotAuthenticated) {
+		return collectionStateAuthError
+	}
+
+	var retrieveErr *oauth2.RetrieveError
+	if errors.As(err, &retrieveErr) {
+		return collectionStateAuthError
+	}
+
+	var respErr *ResponseError
+	if errors.As(err, &respErr) {
+		switch respErr.Code {
+		case responseErrorInvalidToken, responseErrorTokenExpired, responseErrorScope:
+			return collectionStateAuthError
+		case responseErrorUsageReached:
+			return collectionStateRateLimited
+		}
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.StatusCode {
+		case http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden:
+			return collectionStateAuthError
+		case http.StatusTooManyRequests:
+			return collectionStateRateLimited
+		}
+	}
+
+	return collectionStateNetworkError
+}
+
+// sendCollectionState sends one series per collection state, of which only the series of current is set to 1.
+func sendCollectionState(ch chan<- prometheus.Metric, desc *prometheus.Desc, current string) {
+	for _, state := range collectionStates {
+		value := 0.0
+		if state == current {
+			value = 1.0
+		}
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, value, state)
+	}
+}
@@ -0,0 +1,82 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	netatmo "github.com/exzz/netatmo-api-go"
+	"golang.org/x/oauth2"
+)
+
+func TestCollectionState(t *testing.T) {
+	tt := []struct {
+		desc string
+		err  error
+		want string
+	}{
+		{
+			desc: "no token",
+			err:  fmt.Errorf("homesdata: %w", ErrNoToken),
+			want: collectionStateAuthError,
+		},
+		{
+			desc: "not authenticated",
+			err:  fmt.Errorf("error getting token: %w", netatmo.ErrNotAuthenticated),
+			want: collectionStateAuthError,
+		},
+		{
+			desc: "refresh rejected",
+			err:  newTokenError("homesdata", &oauth2.RetrieveError{}),
+			want: collectionStateAuthError,
+		},
+		{
+			desc: "forbidden",
+			err:  newStatusError("homesdata", &http.Response{StatusCode: http.StatusForbidden, Status: "403 Forbidden"}),
+			want: collectionStateAuthError,
+		},
+		{
+			desc: "rate limited",
+			err:  newStatusError("homestatus", &http.Response{StatusCode: http.StatusTooManyRequests, Status: "429 Too Many Requests"}),
+			want: collectionStateRateLimited,
+		},
+		{
+			desc: "missing scope",
+			err:  newResponseError("homesdata", &ResponseError{Code: 13, Message: "Application does not have the good scope rights"}),
+			want: collectionStateAuthError,
+		},
+		{
+			desc: "usage reached",
+			err:  newResponseError("homestatus", &ResponseError{Code: 26, Message: "User usage reached"}),
+			want: collectionStateRateLimited,
+		},
+		{
+			desc: "server error",
+			err:  newStatusError("homestatus", &http.Response{StatusCode: http.StatusBadGateway, Status: "502 Bad Gateway"}),
+			want: collectionStateNetworkError,
+		},
+		{
+			desc: "transport error",
+			err:  newTransportError("homestatus", errors.New("connection refused")),
+			want: collectionStateNetworkError,
+		},
+		{
+			desc: "timeout",
+			err:  context.DeadlineExceeded,
+			want: collectionStateNetworkError,
+		},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			if got := collectionState(tc.err); got != tc.want {
+				t.Errorf("got state %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
@@ -7,32 +7,38 @@ import (
 	netatmo "github.com/exzz/netatmo-api-go"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/sirupsen/logrus"
+	"golang.org/x/sync/singleflight"
 )
 
 var (
 	prefix        = "netatmo_"
-	netatmoUpDesc = prometheus.NewDesc(prefix+"up",
+	netatmoUpDesc = newDesc(prefix+"up",
 		"Zero if there was an error during the last refresh try.",
-		nil, nil)
+		nil)
 
-	refreshIntervalDesc = prometheus.NewDesc(
+	refreshIntervalDesc = newDesc(
 		prefix+"refresh_interval_seconds",
 		"Contains the configured refresh interval in seconds. This is provided as a convenience for calculations with the cache update time.",
-		nil, nil)
+		nil)
 	refreshPrefix        = prefix + "last_refresh_"
-	refreshTimestampDesc = prometheus.NewDesc(
+	refreshTimestampDesc = newDesc(
 		refreshPrefix+"time",
 		"Contains the time of the last refresh try, successful or not.",
-		nil, nil)
-	refreshDurationDesc = prometheus.NewDesc(
+		nil)
+	refreshDurationDesc = newDesc(
 		refreshPrefix+"duration_seconds",
 		"Contains the time it took for the last refresh to complete, even if it was unsuccessful.",
-		nil, nil)
+		nil)
 
-	cacheTimestampDesc = prometheus.NewDesc(
+	cacheTimestampDesc = newDesc(
 		prefix+"cache_updated_time",
 		"Contains the time of the cached data.",
-		nil, nil)
+		nil)
+
+	sinceLastCollectionDesc = newDesc(
+		prefix+"seconds_since_last_collection",
+		"Contains the seconds since the last successful refresh of the cached data. Grows if the background refresh stalls.",
+		nil)
 
 	varLabels = []string{
 		"module",
@@ -42,75 +48,78 @@ var (
 
 	sensorPrefix = prefix + "sensor_"
 
-	updatedDesc = prometheus.NewDesc(
+	updatedDesc = newDesc(
 		sensorPrefix+"updated",
-		"Timestamp of last update",
-		varLabels,
-		nil)
+		"Netatmo Weather unix timestamp of the last update of a module.",
+		varLabels)
 
-	tempDesc = prometheus.NewDesc(
+	tempDesc = newMeasurementDesc(
 		sensorPrefix+"temperature_celsius",
-		"Temperature measurement in celsius",
-		varLabels,
-		nil)
+		"Netatmo Weather temperature measurement in degrees Celsius.",
+		varLabels)
 
-	humidityDesc = prometheus.NewDesc(
+	humidityDesc = newMeasurementDesc(
 		sensorPrefix+"humidity_percent",
-		"Relative humidity measurement in percent",
-		varLabels,
-		nil)
+		"Netatmo Weather relative humidity measurement in percent.",
+		varLabels)
 
-	cotwoDesc = prometheus.NewDesc(
+	cotwoDesc = newMeasurementDesc(
 		sensorPrefix+"co2_ppm",
-		"Carbondioxide measurement in parts per million",
-		varLabels,
-		nil)
+		"Netatmo Weather carbon dioxide measurement in parts per million.",
+		varLabels)
 
-	noiseDesc = prometheus.NewDesc(
+	noiseDesc = newMeasurementDesc(
 		sensorPrefix+"noise_db",
-		"Noise measurement in decibels",
-		varLabels,
-		nil)
+		"Netatmo Weather noise measurement in decibels.",
+		varLabels)
 
-	pressureDesc = prometheus.NewDesc(
+	pressureDesc = newMeasurementDesc(
 		sensorPrefix+"pressure_mb",
-		"Atmospheric pressure measurement in millibar",
-		varLabels,
-		nil)
+		"Netatmo Weather atmospheric pressure measurement in millibar.",
+		varLabels)
+
+	tempFahrenheitDesc = newMeasurementDesc(
+		sensorPrefix+"temperature_fahrenheit",
+		"Netatmo Weather temperature measurement in degrees Fahrenheit.",
+		varLabels)
 
-	windStrengthDesc = prometheus.NewDesc(
+	windStrengthDesc = newMeasurementDesc(
 		sensorPrefix+"wind_strength_kph",
-		"Wind strength in kilometers per hour",
-		varLabels,
-		nil)
+		"Netatmo Weather wind strength in kilometers per hour.",
+		varLabels)
+
+	windStrengthMphDesc = newMeasurementDesc(
+		sensorPrefix+"wind_strength_mph",
+		"Netatmo Weather wind strength in miles per hour.",
+		varLabels)
 
-	windDirectionDesc = prometheus.NewDesc(
+	windDirectionDesc = newDesc(
 		sensorPrefix+"wind_direction_degrees",
-		"Wind direction in degrees",
-		varLabels,
-		nil)
+		"Netatmo Weather wind direction in degrees.",
+		varLabels)
 
-	rainDesc = prometheus.NewDesc(
+	rainDesc = newMeasurementDesc(
 		sensorPrefix+"rain_amount_mm",
-		"Rain amount in millimeters",
-		varLabels,
-		nil)
+		"Netatmo Weather rain amount in millimeters.",
+		varLabels)
 
-	batteryDesc = prometheus.NewDesc(
+	rainInchesDesc = newMeasurementDesc(
+		sensorPrefix+"rain_amount_inches",
+		"Netatmo Weather rain amount in inches.",
+		varLabels)
+
+	batteryDesc = newDesc(
 		sensorPrefix+"battery_percent",
-		"Battery remaining life (10: low)",
-		varLabels,
-		nil)
-	wifiDesc = prometheus.NewDesc(
+		"Netatmo Weather remaining battery life in percent (10: low).",
+		varLabels)
+	wifiDesc = newDesc(
 		sensorPrefix+"wifi_signal_strength",
-		"Wifi signal strength (86: bad, 71: avg, 56: good)",
-		varLabels,
-		nil)
-	rfDesc = prometheus.NewDesc(
+		"Netatmo Weather Wi-Fi signal strength (86: bad, 71: avg, 56: good).",
+		varLabels)
+	rfDesc = newDesc(
 		sensorPrefix+"rf_signal_strength",
-		"RF signal strength (90: lowest, 60: highest)",
-		varLabels,
-		nil)
+		"Netatmo Weather radio signal strength (90: lowest, 60: highest).",
+		varLabels)
 )
 
 // ReadFunction defines the interface for reading from the Netatmo API.
@@ -122,7 +131,11 @@ type NetatmoCollector struct {
 	RefreshInterval time.Duration
 	StaleThreshold  time.Duration
 	ReadFunction    ReadFunction
-	clock           func() time.Time
+	// DualUnits additionally reports temperature, wind strength and rain in imperial units.
+	DualUnits bool
+	// Readiness is marked as ready after the first successful refresh, if it is set.
+	Readiness *Readiness
+	clock     func() time.Time
 
 	lastRefresh         time.Time
 	lastRefreshError    error
@@ -130,6 +143,7 @@ type NetatmoCollector struct {
 	cacheLock           sync.RWMutex
 	cacheTimestamp      time.Time
 	cachedData          *netatmo.DeviceCollection
+	refreshes           singleflight.Group
 }
 
 func New(log *logrus.Logger, readFunction ReadFunction, refreshInterval, staleDuration time.Duration) *NetatmoCollector {
@@ -149,6 +163,7 @@ func (c *NetatmoCollector) Describe(dChan chan<- *prometheus.Desc) {
 	dChan <- refreshTimestampDesc
 	dChan <- refreshDurationDesc
 	dChan <- cacheTimestampDesc
+	dChan <- sinceLastCollectionDesc
 	dChan <- updatedDesc
 	dChan <- tempDesc
 	dChan <- humidityDesc
@@ -161,6 +176,12 @@ func (c *NetatmoCollector) Describe(dChan chan<- *prometheus.Desc) {
 	dChan <- batteryDesc
 	dChan <- wifiDesc
 	dChan <- rfDesc
+
+	if c.DualUnits {
+		dChan <- tempFahrenheitDesc
+		dChan <- windStrengthMphDesc
+		dChan <- rainInchesDesc
+	}
 }
 
 // Collect implements prometheus.Collector
@@ -183,6 +204,9 @@ func (c *NetatmoCollector) Collect(mChan chan<- prometheus.Metric) {
 	defer c.cacheLock.RUnlock()
 
 	c.sendMetric(mChan, cacheTimestampDesc, prometheus.GaugeValue, convertTime(c.cacheTimestamp))
+	if !c.cacheTimestamp.IsZero() {
+		c.sendMetric(mChan, sinceLastCollectionDesc, prometheus.GaugeValue, now.Sub(c.cacheTimestamp).Seconds())
+	}
 	if c.cachedData != nil {
 		for _, dev := range c.cachedData.Devices() {
 			homeName := dev.HomeName
@@ -196,8 +220,16 @@ func (c *NetatmoCollector) Collect(mChan chan<- prometheus.Metric) {
 	}
 }
 
-// RefreshData causes the collector to try to refresh the cached data.
+// RefreshData causes the collector to try to refresh the cached data. If a refresh is already running, for example
+// because two collections started at the same time, it waits for that refresh instead of reading the data again.
 func (c *NetatmoCollector) RefreshData(now time.Time) {
+	c.refreshes.Do("refresh", func() (any, error) {
+		c.refreshData(now)
+		return nil, nil
+	})
+}
+
+func (c *NetatmoCollector) refreshData(now time.Time) {
 	c.Log.Debugf("Refreshing data. Time since last refresh: %s", now.Sub(c.lastRefresh))
 	c.lastRefresh = now
 
@@ -212,6 +244,8 @@ func (c *NetatmoCollector) RefreshData(now time.Time) {
 		return
 	}
 
+	c.Readiness.markReady()
+
 	c.cacheLock.Lock()
 	defer c.cacheLock.Unlock()
 	c.cacheTimestamp = now
@@ -242,6 +276,9 @@ func (c *NetatmoCollector) collectData(ch chan<- prometheus.Metric, device *neta
 
 	if data.Temperature != nil {
 		c.sendMetric(ch, tempDesc, prometheus.GaugeValue, float64(*data.Temperature), moduleName, stationName, homeName)
+		if c.DualUnits {
+			c.sendMetric(ch, tempFahrenheitDesc, prometheus.GaugeValue, celsiusToFahrenheit(float64(*data.Temperature)), moduleName, stationName, homeName)
+		}
 	}
 
 	if data.Humidity != nil {
@@ -262,6 +299,9 @@ func (c *NetatmoCollector) collectData(ch chan<- prometheus.Metric, device *neta
 
 	if data.WindStrength != nil {
 		c.sendMetric(ch, windStrengthDesc, prometheus.GaugeValue, float64(*data.WindStrength), moduleName, stationName, homeName)
+		if c.DualUnits {
+			c.sendMetric(ch, windStrengthMphDesc, prometheus.GaugeValue, kphToMph(float64(*data.WindStrength)), moduleName, stationName, homeName)
+		}
 	}
 
 	if data.WindAngle != nil {
@@ -270,6 +310,9 @@ func (c *NetatmoCollector) collectData(ch chan<- prometheus.Metric, device *neta
 
 	if data.Rain != nil {
 		c.sendMetric(ch, rainDesc, prometheus.GaugeValue, float64(*data.Rain), moduleName, stationName, homeName)
+		if c.DualUnits {
+			c.sendMetric(ch, rainInchesDesc, prometheus.GaugeValue, mmToInches(float64(*data.Rain)), moduleName, stationName, homeName)
+		}
 	}
 
 	if device.BatteryPercent != nil {
@@ -182,6 +182,9 @@ func TestNetatmoCollector_Collect(t *testing.T) {
 		# HELP netatmo_refresh_interval_seconds Contains the configured refresh interval in seconds. This is provided as a convenience for calculations with the cache update time.
 		# TYPE netatmo_refresh_interval_seconds gauge
 		netatmo_refresh_interval_seconds 3600
+		# HELP netatmo_seconds_since_last_collection Contains the seconds since the last successful refresh of the cached data. Grows if the background refresh stalls.
+		# TYPE netatmo_seconds_since_last_collection gauge
+		netatmo_seconds_since_last_collection 0
 		# HELP netatmo_up Zero if there was an error during the last refresh try.
 		# TYPE netatmo_up gauge
 		netatmo_up 1
@@ -202,46 +205,49 @@ netatmo_last_refresh_time 3600
 # HELP netatmo_refresh_interval_seconds Contains the configured refresh interval in seconds. This is provided as a convenience for calculations with the cache update time.
 # TYPE netatmo_refresh_interval_seconds gauge
 netatmo_refresh_interval_seconds 3600
-# HELP netatmo_sensor_battery_percent Battery remaining life (10: low)
+# HELP netatmo_seconds_since_last_collection Contains the seconds since the last successful refresh of the cached data. Grows if the background refresh stalls.
+# TYPE netatmo_seconds_since_last_collection gauge
+netatmo_seconds_since_last_collection 0
+# HELP netatmo_sensor_battery_percent Netatmo Weather remaining battery life in percent (10: low).
 # TYPE netatmo_sensor_battery_percent gauge
 netatmo_sensor_battery_percent{home="Home",module="Bedroom",station="Home (Living Room)"} 55
 netatmo_sensor_battery_percent{home="Home",module="Outside",station="Home (Living Room)"} 70
 netatmo_sensor_battery_percent{home="Home",module="id-aa:bb:cc:dd:ee:f3",station="Home (Living Room)"} 60
-# HELP netatmo_sensor_co2_ppm Carbondioxide measurement in parts per million
+# HELP netatmo_sensor_co2_ppm Netatmo Weather carbon dioxide measurement in parts per million.
 # TYPE netatmo_sensor_co2_ppm gauge
 netatmo_sensor_co2_ppm{home="Home",module="Bedroom",station="Home (Living Room)"} 510
 netatmo_sensor_co2_ppm{home="Home",module="Living Room",station="Home (Living Room)"} 650
 netatmo_sensor_co2_ppm{home="Home",module="id-aa:bb:cc:dd:ee:f3",station="Home (Living Room)"} 750
-# HELP netatmo_sensor_humidity_percent Relative humidity measurement in percent
+# HELP netatmo_sensor_humidity_percent Netatmo Weather relative humidity measurement in percent.
 # TYPE netatmo_sensor_humidity_percent gauge
 netatmo_sensor_humidity_percent{home="Home",module="Bedroom",station="Home (Living Room)"} 52
 netatmo_sensor_humidity_percent{home="Home",module="Living Room",station="Home (Living Room)"} 45
 netatmo_sensor_humidity_percent{home="Home",module="Outside",station="Home (Living Room)"} 83
 netatmo_sensor_humidity_percent{home="Home",module="id-aa:bb:cc:dd:ee:f3",station="Home (Living Room)"} 75
-# HELP netatmo_sensor_noise_db Noise measurement in decibels
+# HELP netatmo_sensor_noise_db Netatmo Weather noise measurement in decibels.
 # TYPE netatmo_sensor_noise_db gauge
 netatmo_sensor_noise_db{home="Home",module="Living Room",station="Home (Living Room)"} 40
-# HELP netatmo_sensor_pressure_mb Atmospheric pressure measurement in millibar
+# HELP netatmo_sensor_pressure_mb Netatmo Weather atmospheric pressure measurement in millibar.
 # TYPE netatmo_sensor_pressure_mb gauge
 netatmo_sensor_pressure_mb{home="Home",module="Living Room",station="Home (Living Room)"} 1234
-# HELP netatmo_sensor_rf_signal_strength RF signal strength (90: lowest, 60: highest)
+# HELP netatmo_sensor_rf_signal_strength Netatmo Weather radio signal strength (90: lowest, 60: highest).
 # TYPE netatmo_sensor_rf_signal_strength gauge
 netatmo_sensor_rf_signal_strength{home="Home",module="Bedroom",station="Home (Living Room)"} 80
 netatmo_sensor_rf_signal_strength{home="Home",module="Outside",station="Home (Living Room)"} 57
 netatmo_sensor_rf_signal_strength{home="Home",module="id-aa:bb:cc:dd:ee:f3",station="Home (Living Room)"} 70
-# HELP netatmo_sensor_temperature_celsius Temperature measurement in celsius
+# HELP netatmo_sensor_temperature_celsius Netatmo Weather temperature measurement in degrees Celsius.
 # TYPE netatmo_sensor_temperature_celsius gauge
 netatmo_sensor_temperature_celsius{home="Home",module="Bedroom",station="Home (Living Room)"} 17
 netatmo_sensor_temperature_celsius{home="Home",module="Living Room",station="Home (Living Room)"} 23
 netatmo_sensor_temperature_celsius{home="Home",module="Outside",station="Home (Living Room)"} 5
 netatmo_sensor_temperature_celsius{home="Home",module="id-aa:bb:cc:dd:ee:f3",station="Home (Living Room)"} 23
-# HELP netatmo_sensor_updated Timestamp of last update
+# HELP netatmo_sensor_updated Netatmo Weather unix timestamp of the last update of a module.
 # TYPE netatmo_sensor_updated gauge
 netatmo_sensor_updated{home="Home",module="Bedroom",station="Home (Living Room)"} 3502
 netatmo_sensor_updated{home="Home",module="Living Room",station="Home (Living Room)"} 3500
 netatmo_sensor_updated{home="Home",module="Outside",station="Home (Living Room)"} 3501
 netatmo_sensor_updated{home="Home",module="id-aa:bb:cc:dd:ee:f3",station="Home (Living Room)"} 3503
-# HELP netatmo_sensor_wifi_signal_strength Wifi signal strength (86: bad, 71: avg, 56: good)
+# HELP netatmo_sensor_wifi_signal_strength Netatmo Weather Wi-Fi signal strength (86: bad, 71: avg, 56: good).
 # TYPE netatmo_sensor_wifi_signal_strength gauge
 netatmo_sensor_wifi_signal_strength{home="Home",module="Living Room",station="Home (Living Room)"} 45
 # HELP netatmo_up Zero if there was an error during the last refresh try.
@@ -0,0 +1,73 @@
+package collector
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var configInfoDesc = newDesc(
+	prefix+"config_info",
+	"Contains the key settings of the running exporter as labels. The value is always 1.",
+	[]string{"refresh_interval", "units", "collectors", "api_url", "max_homes", "concurrency"},
+)
+
+// ConfigInfo is a collector reporting the effective settings of the exporter as netatmo_config_info, so that the
+// configuration of a running instance can be checked from its metrics.
+type ConfigInfo struct {
+	// RefreshInterval is the refresh interval of the sensor data.
+	RefreshInterval time.Duration
+	// DualUnits is set if imperial units are reported in addition to metric units.
+	DualUnits bool
+	// Collectors contains the names of the enabled collectors.
+	Collectors []string
+	// APIURL is the base URL of the API requested instead of the Netatmo API, empty if the Netatmo API is used.
+	APIURL string
+	// Replay is set if recorded responses are replayed instead of requesting the Netatmo API.
+	Replay bool
+	// MaxHomes is the maximum number of homes collected per scrape, zero if there is no limit.
+	MaxHomes int
+	// Concurrency is the number of homes whose status is requested at the same time.
+	Concurrency int
+}
+
+// Describe implements prometheus.Collector.
+func (i ConfigInfo) Describe(ch chan<- *prometheus.Desc) {
+	ch <- configInfoDesc
+}
+
+// Collect implements prometheus.Collector.
+func (i ConfigInfo) Collect(ch chan<- prometheus.Metric) {
+	units := "metric"
+	if i.DualUnits {
+		units = "metric,imperial"
+	}
+
+	// The credentials of the requests are only sent as their token, but a configured URL can contain the password
+	// of a proxy, which is redacted.
+	apiURL := apiBaseURL
+	switch {
+	case i.Replay:
+		apiURL = "replay"
+	case i.APIURL != "":
+		apiURL = i.APIURL
+		if u, err := url.Parse(i.APIURL); err == nil {
+			apiURL = u.Redacted()
+		}
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		configInfoDesc,
+		prometheus.GaugeValue,
+		1,
+		i.RefreshInterval.String(),
+		units,
+		strings.Join(i.Collectors, ","),
+		apiURL,
+		strconv.Itoa(i.MaxHomes),
+		strconv.Itoa(i.Concurrency),
+	)
+}
@@ -0,0 +1,108 @@
+package collector
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// descDefinition contains the arguments a descriptor has been created with.
+type descDefinition struct {
+	// prefix is the prefix at the start of the fully-qualified name, usually "netatmo_", and name the rest of it.
+	prefix string
+	name   string
+	help   string
+	labels []string
+	// measurement is set for gauges of measured values, like temperatures, which are rounded by Round.
+	measurement bool
+}
+
+// fqName returns the fully-qualified name of the descriptor.
+func (d descDefinition) fqName() string {
+	return d.prefix + d.name
+}
+
+// descDefinitions contains the definitions of the descriptors created using newDesc and newPrefixedDesc.
+var descDefinitions sync.Map
+
+// newDesc creates a descriptor without constant labels and records its definition.
+func newDesc(name, help string, labels []string) *prometheus.Desc {
+	metricPrefix, name := splitPrefix(name)
+	return newPrefixedDesc(metricPrefix, name, help, labels)
+}
+
+// newMeasurementDesc creates a descriptor like newDesc for a gauge of a measured value.
+func newMeasurementDesc(name, help string, labels []string) *prometheus.Desc {
+	metricPrefix, name := splitPrefix(name)
+	return defineDesc(descDefinition{
+		prefix:      metricPrefix,
+		name:        name,
+		help:        help,
+		labels:      labels,
+		measurement: true,
+	})
+}
+
+// newPrefixedDesc creates a descriptor without constant labels for the metric name with metricPrefix at its start
+// and records its definition.
+func newPrefixedDesc(metricPrefix, name, help string, labels []string) *prometheus.Desc {
+	return defineDesc(descDefinition{
+		prefix: metricPrefix,
+		name:   name,
+		help:   help,
+		labels: labels,
+	})
+}
+
+// defineDesc creates a descriptor without constant labels from the definition and records it.
+func defineDesc(def descDefinition) *prometheus.Desc {
+	d := prometheus.NewDesc(def.fqName(), def.help, def.labels, nil)
+	descDefinitions.Store(d, def)
+
+	return d
+}
+
+// newHistogramVec creates a histogram vector without constant labels and records the definition of its descriptor,
+// which is created by the client library.
+func newHistogramVec(name, help string, buckets []float64, labels []string) *prometheus.HistogramVec {
+	v := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    name,
+		Help:    help,
+		Buckets: buckets,
+	}, labels)
+
+	descs := make(chan *prometheus.Desc, 1)
+	v.Describe(descs)
+
+	metricPrefix, name := splitPrefix(name)
+	descDefinitions.Store(<-descs, descDefinition{
+		prefix: metricPrefix,
+		name:   name,
+		help:   help,
+		labels: labels,
+	})
+
+	return v
+}
+
+// splitPrefix splits the name into the default prefix and the rest of the name. The prefix is empty, if the name
+// does not start with it.
+func splitPrefix(name string) (string, string) {
+	if !strings.HasPrefix(name, prefix) {
+		return "", name
+	}
+
+	return prefix, strings.TrimPrefix(name, prefix)
+}
+
+// definition returns the definition of the descriptor. Descriptors not created using newDesc, for example those of
+// other packages, have no definition and are passed on unchanged by the collector wrappers.
+func definition(d *prometheus.Desc) (descDefinition, bool) {
+	def, ok := descDefinitions.Load(d)
+	if !ok {
+		return descDefinition{}, false
+	}
+
+	return def.(descDefinition), true
+}
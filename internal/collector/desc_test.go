@@ -0,0 +1,74 @@
+package collector
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestDefinition(t *testing.T) {
+	tt := []struct {
+		desc    string
+		metric  *prometheus.Desc
+		wantOk  bool
+		wantDef descDefinition
+	}{
+		{
+			desc:   "default prefix",
+			metric: testDescs.thermostatBoilerStatus,
+			wantOk: true,
+			wantDef: descDefinition{
+				prefix: prefix,
+				name:   "thermostat_boiler_status",
+				help:   `Netatmo Energy boiler status (1=on, 0=off). Depending on the boiler status mode per room (source="room") and/or per home (source="home").`,
+				labels: []string{"home_id", "home_name", "room_id", "room_name", "source"},
+			},
+		},
+		{
+			desc:   "custom prefix",
+			metric: newThermostatDescs("company_netatmo_").thermostatTemperature,
+			wantOk: true,
+			wantDef: descDefinition{
+				prefix: "company_netatmo_",
+				name:   "thermostat_temperature",
+				help:   "Netatmo Energy measured room temperature in degrees Celsius.",
+				labels: thermostatLabels,
+				// The temperature is a measured value, which is rounded using --precision.
+				measurement: true,
+			},
+		},
+		{
+			desc:   "histogram",
+			metric: NewAPIStats(nil, false).durations.WithLabelValues("homestatus").(prometheus.Metric).Desc(),
+			wantOk: true,
+			wantDef: descDefinition{
+				prefix: prefix,
+				name:   "api_request_duration_seconds",
+				help:   "Duration of requests to the NetAtmo API in seconds by endpoint, including requests failing without a response.",
+				labels: []string{"endpoint"},
+			},
+		},
+		{
+			desc:   "other package",
+			metric: prometheus.NewDesc("netatmo_exporter_token_valid", "Set to 1 if there is a valid token, 0 otherwise.", nil, nil),
+			wantOk: false,
+		},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			def, ok := definition(tc.metric)
+			if ok != tc.wantOk {
+				t.Fatalf("got ok %v, want %v", ok, tc.wantOk)
+			}
+
+			if !reflect.DeepEqual(def, tc.wantDef) {
+				t.Errorf("got definition %+v, want %+v", def, tc.wantDef)
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package collector
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// dutyCycleState counts the boiler status samples of each home. The counts are kept for the lifetime of the
+// exporter, the ratio is calculated from the samples since the previous scrape.
+type dutyCycleState struct {
+	sync.Mutex
+	homes map[string]*dutyCycleSamples
+}
+
+type dutyCycleSamples struct {
+	name  string
+	on    float64
+	total float64
+	// scrapedOn and scrapedTotal contain the counts at the previous scrape.
+	scrapedOn    float64
+	scrapedTotal float64
+}
+
+// add records a sample of the boiler status of a home.
+func (s *dutyCycleState) add(homeID, homeName string, on bool) {
+	s.Lock()
+	defer s.Unlock()
+
+	samples, ok := s.homes[homeID]
+	if !ok {
+		samples = &dutyCycleSamples{}
+		s.homes[homeID] = samples
+	}
+
+	samples.name = homeName
+	samples.total++
+	if on {
+		samples.on++
+	}
+}
+
+// collect reports the sample counters of all homes and the duty cycle of the homes with samples since the previous
+// scrape.
+func (s *dutyCycleState) collect(ch chan<- prometheus.Metric, descs *thermostatDescs) {
+	s.Lock()
+	defer s.Unlock()
+
+	for homeID, samples := range s.homes {
+		ch <- prometheus.MustNewConstMetric(descs.boilerSamples, prometheus.CounterValue, samples.total, homeID, samples.name)
+		ch <- prometheus.MustNewConstMetric(descs.boilerOnSamples, prometheus.CounterValue, samples.on, homeID, samples.name)
+
+		if samples.total > samples.scrapedTotal {
+			ratio := (samples.on - samples.scrapedOn) / (samples.total - samples.scrapedTotal)
+			ch <- prometheus.MustNewConstMetric(descs.boilerDutyCycle, prometheus.GaugeValue, ratio, homeID, samples.name)
+		}
+
+		samples.scrapedOn = samples.on
+		samples.scrapedTotal = samples.total
+	}
+}
+
+// RunBoilerSampler samples the boiler status of all homes once per boiler sample interval until the context is
+// done, so that the duty cycle also covers the time between scrapes. It returns immediately if sampling is
+// disabled. Only homes which have already been returned by homesdata during a scrape are sampled.
+func (c *ThermostatCollector) RunBoilerSampler(ctx context.Context) {
+	if c.boilerSampleInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(c.boilerSampleInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.sampleBoilers(ctx)
+		}
+	}
+}
+
+func (c *ThermostatCollector) sampleBoilers(ctx context.Context) {
+	c.homesLock.Lock()
+	homes := c.cachedHomes
+	c.homesLock.Unlock()
+
+	first := true
+	for _, home := range homes {
+		if c.excluded(home) {
+			continue
+		}
+
+		if !first && c.homeStatusDelay > 0 {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(c.homeStatusDelay):
+			}
+		}
+		first = false
+
+		status, err := c.client.HomeStatus(ctx, home.ID, c.deviceTypes)
+		if err != nil {
+			logAPIError(c.log, err, "ThermostatCollector: error sampling boiler status for %s", home.ID)
+			continue
+		}
+
+		if on, ok := homeBoilerOn(status.Body.Home.Modules); ok {
+			c.dutyCycle.add(home.ID, home.Name, on)
+			c.boilerCycles.observe(home.ID, on)
+		}
+
+		if c.roomHeatingTime {
+			c.observeRoomsHeating(home, status.Body.Home.Rooms)
+		}
+	}
+}
+
+// homeBoilerOn returns true if any module reports the boiler to be on. ok is false if no module reports a boiler
+// status.
+func homeBoilerOn(modules []moduleStatus) (on, ok bool) {
+	for _, mod := range modules {
+		if mod.BoilerStatus == nil {
+			continue
+		}
+
+		ok = true
+		if *mod.BoilerStatus {
+			on = true
+		}
+	}
+
+	return on, ok
+}
@@ -0,0 +1,96 @@
+package collector
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sirupsen/logrus"
+)
+
+func TestThermostatCollector_DutyCycle(t *testing.T) {
+	boilerOn := `{"body":{"home":{"id":"home","modules":[{"id":"relay","type":"NAPlug","boiler_status":true}]}}}`
+	boilerOff := `{"body":{"home":{"id":"home","modules":[{"id":"relay","type":"NAPlug","boiler_status":false}]}}}`
+
+	client := &fakeClient{
+		homesData: mustDecode[HomesDataResponse](t, `{"body":{"homes":[{"id":"home","name":"Home"}]}}`),
+		homeStatus: map[string]*HomeStatusResponse{
+			"home": mustDecode[HomeStatusResponse](t, boilerOn),
+		},
+	}
+	c := NewThermostatCollector(logrus.New(), client, WithBoilerSampleInterval(time.Minute))
+
+	want := func(ratio, on, total string) string {
+		return `# HELP netatmo_boiler_duty_cycle_ratio Netatmo Energy fraction of the boiler status samples since the last scrape during which the boiler of a home was on. Only correct with a single Prometheus server scraping the exporter, otherwise use the rates of the sample counters.
+# TYPE netatmo_boiler_duty_cycle_ratio gauge
+netatmo_boiler_duty_cycle_ratio{home_id="home",home_name="Home"} ` + ratio + `
+# HELP netatmo_boiler_status_on_samples_total Netatmo Energy number of boiler status samples of a home taken by the exporter during which the boiler was on.
+# TYPE netatmo_boiler_status_on_samples_total counter
+netatmo_boiler_status_on_samples_total{home_id="home",home_name="Home"} ` + on + `
+# HELP netatmo_boiler_status_samples_total Netatmo Energy number of boiler status samples of a home taken by the exporter.
+# TYPE netatmo_boiler_status_samples_total counter
+netatmo_boiler_status_samples_total{home_id="home",home_name="Home"} ` + total + "\n"
+	}
+	names := []string{"netatmo_boiler_duty_cycle_ratio", "netatmo_boiler_status_on_samples_total", "netatmo_boiler_status_samples_total"}
+
+	if err := testutil.CollectAndCompare(c, strings.NewReader(want("1", "1", "1")), names...); err != nil {
+		t.Errorf("metrics of first scrape differ: %s", err)
+	}
+
+	client.homeStatus["home"] = mustDecode[HomeStatusResponse](t, boilerOff)
+	for range 3 {
+		c.sampleBoilers(context.Background())
+	}
+	client.homeStatus["home"] = mustDecode[HomeStatusResponse](t, boilerOn)
+
+	// The counters keep the samples of the first scrape, while the ratio only contains the samples since then.
+	if err := testutil.CollectAndCompare(c, strings.NewReader(want("0.25", "2", "5")), names...); err != nil {
+		t.Errorf("metrics after sampling differ: %s", err)
+	}
+}
+
+func TestHomeBoilerOn(t *testing.T) {
+	boolPtr := func(b bool) *bool {
+		return &b
+	}
+
+	tt := []struct {
+		desc    string
+		modules []moduleStatus
+		wantOn  bool
+		wantOk  bool
+	}{
+		{
+			desc:    "no boiler status",
+			modules: []moduleStatus{{ID: "valve"}},
+		},
+		{
+			desc:    "off",
+			modules: []moduleStatus{{ID: "relay", BoilerStatus: boolPtr(false)}},
+			wantOk:  true,
+		},
+		{
+			desc: "one of two boilers on",
+			modules: []moduleStatus{
+				{ID: "relay-a", BoilerStatus: boolPtr(false)},
+				{ID: "relay-b", BoilerStatus: boolPtr(true)},
+			},
+			wantOn: true,
+			wantOk: true,
+		},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			on, ok := homeBoilerOn(tc.modules)
+			if on != tc.wantOn || ok != tc.wantOk {
+				t.Errorf("got (%v, %v), want (%v, %v)", on, ok, tc.wantOn, tc.wantOk)
+			}
+		})
+	}
+}
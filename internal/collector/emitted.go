@@ -0,0 +1,93 @@
+package collector
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var metricsEmittedDesc = newDesc(
+	prefix+"metrics_emitted_total",
+	"Number of samples of a metric emitted by the most recent collection of the exporter.",
+	[]string{"metric"},
+)
+
+// EmittedCounter counts the samples emitted by the collectors wrapped using Count, so that a growing number of
+// series, for example because of renamed rooms, can be noticed. It is a collector itself, which reports the
+// counts of the most recent collection of every wrapped collector.
+type EmittedCounter struct {
+	lock   sync.Mutex
+	counts []map[string]float64
+}
+
+// NewEmittedCounter creates an EmittedCounter without any wrapped collectors.
+func NewEmittedCounter() *EmittedCounter {
+	return &EmittedCounter{}
+}
+
+// Count wraps a collector, so that the samples it emits are counted by name.
+func (e *EmittedCounter) Count(c prometheus.Collector) prometheus.Collector {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	e.counts = append(e.counts, map[string]float64{})
+
+	return &countedCollector{
+		Collector: c,
+		counter:   e,
+		index:     len(e.counts) - 1,
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (e *EmittedCounter) Describe(ch chan<- *prometheus.Desc) {
+	ch <- metricsEmittedDesc
+}
+
+// Collect implements prometheus.Collector.
+func (e *EmittedCounter) Collect(ch chan<- prometheus.Metric) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	totals := map[string]float64{}
+	for _, counts := range e.counts {
+		for name, count := range counts {
+			totals[name] += count
+		}
+	}
+
+	for name, count := range totals {
+		ch <- prometheus.MustNewConstMetric(metricsEmittedDesc, prometheus.GaugeValue, count, name)
+	}
+}
+
+func (e *EmittedCounter) update(index int, counts map[string]float64) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	e.counts[index] = counts
+}
+
+type countedCollector struct {
+	prometheus.Collector
+	counter *EmittedCounter
+	index   int
+}
+
+func (c *countedCollector) Collect(ch chan<- prometheus.Metric) {
+	metrics := make(chan prometheus.Metric)
+	go func() {
+		c.Collector.Collect(metrics)
+		close(metrics)
+	}()
+
+	counts := map[string]float64{}
+	for m := range metrics {
+		if def, ok := definition(m.Desc()); ok {
+			counts[def.fqName()]++
+		}
+		ch <- m
+	}
+
+	c.counter.update(c.index, counts)
+}
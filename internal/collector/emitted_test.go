@@ -0,0 +1,37 @@
+package collector
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestEmittedCounter(t *testing.T) {
+	counter := NewEmittedCounter()
+	thermostat := counter.Count(staticCollector{
+		prometheus.MustNewConstMetric(testDescs.thermostatTemperature, prometheus.GaugeValue, 21, "home", "Home", "living", "Living"),
+		prometheus.MustNewConstMetric(testDescs.thermostatTemperature, prometheus.GaugeValue, 19, "home", "Home", "bath", "Bath"),
+		prometheus.MustNewConstMetric(testDescs.moduleNeedsAttention, prometheus.GaugeValue, 0, "home", "Home", "valve", "Valve"),
+	})
+	weather := counter.Count(staticCollector{
+		prometheus.MustNewConstMetric(weatherModuleNeedsAttentionDesc, prometheus.GaugeValue, 1, "home", "Home", "outdoor", "Outdoor"),
+	})
+
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(thermostat, weather)
+	if _, err := registry.Gather(); err != nil {
+		t.Fatalf("can not gather metrics: %s", err)
+	}
+
+	wantMetrics := `# HELP netatmo_metrics_emitted_total Number of samples of a metric emitted by the most recent collection of the exporter.
+# TYPE netatmo_metrics_emitted_total gauge
+netatmo_metrics_emitted_total{metric="netatmo_module_needs_attention"} 1
+netatmo_metrics_emitted_total{metric="netatmo_thermostat_temperature"} 2
+netatmo_metrics_emitted_total{metric="netatmo_weather_module_needs_attention"} 1
+`
+	if err := testutil.CollectAndCompare(counter, strings.NewReader(wantMetrics)); err != nil {
+		t.Error(err)
+	}
+}
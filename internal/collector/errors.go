@@ -0,0 +1,196 @@
+package collector
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	netatmo "github.com/exzz/netatmo-api-go"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/oauth2"
+)
+
+// APIError is returned by the fetch helpers when a request to the Netatmo API fails.
+type APIError struct {
+	// Endpoint contains the name of the API endpoint, for example "homestatus".
+	Endpoint string
+	// StatusCode contains the HTTP status code of the response or zero if no response was received.
+	StatusCode int
+	// Err contains the underlying error.
+	Err error
+
+	transient bool
+	hint      string
+	detail    string
+	// retryAfter is the delay requested by the Retry-After header of the response, zero if there was none.
+	retryAfter time.Duration
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s request failed: %s", e.Endpoint, e.Err)
+}
+
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+// Transient returns true, if the error is likely to go away when the request is retried.
+func (e *APIError) Transient() bool {
+	return e.transient
+}
+
+// Hint returns a human-readable suggestion how to fix a permanent error. It is empty for transient errors.
+func (e *APIError) Hint() string {
+	return e.hint
+}
+
+// IsTransient returns true, if err contains an APIError that is worth retrying.
+func IsTransient(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Transient()
+	}
+
+	return false
+}
+
+// requestedDelay returns the delay before the next attempt requested by the API using the Retry-After header of the
+// response contained in err. ok is false if no delay was requested.
+func requestedDelay(err error) (delay time.Duration, ok bool) {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.retryAfter > 0 {
+		return apiErr.retryAfter, true
+	}
+
+	return 0, false
+}
+
+// parseRetryAfter parses the value of a Retry-After header, which is either a number of seconds or a date. It
+// returns zero if the value is invalid or in the past.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0)
+	}
+
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0
+	}
+
+	return max(date.Sub(now), 0)
+}
+
+func newTransportError(endpoint string, err error) *APIError {
+	return &APIError{
+		Endpoint:  endpoint,
+		Err:       err,
+		transient: true,
+	}
+}
+
+// newTokenError classifies an error returned by the token source before a request. A refresh rejected by the
+// Netatmo API is permanent, other failed refreshes are usually caused by the network and are transient.
+func newTokenError(endpoint string, err error) *APIError {
+	apiErr := &APIError{
+		Endpoint:  endpoint,
+		Err:       fmt.Errorf("error getting token: %w", err),
+		transient: true,
+	}
+
+	var retrieveErr *oauth2.RetrieveError
+	switch {
+	case errors.As(err, &retrieveErr):
+		apiErr.transient = false
+		apiErr.hint = "token refresh was rejected, the exporter probably needs to be re-authenticated"
+	case errors.Is(err, netatmo.ErrNotAuthenticated):
+		apiErr.transient = false
+	}
+
+	return apiErr
+}
+
+func newStatusError(endpoint string, resp *http.Response) *APIError {
+	apiErr := &APIError{
+		Endpoint:   endpoint,
+		StatusCode: resp.StatusCode,
+		Err:        fmt.Errorf("status %s", resp.Status),
+	}
+
+	switch code := resp.StatusCode; {
+	case code == http.StatusRequestTimeout, code == http.StatusTooManyRequests, code >= http.StatusInternalServerError:
+		apiErr.transient = true
+		apiErr.retryAfter = parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+	case code == http.StatusUnauthorized:
+		apiErr.hint = "access token was not accepted, the exporter probably needs to be re-authenticated"
+	case code == http.StatusForbidden:
+		apiErr.hint = "access was denied, check that the token has the scopes needed for this endpoint (read_station, read_thermostat, read_camera)"
+	case code == http.StatusBadRequest:
+		apiErr.hint = "request was rejected, check the client ID and secret of the NetAtmo app"
+	}
+
+	return apiErr
+}
+
+// Error codes contained in the body of responses of the Netatmo API.
+const (
+	responseErrorInvalidToken = 2
+	responseErrorTokenExpired = 3
+	responseErrorScope        = 13
+	responseErrorUsageReached = 26
+)
+
+// ResponseError is an error contained in the body of a response of the Netatmo API. The API sometimes responds with
+// status 200 and such an error instead of the data, for example if the token is missing a scope.
+type ResponseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *ResponseError) Error() string {
+	return fmt.Sprintf("API error %d: %s", e.Code, e.Message)
+}
+
+func newResponseError(endpoint string, respErr *ResponseError) *APIError {
+	apiErr := &APIError{
+		Endpoint: endpoint,
+		Err:      respErr,
+	}
+
+	switch respErr.Code {
+	case responseErrorUsageReached:
+		apiErr.transient = true
+	case responseErrorInvalidToken, responseErrorTokenExpired:
+		apiErr.hint = "access token was not accepted, the exporter probably needs to be re-authenticated"
+	case responseErrorScope:
+		apiErr.hint = "access was denied, check that the token has the scopes needed for this endpoint (read_station, read_thermostat, read_camera)"
+	}
+
+	return apiErr
+}
+
+// logAPIError logs transient errors as warnings and permanent errors as errors including a hint, if available.
+func logAPIError(log logrus.FieldLogger, err error, format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.detail != "" {
+		log.Debugf("%s: details of failed response: %s", msg, apiErr.detail)
+	}
+
+	switch {
+	case errors.Is(err, ErrNoToken):
+		log.Debugf("%s: %v", msg, err)
+	case IsTransient(err):
+		log.Warnf("%s: %v", msg, err)
+	case errors.As(err, &apiErr) && apiErr.Hint() != "":
+		log.Errorf("%s: %v (%s)", msg, err, apiErr.Hint())
+	default:
+		log.Errorf("%s: %v", msg, err)
+	}
+}
@@ -0,0 +1,130 @@
+package collector
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestIsTransient(t *testing.T) {
+	statusError := func(code int) error {
+		return newStatusError("homestatus", &http.Response{
+			StatusCode: code,
+			Status:     fmt.Sprintf("%d %s", code, http.StatusText(code)),
+		})
+	}
+
+	tt := []struct {
+		desc          string
+		err           error
+		wantTransient bool
+		wantHint      bool
+	}{
+		{
+			desc:          "plain error",
+			err:           errors.New("test error"),
+			wantTransient: false,
+			wantHint:      false,
+		},
+		{
+			desc:          "transport error",
+			err:           newTransportError("homesdata", errors.New("connection reset")),
+			wantTransient: true,
+			wantHint:      false,
+		},
+		{
+			desc:          "rate limited",
+			err:           statusError(http.StatusTooManyRequests),
+			wantTransient: true,
+		},
+		{
+			desc:          "server error",
+			err:           statusError(http.StatusBadGateway),
+			wantTransient: true,
+		},
+		{
+			desc:          "bad request",
+			err:           statusError(http.StatusBadRequest),
+			wantTransient: false,
+			wantHint:      true,
+		},
+		{
+			desc:          "unauthorized",
+			err:           statusError(http.StatusUnauthorized),
+			wantTransient: false,
+			wantHint:      true,
+		},
+		{
+			desc:          "forbidden",
+			err:           fmt.Errorf("wrapped: %w", statusError(http.StatusForbidden)),
+			wantTransient: false,
+			wantHint:      true,
+		},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			if got := IsTransient(tc.err); got != tc.wantTransient {
+				t.Errorf("got transient %v, want %v", got, tc.wantTransient)
+			}
+
+			var apiErr *APIError
+			hasHint := errors.As(tc.err, &apiErr) && apiErr.Hint() != ""
+			if hasHint != tc.wantHint {
+				t.Errorf("got hint %v, want %v", hasHint, tc.wantHint)
+			}
+		})
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tt := []struct {
+		desc  string
+		value string
+		want  time.Duration
+	}{
+		{
+			desc:  "empty",
+			value: "",
+			want:  0,
+		},
+		{
+			desc:  "seconds",
+			value: "30",
+			want:  30 * time.Second,
+		},
+		{
+			desc:  "date",
+			value: "Mon, 01 Jan 2024 12:01:00 GMT",
+			want:  time.Minute,
+		},
+		{
+			desc:  "date in the past",
+			value: "Mon, 01 Jan 2024 11:00:00 GMT",
+			want:  0,
+		},
+		{
+			desc:  "invalid",
+			value: "soon",
+			want:  0,
+		},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			if got := parseRetryAfter(tc.value, now); got != tc.want {
+				t.Errorf("got %s, want %s", got, tc.want)
+			}
+		})
+	}
+}
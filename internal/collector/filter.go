@@ -0,0 +1,138 @@
+package collector
+
+import (
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// MetricFilter decides which metrics are emitted based on their name without the "netatmo_" prefix or the custom
+// metric prefix.
+type MetricFilter struct {
+	metricPrefix string
+	enabled      map[string]bool
+	disabled     map[string]bool
+}
+
+// NewMetricFilter creates a filter from a list of enabled and disabled metric names. If the list of enabled
+// metrics is empty, all metrics which have not been disabled are emitted. The names can contain "netatmo_" or
+// metricPrefix, which is also removed from the names of the metrics, so that the names match with either prefix.
+func NewMetricFilter(enabled, disabled []string, metricPrefix string) *MetricFilter {
+	f := &MetricFilter{
+		metricPrefix: metricPrefix,
+	}
+	f.enabled = f.toSet(enabled)
+	f.disabled = f.toSet(disabled)
+
+	return f
+}
+
+// Empty returns true, if the filter lets all metrics pass.
+func (f *MetricFilter) Empty() bool {
+	return len(f.enabled) == 0 && len(f.disabled) == 0
+}
+
+// Allowed returns true, if the metric with the provided fully-qualified name should be emitted.
+func (f *MetricFilter) Allowed(fqName string) bool {
+	name := f.trimPrefix(fqName)
+	if f.disabled[name] {
+		return false
+	}
+
+	return len(f.enabled) == 0 || f.enabled[name]
+}
+
+// trimPrefix removes the custom metric prefix or the default prefix from a metric name.
+func (f *MetricFilter) trimPrefix(name string) string {
+	if f.metricPrefix != "" && strings.HasPrefix(name, f.metricPrefix) {
+		return strings.TrimPrefix(name, f.metricPrefix)
+	}
+
+	return strings.TrimPrefix(name, prefix)
+}
+
+func (f *MetricFilter) toSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[f.trimPrefix(v)] = true
+	}
+
+	return set
+}
+
+// Filter wraps a collector, so that only descriptors and metrics allowed by the filter are passed on.
+func Filter(c prometheus.Collector, filter *MetricFilter) prometheus.Collector {
+	if filter.Empty() {
+		return c
+	}
+
+	return &filteredCollector{
+		collector: c,
+		filter:    filter,
+	}
+}
+
+type filteredCollector struct {
+	collector prometheus.Collector
+	filter    *MetricFilter
+}
+
+func (c *filteredCollector) Describe(ch chan<- *prometheus.Desc) {
+	descs := make(chan *prometheus.Desc)
+	go func() {
+		c.collector.Describe(descs)
+		close(descs)
+	}()
+
+	for d := range descs {
+		if c.allowed(d) {
+			ch <- d
+		}
+	}
+}
+
+func (c *filteredCollector) Collect(ch chan<- prometheus.Metric) {
+	metrics := make(chan prometheus.Metric)
+	go func() {
+		c.collector.Collect(metrics)
+		close(metrics)
+	}()
+
+	for m := range metrics {
+		if c.allowed(m.Desc()) {
+			ch <- m
+		}
+	}
+}
+
+func (c *filteredCollector) allowed(d *prometheus.Desc) bool {
+	def, ok := definition(d)
+	if !ok {
+		// Metrics of other packages are filtered using FilterGatherer.
+		return true
+	}
+
+	return c.filter.Allowed(def.fqName())
+}
+
+// FilterGatherer wraps a gatherer, so that only the metric families allowed by the filter are passed on. It is used
+// for collectors of other packages, whose descriptors do not contain a definition the name can be taken from.
+func FilterGatherer(g prometheus.Gatherer, filter *MetricFilter) prometheus.Gatherer {
+	if filter.Empty() {
+		return g
+	}
+
+	return prometheus.GathererFunc(func() ([]*dto.MetricFamily, error) {
+		families, err := g.Gather()
+
+		allowed := families[:0]
+		for _, family := range families {
+			if filter.Allowed(family.GetName()) {
+				allowed = append(allowed, family)
+			}
+		}
+
+		return allowed, err
+	})
+}
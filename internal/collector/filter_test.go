@@ -0,0 +1,116 @@
+package collector
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// testDescs contains the thermostat descriptors with the default prefix for the tests of the collector wrappers.
+var testDescs = newThermostatDescs(prefix)
+
+type staticCollector []prometheus.Metric
+
+func (c staticCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, m := range c {
+		ch <- m.Desc()
+	}
+}
+
+func (c staticCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, m := range c {
+		ch <- m
+	}
+}
+
+func TestFilter(t *testing.T) {
+	inner := staticCollector{
+		prometheus.MustNewConstMetric(testDescs.thermostatTemperature, prometheus.GaugeValue, 21, "home", "Home", "room", "Room"),
+		prometheus.MustNewConstMetric(testDescs.thermostatBoilerStatus, prometheus.GaugeValue, 1, "home", "Home", "", "", "home"),
+		prometheus.MustNewConstMetric(netatmoUpDesc, prometheus.GaugeValue, 1),
+	}
+
+	tt := []struct {
+		desc        string
+		enabled     []string
+		disabled    []string
+		wantMetrics string
+	}{
+		{
+			desc:     "disabled",
+			disabled: []string{"thermostat_boiler_status", "up"},
+			wantMetrics: `# HELP netatmo_thermostat_temperature Netatmo Energy measured room temperature in degrees Celsius.
+# TYPE netatmo_thermostat_temperature gauge
+netatmo_thermostat_temperature{home_id="home",home_name="Home",room_id="room",room_name="Room"} 21
+`,
+		},
+		{
+			desc:    "enabled with prefix",
+			enabled: []string{"netatmo_up"},
+			wantMetrics: `# HELP netatmo_up Zero if there was an error during the last refresh try.
+# TYPE netatmo_up gauge
+netatmo_up 1
+`,
+		},
+		{
+			desc:     "enabled and disabled",
+			enabled:  []string{"up", "thermostat_boiler_status"},
+			disabled: []string{"up"},
+			wantMetrics: `# HELP netatmo_thermostat_boiler_status Netatmo Energy boiler status (1=on, 0=off). Depending on the boiler status mode per room (source="room") and/or per home (source="home").
+# TYPE netatmo_thermostat_boiler_status gauge
+netatmo_thermostat_boiler_status{home_id="home",home_name="Home",room_id="",room_name="",source="home"} 1
+`,
+		},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			c := Filter(inner, NewMetricFilter(tc.enabled, tc.disabled, ""))
+			if err := testutil.CollectAndCompare(c, strings.NewReader(tc.wantMetrics)); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestMetricFilter_Prefix(t *testing.T) {
+	filter := NewMetricFilter([]string{"thermostat_temperature", "company_netatmo_up"}, []string{"netatmo_thermostat_humidity"}, "company_netatmo_")
+
+	for name, want := range map[string]bool{
+		"company_netatmo_thermostat_temperature": true,
+		"company_netatmo_up":                     true,
+		"netatmo_up":                             true,
+		"company_netatmo_thermostat_humidity":    false,
+		"company_netatmo_thermostat_setpoint":    false,
+	} {
+		if got := filter.Allowed(name); got != want {
+			t.Errorf("got allowed %v for %s, want %v", got, name, want)
+		}
+	}
+}
+
+func TestFilterGatherer(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "netatmo_oauth_token_refreshes_total",
+		Help: "Number of times the access token has been refreshed since the exporter was started.",
+	}), prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "netatmo_exporter_token_valid",
+		Help: "Set to 1 if there is a valid token, 0 otherwise.",
+	}))
+
+	gatherer := FilterGatherer(registry, NewMetricFilter(nil, []string{"oauth_token_refreshes_total"}, prefix))
+
+	wantMetrics := `# HELP netatmo_exporter_token_valid Set to 1 if there is a valid token, 0 otherwise.
+# TYPE netatmo_exporter_token_valid gauge
+netatmo_exporter_token_valid 0
+`
+	if err := testutil.GatherAndCompare(gatherer, strings.NewReader(wantMetrics)); err != nil {
+		t.Error(err)
+	}
+}
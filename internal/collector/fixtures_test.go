@@ -0,0 +1,89 @@
+package collector
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/oauth2"
+)
+
+// fixtureServer serves the JSON files in dir as responses of the Netatmo API. The homestatus responses are read
+// from "homestatus_<home_id>.json".
+func fixtureServer(t *testing.T, dir string) *httptest.Server {
+	t.Helper()
+
+	serveFile := func(w http.ResponseWriter, fileName string) {
+		data, err := os.ReadFile(filepath.Join(dir, fileName))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(data)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/homesdata", func(w http.ResponseWriter, _ *http.Request) {
+		serveFile(w, "homesdata.json")
+	})
+	mux.HandleFunc("/api/homestatus", func(w http.ResponseWriter, r *http.Request) {
+		serveFile(w, "homestatus_"+filepath.Base(r.URL.Query().Get("home_id"))+".json")
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestThermostatCollector_Fixtures(t *testing.T) {
+	tt := []string{
+		"valves-only",
+		"classic-thermostat",
+		"multi-home",
+		"null-rooms",
+		"thermostat-without-room",
+	}
+
+	for _, name := range tt {
+		name := name
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := filepath.Join("testdata", name)
+			server := fixtureServer(t, dir)
+
+			client := &httpNetatmoClient{
+				baseURL: server.URL + "/api/",
+				tokenFunc: func() (*oauth2.Token, error) {
+					return &oauth2.Token{
+						AccessToken: "test-token",
+						Expiry:      time.Now().Add(time.Hour),
+					}, nil
+				},
+			}
+
+			c := NewThermostatCollector(logrus.New(), client)
+			c.clock = func() time.Time {
+				return time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+			}
+
+			want, err := os.Open(filepath.Join(dir, "metrics.prom"))
+			if err != nil {
+				t.Fatalf("error opening expected metrics: %s", err)
+			}
+			defer want.Close()
+
+			if err := testutil.CollectAndCompare(c, want); err != nil {
+				t.Errorf("metrics differ: %s", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package collector
+
+import (
+	"sync"
+	"time"
+)
+
+// heatingSeasonState contains the time each home last had a room calling for heat.
+type heatingSeasonState struct {
+	sync.Mutex
+	lastDemand map[string]time.Time
+}
+
+// WithHeatingSeason reports a home as being in the heating season, if any of its rooms called for heat within
+// window. This can be used to silence heating-related alerts in summer. A window of zero disables the metric.
+func WithHeatingSeason(window time.Duration) ThermostatOption {
+	return func(c *ThermostatCollector) {
+		c.heatingSeasonWindow = window
+	}
+}
+
+// heatingSeason records whether the home currently has rooms calling for heat and returns 1 if the last heat
+// demand was within the heating season window. Homes without heat demand since the start of the exporter are
+// reported as 0.
+func (c *ThermostatCollector) heatingSeason(homeID string, heatDemand float64, now time.Time) float64 {
+	c.heatingSeasonState.Lock()
+	defer c.heatingSeasonState.Unlock()
+
+	if heatDemand > 0 {
+		c.heatingSeasonState.lastDemand[homeID] = now
+		return 1
+	}
+
+	last, ok := c.heatingSeasonState.lastDemand[homeID]
+	if !ok || now.Sub(last) > c.heatingSeasonWindow {
+		return 0
+	}
+
+	return 1
+}
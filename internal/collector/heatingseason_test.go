@@ -0,0 +1,75 @@
+package collector
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sirupsen/logrus"
+)
+
+func TestThermostatCollector_HeatingSeason(t *testing.T) {
+	client := &fakeClient{
+		homesData: mustDecode[HomesDataResponse](t, `{"body":{"homes":[{"id":"home","name":"Home","rooms":[{"id":"room","name":"Bedroom"}]}]}}`),
+	}
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	c := NewThermostatCollector(logrus.New(), client, WithHeatingSeason(6*time.Hour))
+	c.clock = func() time.Time {
+		return now
+	}
+
+	tt := []struct {
+		desc         string
+		advance      time.Duration
+		powerRequest float64
+		want         string
+	}{
+		{
+			desc:         "no heat demand yet",
+			powerRequest: 0,
+			want:         "0",
+		},
+		{
+			desc:         "heat demand",
+			advance:      time.Hour,
+			powerRequest: 40,
+			want:         "1",
+		},
+		{
+			desc:         "within window",
+			advance:      5 * time.Hour,
+			powerRequest: 0,
+			want:         "1",
+		},
+		{
+			desc:         "window passed",
+			advance:      2 * time.Hour,
+			powerRequest: 0,
+			want:         "0",
+		},
+		{
+			desc:         "heat demand again",
+			advance:      time.Hour,
+			powerRequest: 10,
+			want:         "1",
+		},
+	}
+
+	for _, tc := range tt {
+		now = now.Add(tc.advance)
+		client.homeStatus = map[string]*HomeStatusResponse{
+			"home": mustDecode[HomeStatusResponse](t, fmt.Sprintf(`{"body":{"home":{"id":"home","rooms":[
+				{"id":"room","heating_power_request":%v}
+			]}}}`, tc.powerRequest)),
+		}
+
+		want := `# HELP netatmo_heating_active_season Netatmo Energy heating season of a home (1=a room called for heat within the configured window, 0=otherwise).
+# TYPE netatmo_heating_active_season gauge
+netatmo_heating_active_season{home_id="home",home_name="Home"} ` + tc.want + "\n"
+		if err := testutil.CollectAndCompare(c, strings.NewReader(want), "netatmo_heating_active_season"); err != nil {
+			t.Errorf("%s: metrics differ: %s", tc.desc, err)
+		}
+	}
+}
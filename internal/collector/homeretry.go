@@ -0,0 +1,74 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// homeRetryState counts the homestatus retries of each home.
+type homeRetryState struct {
+	sync.Mutex
+	names   map[string]string
+	retries map[string]float64
+}
+
+// WithHomeStatusRetries retries the homestatus request of a single home up to retries times, waiting delay before
+// each retry, if it fails with a server error. This is independent of the retries of the NetatmoClient and covers
+// the API failing for one home for longer while the other homes work. Zero retries disable this.
+func WithHomeStatusRetries(retries int, delay time.Duration) ThermostatOption {
+	return func(c *ThermostatCollector) {
+		c.homeStatusRetries = retries
+		c.homeStatusRetryDelay = delay
+	}
+}
+
+// requestHomeStatus requests the status of a home and retries server errors according to the homestatus retries.
+func (c *ThermostatCollector) requestHomeStatus(ctx context.Context, home homeData) (*HomeStatusResponse, error) {
+	for attempt := 0; ; attempt++ {
+		status, err := c.client.HomeStatus(ctx, home.ID, c.deviceTypes)
+		if err == nil || attempt >= c.homeStatusRetries || !isServerError(err) {
+			return status, err
+		}
+
+		c.log.Debugf("ThermostatCollector: retrying homestatus for %s after error: %v", home.ID, err)
+		select {
+		case <-ctx.Done():
+			return nil, err
+		case <-time.After(c.homeStatusRetryDelay):
+		}
+
+		c.homeRetries.add(home.ID, home.Name)
+	}
+}
+
+// isServerError returns true if err contains an APIError with a 5xx status code.
+func isServerError(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode >= http.StatusInternalServerError
+	}
+
+	return false
+}
+
+func (s *homeRetryState) add(homeID, homeName string) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.names[homeID] = homeName
+	s.retries[homeID]++
+}
+
+func (s *homeRetryState) collect(ch chan<- prometheus.Metric, desc *prometheus.Desc) {
+	s.Lock()
+	defer s.Unlock()
+
+	for homeID, retries := range s.retries {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.CounterValue, retries, homeID, s.names[homeID])
+	}
+}
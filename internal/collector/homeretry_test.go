@@ -0,0 +1,98 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// flakyClient fails the homestatus requests with a server error until failures is used up.
+type flakyClient struct {
+	fakeClient
+	failures int
+	err      error
+}
+
+func (c *flakyClient) HomeStatus(ctx context.Context, homeID string, deviceTypes []string) (*HomeStatusResponse, error) {
+	if c.failures > 0 {
+		c.failures--
+		return nil, c.err
+	}
+
+	return c.fakeClient.HomeStatus(ctx, homeID, deviceTypes)
+}
+
+func TestThermostatCollector_HomeStatusRetries(t *testing.T) {
+	serverError := &APIError{Endpoint: "homestatus", StatusCode: http.StatusInternalServerError, Err: errors.New("status 500")}
+	badRequest := &APIError{Endpoint: "homestatus", StatusCode: http.StatusBadRequest, Err: errors.New("status 400")}
+
+	tt := []struct {
+		desc        string
+		retries     int
+		failures    int
+		err         error
+		wantErr     bool
+		wantRetries float64
+	}{
+		{
+			desc:     "disabled",
+			retries:  0,
+			failures: 1,
+			err:      serverError,
+			wantErr:  true,
+		},
+		{
+			desc:        "success after retry",
+			retries:     2,
+			failures:    2,
+			err:         serverError,
+			wantRetries: 2,
+		},
+		{
+			desc:        "retries used up",
+			retries:     1,
+			failures:    2,
+			err:         serverError,
+			wantErr:     true,
+			wantRetries: 1,
+		},
+		{
+			desc:     "no retry of client error",
+			retries:  2,
+			failures: 1,
+			err:      badRequest,
+			wantErr:  true,
+		},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			client := &flakyClient{
+				fakeClient: fakeClient{
+					homeStatus: map[string]*HomeStatusResponse{
+						"home": mustDecode[HomeStatusResponse](t, `{"body":{"home":{"id":"home"}}}`),
+					},
+				},
+				failures: tc.failures,
+				err:      tc.err,
+			}
+			c := NewThermostatCollector(logrus.New(), client, WithHomeStatusRetries(tc.retries, time.Millisecond))
+
+			_, err := c.homeStatus(context.Background(), homeData{ID: "home", Name: "Home"})
+			if (err != nil) != tc.wantErr {
+				t.Errorf("got error %v, want error %v", err, tc.wantErr)
+			}
+
+			if got := c.homeRetries.retries["home"]; got != tc.wantRetries {
+				t.Errorf("got %f retries, want %f", got, tc.wantRetries)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package collector
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// homeStatusCache contains the last homestatus response of each home and counts how often it was used.
+type homeStatusCache struct {
+	sync.Mutex
+	entries map[string]cachedHomeStatus
+	names   map[string]string
+	hits    map[string]float64
+}
+
+// cachedHomeStatus is a homestatus response and the time it was received.
+type cachedHomeStatus struct {
+	status  *HomeStatusResponse
+	fetched time.Time
+}
+
+// WithHomeStatusCache reuses the homestatus response of a home for ttl instead of requesting it on every scrape,
+// which reduces the requests to the Netatmo API if the exporter is scraped often or by several Prometheus servers.
+// Only successful responses are cached. Zero disables the cache.
+func WithHomeStatusCache(ttl time.Duration) ThermostatOption {
+	return func(c *ThermostatCollector) {
+		c.homeStatusCacheTTL = ttl
+	}
+}
+
+// cachedStatus returns the cached homestatus response of the home, if it is younger than the TTL of the cache.
+func (c *ThermostatCollector) cachedStatus(homeID string) (*HomeStatusResponse, bool) {
+	if c.homeStatusCacheTTL <= 0 {
+		return nil, false
+	}
+
+	c.homeStatusCache.Lock()
+	defer c.homeStatusCache.Unlock()
+
+	entry, ok := c.homeStatusCache.entries[homeID]
+	if !ok || c.clock().Sub(entry.fetched) >= c.homeStatusCacheTTL {
+		return nil, false
+	}
+
+	return entry.status, true
+}
+
+// homeStatus returns the status of a home from the cache, if it is fresh, and requests it otherwise. The time of
+// successful requests is recorded for the last success of the home.
+func (c *ThermostatCollector) homeStatus(ctx context.Context, home homeData) (*HomeStatusResponse, error) {
+	if status, ok := c.cachedStatus(home.ID); ok {
+		c.homeStatusCache.hit(home.ID, home.Name)
+		return status, nil
+	}
+
+	inFlight := c.homeStatusInFlight.Add(1)
+	c.log.Debugf("ThermostatCollector: requesting homestatus for %s, %d requests in flight", home.ID, inFlight)
+	status, err := c.requestHomeStatus(ctx, home)
+	c.homeStatusInFlight.Add(-1)
+	if err == nil {
+		c.homeSuccess.add(home.ID, home.Name, c.clock())
+	}
+	if err != nil || c.homeStatusCacheTTL <= 0 {
+		return status, err
+	}
+
+	c.homeStatusCache.Lock()
+	defer c.homeStatusCache.Unlock()
+
+	c.homeStatusCache.entries[home.ID] = cachedHomeStatus{
+		status:  status,
+		fetched: c.clock(),
+	}
+
+	return status, nil
+}
+
+func (s *homeStatusCache) hit(homeID, homeName string) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.names[homeID] = homeName
+	s.hits[homeID]++
+}
+
+func (s *homeStatusCache) collect(ch chan<- prometheus.Metric, desc *prometheus.Desc) {
+	s.Lock()
+	defer s.Unlock()
+
+	for homeID, hits := range s.hits {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.CounterValue, hits, homeID, s.names[homeID])
+	}
+}
@@ -0,0 +1,59 @@
+package collector
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sirupsen/logrus"
+)
+
+func TestThermostatCollector_HomeStatusCache(t *testing.T) {
+	status := func(temperature string) *HomeStatusResponse {
+		return mustDecode[HomeStatusResponse](t, `{"body":{"home":{"id":"home","rooms":[
+			{"id":"living","name":"Living Room","therm_measured_temperature":`+temperature+`}
+		]}}}`)
+	}
+
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	client := &fakeClient{
+		homesData: mustDecode[HomesDataResponse](t, `{"body":{"homes":[{"id":"home","name":"Home"}]}}`),
+		homeStatus: map[string]*HomeStatusResponse{
+			"home": status("20.5"),
+		},
+	}
+	c := NewThermostatCollector(logrus.New(), client, WithHomeStatusCache(2*time.Minute))
+	c.clock = func() time.Time {
+		return now
+	}
+
+	want := func(temperature, hits string) string {
+		want := `# HELP netatmo_thermostat_temperature Netatmo Energy measured room temperature in degrees Celsius.
+# TYPE netatmo_thermostat_temperature gauge
+netatmo_thermostat_temperature{home_id="home",home_name="Home",room_id="living",room_name="Living Room"} ` + temperature + "\n"
+		if hits != "" {
+			want = `# HELP netatmo_thermostat_cache_hits_total Netatmo Energy number of collections which used a cached homestatus response of a home instead of requesting it.
+# TYPE netatmo_thermostat_cache_hits_total counter
+netatmo_thermostat_cache_hits_total{home_id="home",home_name="Home"} ` + hits + "\n" + want
+		}
+
+		return want
+	}
+	metrics := []string{"netatmo_thermostat_cache_hits_total", "netatmo_thermostat_temperature"}
+
+	if err := testutil.CollectAndCompare(c, strings.NewReader(want("20.5", "")), metrics...); err != nil {
+		t.Errorf("metrics of first scrape differ: %s", err)
+	}
+
+	client.homeStatus["home"] = status("21")
+	now = now.Add(time.Minute)
+	if err := testutil.CollectAndCompare(c, strings.NewReader(want("20.5", "1")), metrics...); err != nil {
+		t.Errorf("metrics of cached scrape differ: %s", err)
+	}
+
+	now = now.Add(time.Minute)
+	if err := testutil.CollectAndCompare(c, strings.NewReader(want("21", "1")), metrics...); err != nil {
+		t.Errorf("metrics after TTL differ: %s", err)
+	}
+}
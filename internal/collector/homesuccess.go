@@ -0,0 +1,32 @@
+package collector
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// homeSuccessState keeps the time of the last successful homestatus request of each home across scrapes.
+type homeSuccessState struct {
+	sync.Mutex
+	names map[string]string
+	times map[string]time.Time
+}
+
+func (s *homeSuccessState) add(homeID, homeName string, now time.Time) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.names[homeID] = homeName
+	s.times[homeID] = now
+}
+
+func (s *homeSuccessState) collect(ch chan<- prometheus.Metric, desc *prometheus.Desc) {
+	s.Lock()
+	defer s.Unlock()
+
+	for homeID, last := range s.times {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, float64(last.Unix()), homeID, s.names[homeID])
+	}
+}
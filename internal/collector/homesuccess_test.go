@@ -0,0 +1,43 @@
+package collector
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sirupsen/logrus"
+)
+
+func TestThermostatCollector_LastSuccess(t *testing.T) {
+	now := time.Unix(1704110400, 0)
+	client := &fakeClient{
+		homesData: mustDecode[HomesDataResponse](t, `{"body":{"homes":[{"id":"home","name":"Home"},{"id":"flaky","name":"Flaky"}]}}`),
+		homeStatus: map[string]*HomeStatusResponse{
+			"home":  mustDecode[HomeStatusResponse](t, `{"body":{"home":{"id":"home"}}}`),
+			"flaky": mustDecode[HomeStatusResponse](t, `{"body":{"home":{"id":"flaky"}}}`),
+		},
+	}
+	c := NewThermostatCollector(logrus.New(), client)
+	c.clock = func() time.Time {
+		return now
+	}
+
+	want := func(home, flaky string) string {
+		return `# HELP netatmo_thermostat_last_success_timestamp_seconds Netatmo Energy unix timestamp of the last successful homestatus request of a home. It is kept while the requests of the home fail.
+# TYPE netatmo_thermostat_last_success_timestamp_seconds gauge
+netatmo_thermostat_last_success_timestamp_seconds{home_id="flaky",home_name="Flaky"} ` + flaky + `
+netatmo_thermostat_last_success_timestamp_seconds{home_id="home",home_name="Home"} ` + home + "\n"
+	}
+	metric := "netatmo_thermostat_last_success_timestamp_seconds"
+
+	if err := testutil.CollectAndCompare(c, strings.NewReader(want("1.7041104e+09", "1.7041104e+09")), metric); err != nil {
+		t.Errorf("metrics of first scrape differ: %s", err)
+	}
+
+	delete(client.homeStatus, "flaky")
+	now = now.Add(10 * time.Minute)
+	if err := testutil.CollectAndCompare(c, strings.NewReader(want("1.704111e+09", "1.7041104e+09")), metric); err != nil {
+		t.Errorf("metrics after failed scrape differ: %s", err)
+	}
+}
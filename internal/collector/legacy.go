@@ -0,0 +1,131 @@
+package collector
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	legacyThermostatLabels = []string{"device_id", "device_name", "module_id", "module_name"}
+
+	legacyThermostatTemperatureDesc = newMeasurementDesc(
+		prefix+"legacy_thermostat_temperature",
+		"Netatmo Energy measured temperature of a legacy thermostat in degrees Celsius.",
+		legacyThermostatLabels,
+	)
+
+	legacyThermostatSetpointDesc = newMeasurementDesc(
+		prefix+"legacy_thermostat_setpoint",
+		"Netatmo Energy setpoint temperature of a legacy thermostat in degrees Celsius.",
+		legacyThermostatLabels,
+	)
+
+	legacyThermostatRelayCmdDesc = newDesc(
+		prefix+"legacy_thermostat_relay_cmd",
+		"Netatmo Energy relay command sent to the boiler by a legacy thermostat (0=off, 100=on).",
+		legacyThermostatLabels,
+	)
+
+	legacyThermostatBoilerOnDesc = newDesc(
+		prefix+"legacy_thermostat_boiler_on_today_seconds",
+		"Netatmo Energy time in seconds the boiler has been switched on by a legacy thermostat today.",
+		legacyThermostatLabels,
+	)
+)
+
+// LegacyThermostatCollector is a Prometheus collector for first-generation Netatmo thermostats using the legacy
+// getthermostatsdata endpoint, which reports some values missing from homestatus for these devices.
+type LegacyThermostatCollector struct {
+	log    logrus.FieldLogger
+	client NetatmoClient
+}
+
+// NewLegacyThermostatCollector creates a new LegacyThermostatCollector.
+func NewLegacyThermostatCollector(log logrus.FieldLogger, client NetatmoClient) *LegacyThermostatCollector {
+	return &LegacyThermostatCollector{
+		log:    log,
+		client: client,
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *LegacyThermostatCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- legacyThermostatTemperatureDesc
+	ch <- legacyThermostatSetpointDesc
+	ch <- legacyThermostatRelayCmdDesc
+	ch <- legacyThermostatBoilerOnDesc
+}
+
+// Collect implements prometheus.Collector.
+func (c *LegacyThermostatCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx := context.Background()
+
+	data, err := c.client.ThermostatsData(ctx)
+	if err != nil {
+		logAPIError(c.log, err, "LegacyThermostatCollector: error fetching getthermostatsdata")
+		return
+	}
+
+	for _, device := range data.Body.Devices {
+		for _, module := range device.Modules {
+			labels := []string{device.ID, device.StationName, module.ID, module.ModuleName}
+
+			sendOptional(ch, legacyThermostatTemperatureDesc, module.Measured.Temperature, labels...)
+			sendOptional(ch, legacyThermostatSetpointDesc, module.Measured.SetpointTemperature, labels...)
+			sendOptional(ch, legacyThermostatRelayCmdDesc, module.RelayCmd, labels...)
+
+			samples, err := c.client.Measure(ctx, MeasureRequest{
+				DeviceID: device.ID,
+				ModuleID: module.ID,
+				Scale:    "1day",
+				Types:    []string{"sum_boiler_on"},
+				DateEnd:  "last",
+			})
+			if err != nil {
+				logAPIError(c.log, err, "LegacyThermostatCollector: error fetching boiler on-time for %s", module.ID)
+				continue
+			}
+
+			if len(samples) > 0 && len(samples[len(samples)-1].Values) > 0 {
+				sendOptional(ch, legacyThermostatBoilerOnDesc, samples[len(samples)-1].Values[0], labels...)
+			}
+		}
+	}
+}
+
+// ThermostatsDataResponse contains the response of the legacy getthermostatsdata endpoint.
+type ThermostatsDataResponse struct {
+	Body struct {
+		Devices []legacyThermostatDevice `json:"devices"`
+	} `json:"body"`
+}
+
+// legacyThermostatDevice is the relay, which connects the thermostats in Modules.
+type legacyThermostatDevice struct {
+	ID          string                   `json:"_id"`
+	StationName string                   `json:"station_name"`
+	Modules     []legacyThermostatModule `json:"modules"`
+}
+
+type legacyThermostatModule struct {
+	ID         string `json:"_id"`
+	ModuleName string `json:"module_name"`
+	Measured   struct {
+		Temperature         *float64 `json:"temperature"`
+		SetpointTemperature *float64 `json:"setpoint_temp"`
+	} `json:"measured"`
+	RelayCmd *float64 `json:"therm_relay_cmd"`
+}
+
+// ThermostatsData implements NetatmoClient.
+func (c *httpNetatmoClient) ThermostatsData(ctx context.Context) (*ThermostatsDataResponse, error) {
+	var result ThermostatsDataResponse
+	if err := c.get(ctx, "getthermostatsdata", url.Values{}, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
@@ -0,0 +1,82 @@
+package collector
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sirupsen/logrus"
+)
+
+func TestLegacyThermostatCollector(t *testing.T) {
+	floatPtr := func(f float64) *float64 {
+		return &f
+	}
+
+	tt := []struct {
+		desc        string
+		client      *fakeClient
+		wantMetrics string
+	}{
+		{
+			desc:        "error",
+			client:      &fakeClient{},
+			wantMetrics: ``,
+		},
+		{
+			desc: "thermostat",
+			client: &fakeClient{
+				legacy: mustDecode[ThermostatsDataResponse](t, `{"body":{"devices":[{
+					"_id":"relay","station_name":"Relay","type":"NAPlug",
+					"modules":[{
+						"_id":"thermostat","module_name":"Living","type":"NATherm1","therm_relay_cmd":100,
+						"measured":{"time":1700000000,"temperature":19.5,"setpoint_temp":21}
+					}]
+				}]}}`),
+				measure: map[string][]MeasureSample{
+					"thermostat": {
+						{Values: []*float64{floatPtr(3600)}},
+					},
+				},
+			},
+			wantMetrics: `# HELP netatmo_legacy_thermostat_boiler_on_today_seconds Netatmo Energy time in seconds the boiler has been switched on by a legacy thermostat today.
+# TYPE netatmo_legacy_thermostat_boiler_on_today_seconds gauge
+netatmo_legacy_thermostat_boiler_on_today_seconds{device_id="relay",device_name="Relay",module_id="thermostat",module_name="Living"} 3600
+# HELP netatmo_legacy_thermostat_relay_cmd Netatmo Energy relay command sent to the boiler by a legacy thermostat (0=off, 100=on).
+# TYPE netatmo_legacy_thermostat_relay_cmd gauge
+netatmo_legacy_thermostat_relay_cmd{device_id="relay",device_name="Relay",module_id="thermostat",module_name="Living"} 100
+# HELP netatmo_legacy_thermostat_setpoint Netatmo Energy setpoint temperature of a legacy thermostat in degrees Celsius.
+# TYPE netatmo_legacy_thermostat_setpoint gauge
+netatmo_legacy_thermostat_setpoint{device_id="relay",device_name="Relay",module_id="thermostat",module_name="Living"} 21
+# HELP netatmo_legacy_thermostat_temperature Netatmo Energy measured temperature of a legacy thermostat in degrees Celsius.
+# TYPE netatmo_legacy_thermostat_temperature gauge
+netatmo_legacy_thermostat_temperature{device_id="relay",device_name="Relay",module_id="thermostat",module_name="Living"} 19.5
+`,
+		},
+		{
+			desc: "missing values",
+			client: &fakeClient{
+				legacy: mustDecode[ThermostatsDataResponse](t, `{"body":{"devices":[{
+					"_id":"relay","station_name":"Relay",
+					"modules":[{"_id":"thermostat","module_name":"Living","measured":{"temperature":19.5}}]
+				}]}}`),
+			},
+			wantMetrics: `# HELP netatmo_legacy_thermostat_temperature Netatmo Energy measured temperature of a legacy thermostat in degrees Celsius.
+# TYPE netatmo_legacy_thermostat_temperature gauge
+netatmo_legacy_thermostat_temperature{device_id="relay",device_name="Relay",module_id="thermostat",module_name="Living"} 19.5
+`,
+		},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			c := NewLegacyThermostatCollector(logrus.New(), tc.client)
+			if err := testutil.CollectAndCompare(c, strings.NewReader(tc.wantMetrics)); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
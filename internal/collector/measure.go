@@ -0,0 +1,108 @@
+package collector
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MeasureSample contains the values returned by getmeasure for one point in time.
+// The values are in the same order as the requested types, missing values are nil.
+type MeasureSample struct {
+	Time   time.Time
+	Values []*float64
+}
+
+type measureResponse struct {
+	Body []measureBlock `json:"body"`
+}
+
+// measureBlock is one entry of the "optimized" getmeasure response format: values are spaced
+// StepTime seconds apart starting at BeginTime.
+type measureBlock struct {
+	BeginTime int64        `json:"beg_time"`
+	StepTime  int64        `json:"step_time"`
+	Values    [][]*float64 `json:"value"`
+}
+
+func (r *measureResponse) samples() []MeasureSample {
+	var samples []MeasureSample
+	for _, block := range r.Body {
+		for i, values := range block.Values {
+			samples = append(samples, MeasureSample{
+				Time:   time.Unix(block.BeginTime+int64(i)*block.StepTime, 0),
+				Values: values,
+			})
+		}
+	}
+
+	return samples
+}
+
+// MeasureRequest contains the parameters of a getmeasure request.
+type MeasureRequest struct {
+	DeviceID string
+	ModuleID string
+	Scale    string
+	Types    []string
+	// DateBegin is the start of the requested time range. It is omitted if zero.
+	DateBegin time.Time
+	// DateEnd is set to "last" to only return the most recent value or to a Unix timestamp to end the time range.
+	DateEnd string
+	// HomeID and RoomID select the history of a Netatmo Energy room instead of a device. If RoomID is set, the
+	// measurements are requested from getroommeasure and DeviceID and ModuleID are ignored.
+	HomeID string
+	RoomID string
+}
+
+// Measure implements NetatmoClient.
+func (c *httpNetatmoClient) Measure(ctx context.Context, params MeasureRequest) ([]MeasureSample, error) {
+	endpoint := "getmeasure"
+	query := url.Values{}
+	if params.RoomID != "" {
+		endpoint = "getroommeasure"
+		query.Set("home_id", params.HomeID)
+		query.Set("room_id", params.RoomID)
+	} else {
+		query.Set("device_id", params.DeviceID)
+		if params.ModuleID != "" {
+			query.Set("module_id", params.ModuleID)
+		}
+	}
+	query.Set("scale", params.Scale)
+	query.Set("type", strings.Join(params.Types, ","))
+	if !params.DateBegin.IsZero() {
+		query.Set("date_begin", strconv.FormatInt(params.DateBegin.Unix(), 10))
+	}
+	if params.DateEnd != "" {
+		query.Set("date_end", params.DateEnd)
+	}
+	query.Set("optimize", "true")
+
+	var result measureResponse
+	if err := c.get(ctx, endpoint, query, &result); err != nil {
+		return nil, err
+	}
+
+	return result.samples(), nil
+}
+
+// fetchMeasure requests the history of the types, for example "temperature" and "sp_temperature", of a room between
+// begin and end at the given scale, which is one of "30min", "1hour", "3hours", "1day", "1week" or "1month". A zero end
+// requests the history up to now.
+func fetchMeasure(ctx context.Context, client NetatmoClient, homeID, roomID, scale string, types []string, begin, end time.Time) ([]MeasureSample, error) {
+	params := MeasureRequest{
+		HomeID:    homeID,
+		RoomID:    roomID,
+		Scale:     scale,
+		Types:     types,
+		DateBegin: begin,
+	}
+	if !end.IsZero() {
+		params.DateEnd = strconv.FormatInt(end.Unix(), 10)
+	}
+
+	return client.Measure(ctx, params)
+}
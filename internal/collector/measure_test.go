@@ -0,0 +1,75 @@
+package collector
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+func TestFetchMeasure(t *testing.T) {
+	var gotPath, gotQuery string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotQuery = r.URL.RawQuery
+		_, _ = w.Write([]byte(`{"body":[
+			{"beg_time":1704106800,"step_time":1800,"value":[[20.5,21],[20.7,null]]},
+			{"beg_time":1704114000,"step_time":1800,"value":[[21.1,21]]}
+		]}`))
+	}))
+	defer server.Close()
+
+	client := &httpNetatmoClient{
+		baseURL: server.URL + "/api/",
+		tokenFunc: func() (*oauth2.Token, error) {
+			return &oauth2.Token{
+				AccessToken: "test-token",
+				Expiry:      time.Now().Add(time.Hour),
+			}, nil
+		},
+	}
+
+	begin := time.Unix(1704106800, 0)
+	end := time.Unix(1704117600, 0)
+	samples, err := fetchMeasure(context.Background(), client, "home", "living", "30min", []string{"temperature", "sp_temperature"}, begin, end)
+	if err != nil {
+		t.Fatalf("got error: %s", err)
+	}
+
+	if gotPath != "/api/getroommeasure" {
+		t.Errorf("got path %q, want %q", gotPath, "/api/getroommeasure")
+	}
+	wantQuery := "date_begin=1704106800&date_end=1704117600&home_id=home&optimize=true&room_id=living&scale=30min&type=temperature%2Csp_temperature"
+	if gotQuery != wantQuery {
+		t.Errorf("got query %q, want %q", gotQuery, wantQuery)
+	}
+
+	want := []struct {
+		time   int64
+		values []float64
+	}{
+		{time: 1704106800, values: []float64{20.5, 21}},
+		{time: 1704108600, values: []float64{20.7, -1}},
+		{time: 1704114000, values: []float64{21.1, 21}},
+	}
+	if len(samples) != len(want) {
+		t.Fatalf("got %d samples, want %d", len(samples), len(want))
+	}
+	for i, sample := range samples {
+		if sample.Time.Unix() != want[i].time {
+			t.Errorf("sample %d: got time %d, want %d", i, sample.Time.Unix(), want[i].time)
+		}
+		for j, value := range sample.Values {
+			got := -1.0
+			if value != nil {
+				got = *value
+			}
+			if got != want[i].values[j] {
+				t.Errorf("sample %d: got value %d %v, want %v", i, j, got, want[i].values[j])
+			}
+		}
+	}
+}
@@ -0,0 +1,312 @@
+package collector
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+var (
+	homeInfoDesc = newDesc(
+		prefix+"home_info",
+		"Contains the name of a home. Only reported with minimal labels.",
+		[]string{"home_id", "home_name"},
+	)
+
+	zoneInfoDesc = newDesc(
+		prefix+"zone_info",
+		"Contains the name of a schedule zone. Only reported with minimal labels.",
+		[]string{"home_id", "zone_id", "zone_name"},
+	)
+
+	moduleInfoDesc = newDesc(
+		prefix+"module_info",
+		"Contains the name of a module. Only reported with minimal labels.",
+		[]string{"module_id", "module_name"},
+	)
+
+	deviceInfoDesc = newDesc(
+		prefix+"device_info",
+		"Contains the name of a device of the legacy thermostat API. Only reported with minimal labels.",
+		[]string{"device_id", "device_name"},
+	)
+
+	cameraInfoDesc = newDesc(
+		prefix+"camera_info",
+		"Contains the name of a camera. Only reported with minimal labels.",
+		[]string{"camera_id", "camera_name"},
+	)
+
+	// minimalInfos contains the descriptive labels removed by MinimalLabels. Each label is moved to an info metric,
+	// which has the label and the labels identifying the described object. Labels without a descriptor are already
+	// part of an info metric of the collector, like the room names in netatmo_room_info.
+	minimalInfos = []minimalInfo{
+		{label: "home_name", keys: []string{"home_id"}, desc: homeInfoDesc},
+		{label: "room_name", keys: []string{"home_id", "room_id"}},
+		{label: "zone_name", keys: []string{"home_id", "zone_id"}, desc: zoneInfoDesc},
+		{label: "module_name", keys: []string{"module_id"}, desc: moduleInfoDesc},
+		{label: "device_name", keys: []string{"device_id"}, desc: deviceInfoDesc},
+		{label: "camera_name", keys: []string{"camera_id"}, desc: cameraInfoDesc},
+	}
+
+	errMinimalValueType = errors.New("metric type is not supported with minimal labels")
+)
+
+type minimalInfo struct {
+	label string
+	keys  []string
+	desc  *prometheus.Desc
+}
+
+// MinimalLabels removes the descriptive labels, like the names of homes, rooms and modules, from the metrics of the
+// collectors wrapped using Wrap, so that renaming a room does not create new series for all of its metrics. The
+// names are reported in separate info metrics instead, which can be joined using the ID labels. It is a collector
+// itself, which reports the info metrics seen during the most recent collection of every wrapped collector.
+//
+// Metrics ending in "_info" are not changed. Labels are only removed, if the metric has all the labels identifying
+// the described object.
+type MinimalLabels struct {
+	lock  sync.Mutex
+	infos []map[string]infoSeries
+	descs sync.Map
+}
+
+type infoSeries struct {
+	desc   *prometheus.Desc
+	values []string
+}
+
+// minimalDesc contains the descriptor of a metric with the descriptive labels removed.
+type minimalDesc struct {
+	desc   *prometheus.Desc
+	labels []string
+	infos  []minimalInfo
+}
+
+// NewMinimalLabels creates a MinimalLabels without any wrapped collectors.
+func NewMinimalLabels() *MinimalLabels {
+	return &MinimalLabels{}
+}
+
+// Wrap wraps a collector, so that its metrics are reported with minimal labels.
+func (l *MinimalLabels) Wrap(c prometheus.Collector) prometheus.Collector {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.infos = append(l.infos, map[string]infoSeries{})
+
+	return &minimalCollector{
+		Collector: c,
+		labels:    l,
+		index:     len(l.infos) - 1,
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (l *MinimalLabels) Describe(ch chan<- *prometheus.Desc) {
+	for _, info := range minimalInfos {
+		if info.desc != nil {
+			ch <- info.desc
+		}
+	}
+}
+
+// Collect implements prometheus.Collector.
+func (l *MinimalLabels) Collect(ch chan<- prometheus.Metric) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	seen := map[string]bool{}
+	for _, infos := range l.infos {
+		for key, info := range infos {
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			ch <- prometheus.MustNewConstMetric(info.desc, prometheus.GaugeValue, 1, info.values...)
+		}
+	}
+}
+
+func (l *MinimalLabels) update(index int, infos map[string]infoSeries) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.infos[index] = infos
+}
+
+// minimal returns the descriptor with minimal labels for d. The descriptors are shared by all wrapped collectors,
+// so that a descriptor described by several collectors stays identical after removing the labels. A nil result
+// means that the metric is not changed.
+func (l *MinimalLabels) minimal(d *prometheus.Desc) *minimalDesc {
+	if md, ok := l.descs.Load(d); ok {
+		return md.(*minimalDesc)
+	}
+
+	md, _ := l.descs.LoadOrStore(d, newMinimalDesc(d))
+	return md.(*minimalDesc)
+}
+
+func newMinimalDesc(d *prometheus.Desc) *minimalDesc {
+	def, ok := definition(d)
+	if !ok || strings.HasSuffix(def.name, "_info") {
+		return nil
+	}
+	labels := def.labels
+
+	has := make(map[string]bool, len(labels))
+	for _, label := range labels {
+		has[label] = true
+	}
+
+	removed := map[string]bool{}
+	var infos []minimalInfo
+	for _, info := range minimalInfos {
+		if !has[info.label] || !hasAll(has, info.keys) {
+			continue
+		}
+
+		removed[info.label] = true
+		infos = append(infos, info)
+	}
+
+	if len(infos) == 0 {
+		return nil
+	}
+
+	kept := make([]string, 0, len(labels)-len(removed))
+	for _, label := range labels {
+		if !removed[label] {
+			kept = append(kept, label)
+		}
+	}
+
+	def.labels = kept
+	return &minimalDesc{
+		desc:   defineDesc(def),
+		labels: kept,
+		infos:  infos,
+	}
+}
+
+func hasAll(has map[string]bool, labels []string) bool {
+	for _, label := range labels {
+		if !has[label] {
+			return false
+		}
+	}
+
+	return true
+}
+
+type minimalCollector struct {
+	prometheus.Collector
+	labels *MinimalLabels
+	index  int
+}
+
+func (c *minimalCollector) Describe(ch chan<- *prometheus.Desc) {
+	descs := make(chan *prometheus.Desc)
+	go func() {
+		c.Collector.Describe(descs)
+		close(descs)
+	}()
+
+	for d := range descs {
+		if md := c.labels.minimal(d); md != nil {
+			ch <- md.desc
+			continue
+		}
+
+		ch <- d
+	}
+}
+
+func (c *minimalCollector) Collect(ch chan<- prometheus.Metric) {
+	metrics := make(chan prometheus.Metric)
+	go func() {
+		c.Collector.Collect(metrics)
+		close(metrics)
+	}()
+
+	infos := map[string]infoSeries{}
+	for m := range metrics {
+		md := c.labels.minimal(m.Desc())
+		if md == nil {
+			ch <- m
+			continue
+		}
+
+		ch <- md.metric(m, infos)
+	}
+
+	c.labels.update(c.index, infos)
+}
+
+// metric creates a copy of m with minimal labels and adds the info series of the removed labels to infos.
+func (md *minimalDesc) metric(m prometheus.Metric, infos map[string]infoSeries) prometheus.Metric {
+	var out dto.Metric
+	if err := m.Write(&out); err != nil {
+		return prometheus.NewInvalidMetric(md.desc, err)
+	}
+
+	values := make(map[string]string, len(out.Label))
+	for _, pair := range out.Label {
+		values[pair.GetName()] = pair.GetValue()
+	}
+
+	for _, info := range md.infos {
+		if info.desc == nil {
+			continue
+		}
+
+		series := make([]string, 0, len(info.keys)+1)
+		for _, key := range info.keys {
+			series = append(series, values[key])
+		}
+		series = append(series, values[info.label])
+		if slices.Contains(series, "") {
+			// Series without an object, like the home series of the boiler status with an empty room, or
+			// objects without a name have nothing to describe.
+			continue
+		}
+
+		infos[info.label+"\xff"+strings.Join(series, "\xff")] = infoSeries{
+			desc:   info.desc,
+			values: series,
+		}
+	}
+
+	kept := make([]string, 0, len(md.labels))
+	for _, label := range md.labels {
+		kept = append(kept, values[label])
+	}
+
+	var result prometheus.Metric
+	var err error
+	switch {
+	case out.Gauge != nil:
+		result, err = prometheus.NewConstMetric(md.desc, prometheus.GaugeValue, out.Gauge.GetValue(), kept...)
+	case out.Counter != nil:
+		result, err = prometheus.NewConstMetric(md.desc, prometheus.CounterValue, out.Counter.GetValue(), kept...)
+	case out.Untyped != nil:
+		result, err = prometheus.NewConstMetric(md.desc, prometheus.UntypedValue, out.Untyped.GetValue(), kept...)
+	default:
+		err = errMinimalValueType
+	}
+	if err != nil {
+		return prometheus.NewInvalidMetric(md.desc, err)
+	}
+
+	if out.TimestampMs != nil {
+		result = prometheus.NewMetricWithTimestamp(time.UnixMilli(out.GetTimestampMs()), result)
+	}
+
+	return result
+}
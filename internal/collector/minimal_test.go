@@ -0,0 +1,61 @@
+package collector
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMinimalLabels(t *testing.T) {
+	minimal := NewMinimalLabels()
+	thermostat := minimal.Wrap(staticCollector{
+		prometheus.MustNewConstMetric(testDescs.thermostatTemperature, prometheus.GaugeValue, 21, "home", "Home", "living", "Living"),
+		prometheus.MustNewConstMetric(testDescs.thermostatBoilerStatus, prometheus.GaugeValue, 1, "home", "Home", "", "", "home"),
+		prometheus.MustNewConstMetric(testDescs.moduleNeedsAttention, prometheus.GaugeValue, 0, "home", "Home", "valve", "Valve"),
+		prometheus.MustNewConstMetric(testDescs.homeActiveSchedule, prometheus.GaugeValue, 1, "home", "Home", "winter", "Winter"),
+	})
+	weather := minimal.Wrap(staticCollector{
+		prometheus.MustNewConstMetric(weatherModuleNeedsAttentionDesc, prometheus.GaugeValue, 1, "home", "Home", "outdoor", "Outdoor"),
+		prometheus.MustNewConstMetric(netatmoUpDesc, prometheus.GaugeValue, 1),
+	})
+
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(thermostat, weather)
+
+	wantMetrics := `# HELP netatmo_home_active_schedule_info Netatmo Energy active heating schedule of a home. The value is always 1.
+# TYPE netatmo_home_active_schedule_info gauge
+netatmo_home_active_schedule_info{home_id="home",home_name="Home",schedule_id="winter",schedule_name="Winter"} 1
+# HELP netatmo_module_needs_attention Contains 1 if a module has a low battery, is unreachable or has a poor signal, 0 otherwise.
+# TYPE netatmo_module_needs_attention gauge
+netatmo_module_needs_attention{home_id="home",module_id="valve"} 0
+# HELP netatmo_thermostat_boiler_status Netatmo Energy boiler status (1=on, 0=off). Depending on the boiler status mode per room (source="room") and/or per home (source="home").
+# TYPE netatmo_thermostat_boiler_status gauge
+netatmo_thermostat_boiler_status{home_id="home",room_id="",source="home"} 1
+# HELP netatmo_thermostat_temperature Netatmo Energy measured room temperature in degrees Celsius.
+# TYPE netatmo_thermostat_temperature gauge
+netatmo_thermostat_temperature{home_id="home",room_id="living"} 21
+# HELP netatmo_up Zero if there was an error during the last refresh try.
+# TYPE netatmo_up gauge
+netatmo_up 1
+# HELP netatmo_weather_module_needs_attention Netatmo Weather set to 1 if a module has a low battery, is unreachable or has a poor signal, 0 otherwise.
+# TYPE netatmo_weather_module_needs_attention gauge
+netatmo_weather_module_needs_attention{home_id="home",module_id="outdoor"} 1
+`
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(wantMetrics)); err != nil {
+		t.Error(err)
+	}
+
+	wantInfos := `# HELP netatmo_home_info Contains the name of a home. Only reported with minimal labels.
+# TYPE netatmo_home_info gauge
+netatmo_home_info{home_id="home",home_name="Home"} 1
+# HELP netatmo_module_info Contains the name of a module. Only reported with minimal labels.
+# TYPE netatmo_module_info gauge
+netatmo_module_info{module_id="outdoor",module_name="Outdoor"} 1
+netatmo_module_info{module_id="valve",module_name="Valve"} 1
+`
+	if err := testutil.CollectAndCompare(minimal, strings.NewReader(wantInfos)); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,46 @@
+package collector
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	weatherModuleTypeCodeDesc = newDesc(
+		prefix+"weather_module_type_code",
+		"Netatmo Weather numeric code for the type of a module, 0 if the type is unknown. The codes are listed in the README.",
+		[]string{"home_id", "home_name", "module_id", "module_name"},
+	)
+)
+
+// moduleTypeUnknown is the code used for module types missing from moduleTypeCodes.
+const moduleTypeUnknown = 0
+
+// moduleTypeCodes maps the module types reported by the Netatmo API to stable codes. Codes are part of the
+// exported metrics, so existing entries must never change. New hardware types get the next free code.
+var moduleTypeCodes = map[string]int{
+	// Weather station
+	"NAMain":    1,
+	"NAModule1": 2,
+	"NAModule2": 3,
+	"NAModule3": 4,
+	"NAModule4": 5,
+	// Energy
+	"NAPlug":   6,
+	"NATherm1": 7,
+	"NRV":      8,
+	"OTH":      9,
+	"OTM":      10,
+}
+
+// moduleTypeCode returns the code for a module type or moduleTypeUnknown.
+func moduleTypeCode(moduleType string) int {
+	if code, ok := moduleTypeCodes[moduleType]; ok {
+		return code
+	}
+
+	return moduleTypeUnknown
+}
+
+func sendModuleTypeCode(ch chan<- prometheus.Metric, desc *prometheus.Desc, moduleType string, labels ...string) {
+	ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, float64(moduleTypeCode(moduleType)), labels...)
+}
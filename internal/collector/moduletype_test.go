@@ -0,0 +1,60 @@
+package collector
+
+import (
+	"testing"
+)
+
+func TestModuleTypeCode(t *testing.T) {
+	tt := []struct {
+		desc       string
+		moduleType string
+		want       int
+	}{
+		{
+			desc:       "main module",
+			moduleType: "NAMain",
+			want:       1,
+		},
+		{
+			desc:       "valve",
+			moduleType: "NRV",
+			want:       8,
+		},
+		{
+			desc:       "unknown type",
+			moduleType: "NAFoo",
+			want:       moduleTypeUnknown,
+		},
+		{
+			desc:       "empty type",
+			moduleType: "",
+			want:       moduleTypeUnknown,
+		},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			got := moduleTypeCode(tc.moduleType)
+			if got != tc.want {
+				t.Errorf("got %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestModuleTypeCodesUnique(t *testing.T) {
+	seen := map[int]string{}
+	for moduleType, code := range moduleTypeCodes {
+		if code == moduleTypeUnknown {
+			t.Errorf("type %q uses the code for unknown types", moduleType)
+		}
+
+		if other, ok := seen[code]; ok {
+			t.Errorf("types %q and %q share code %d", moduleType, other, code)
+		}
+		seen[code] = moduleType
+	}
+}
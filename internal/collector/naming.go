@@ -0,0 +1,161 @@
+package collector
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// NamingStrategy selects how MetricNaming changes the names of the metrics.
+type NamingStrategy string
+
+const (
+	// NamingDefault keeps the names of the metrics.
+	NamingDefault NamingStrategy = "default"
+	// NamingEnergyPrefix reports the Netatmo Energy metrics with a "netatmo_energy_" prefix.
+	NamingEnergyPrefix NamingStrategy = "energy-prefix"
+	// NamingCelsiusSuffix adds a "_celsius" suffix to temperatures in degrees Celsius which do not have it yet.
+	NamingCelsiusSuffix NamingStrategy = "celsius-suffix"
+)
+
+const energyHelp = "Netatmo Energy "
+
+// rename returns the name of a metric without its prefix, like "thermostat_temperature", changed by the strategy.
+func (s NamingStrategy) rename(name, help string) string {
+	switch s {
+	case NamingEnergyPrefix:
+		if strings.HasPrefix(help, energyHelp) && !strings.HasPrefix(name, "energy_") {
+			return "energy_" + name
+		}
+	case NamingCelsiusSuffix:
+		// Rates of change are in degrees Celsius per hour, which the suffix would not describe correctly.
+		if strings.Contains(help, "in degrees Celsius") && !strings.Contains(help, "in degrees Celsius per ") && !strings.Contains(name, "_celsius") {
+			return name + "_celsius"
+		}
+	}
+
+	return name
+}
+
+// MetricNaming changes the names of the metrics of the collectors wrapped using Wrap, so that they can follow
+// other naming conventions than the default names. The names are changed by the strategy or replaced using the
+// mapping from default names to custom names. The strategy keeps the prefix of the metrics, like the custom prefix
+// of a ThermostatCollector created using WithMetricPrefix.
+//
+// Descriptors with constant labels are not renamed.
+type MetricNaming struct {
+	strategy NamingStrategy
+	names    map[string]string
+	descs    sync.Map
+}
+
+// NewMetricNaming creates a MetricNaming using the strategy and a mapping from the default names of metrics to custom
+// names. The mapping takes precedence over the strategy.
+func NewMetricNaming(strategy NamingStrategy, names map[string]string) *MetricNaming {
+	return &MetricNaming{
+		strategy: strategy,
+		names:    names,
+	}
+}
+
+// Wrap wraps a collector, so that its metrics are reported with the changed names. The collector is returned
+// unchanged if no names are changed.
+func (n *MetricNaming) Wrap(c prometheus.Collector) prometheus.Collector {
+	if (n.strategy == "" || n.strategy == NamingDefault) && len(n.names) == 0 {
+		return c
+	}
+
+	return &namedCollector{
+		Collector: c,
+		naming:    n,
+	}
+}
+
+// name returns the fully-qualified name a metric with the definition is reported with. The custom names are looked
+// up using the default name of the metric, which has the default prefix.
+func (n *MetricNaming) name(def descDefinition) string {
+	if custom, ok := n.names[prefix+def.name]; ok {
+		return custom
+	}
+
+	return def.prefix + n.strategy.rename(def.name, def.help)
+}
+
+// renamed returns the descriptor with the changed name for d, which is d itself if the name does not change.
+func (n *MetricNaming) renamed(d *prometheus.Desc) *prometheus.Desc {
+	if renamed, ok := n.descs.Load(d); ok {
+		return renamed.(*prometheus.Desc)
+	}
+
+	renamed, _ := n.descs.LoadOrStore(d, n.newDesc(d))
+	return renamed.(*prometheus.Desc)
+}
+
+func (n *MetricNaming) newDesc(d *prometheus.Desc) *prometheus.Desc {
+	def, ok := definition(d)
+	if !ok {
+		return d
+	}
+
+	renamed := n.name(def)
+	switch {
+	case renamed == def.fqName():
+		return d
+	case strings.HasPrefix(renamed, def.prefix):
+		def.name = strings.TrimPrefix(renamed, def.prefix)
+	default:
+		def.prefix, def.name = splitPrefix(renamed)
+	}
+
+	return defineDesc(def)
+}
+
+type namedCollector struct {
+	prometheus.Collector
+	naming *MetricNaming
+}
+
+func (c *namedCollector) Describe(ch chan<- *prometheus.Desc) {
+	descs := make(chan *prometheus.Desc)
+	go func() {
+		c.Collector.Describe(descs)
+		close(descs)
+	}()
+
+	for d := range descs {
+		ch <- c.naming.renamed(d)
+	}
+}
+
+func (c *namedCollector) Collect(ch chan<- prometheus.Metric) {
+	metrics := make(chan prometheus.Metric)
+	go func() {
+		c.Collector.Collect(metrics)
+		close(metrics)
+	}()
+
+	for m := range metrics {
+		d := c.naming.renamed(m.Desc())
+		if d == m.Desc() {
+			ch <- m
+			continue
+		}
+
+		ch <- namedMetric{
+			Metric: m,
+			desc:   d,
+		}
+	}
+}
+
+// namedMetric reports a metric using a descriptor with a different name. The labels and the value written by the
+// metric stay the same, because they do not depend on the name.
+type namedMetric struct {
+	prometheus.Metric
+	desc *prometheus.Desc
+}
+
+func (m namedMetric) Desc() *prometheus.Desc {
+	return m.desc
+}
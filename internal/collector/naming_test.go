@@ -0,0 +1,158 @@
+package collector
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestNamingStrategy(t *testing.T) {
+	tt := []struct {
+		desc     string
+		strategy NamingStrategy
+		metric   *prometheus.Desc
+		want     string
+	}{
+		{
+			desc:     "default",
+			strategy: NamingDefault,
+			metric:   testDescs.thermostatTemperature,
+			want:     "thermostat_temperature",
+		},
+		{
+			desc:     "energy prefix",
+			strategy: NamingEnergyPrefix,
+			metric:   testDescs.thermostatTemperature,
+			want:     "energy_thermostat_temperature",
+		},
+		{
+			desc:     "energy prefix of other metric",
+			strategy: NamingEnergyPrefix,
+			metric:   netatmoUpDesc,
+			want:     "up",
+		},
+		{
+			desc:     "celsius suffix",
+			strategy: NamingCelsiusSuffix,
+			metric:   testDescs.thermostatSetpoint,
+			want:     "thermostat_setpoint_celsius",
+		},
+		{
+			desc:     "celsius suffix already present",
+			strategy: NamingCelsiusSuffix,
+			metric:   weatherDewpointDesc,
+			want:     "dewpoint_celsius",
+		},
+		{
+			desc:     "celsius suffix of rate",
+			strategy: NamingCelsiusSuffix,
+			metric:   testDescs.roomTemperatureChange,
+			want:     "room_temperature_change_per_hour",
+		},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			def, ok := definition(tc.metric)
+			if !ok {
+				t.Fatal("descriptor has no definition")
+			}
+
+			if got := tc.strategy.rename(def.name, def.help); got != tc.want {
+				t.Errorf("got name %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestMetricNaming(t *testing.T) {
+	naming := NewMetricNaming(NamingEnergyPrefix, map[string]string{
+		"netatmo_up": "netatmo_sensor_up",
+	})
+	thermostat := naming.Wrap(staticCollector{
+		prometheus.MustNewConstMetric(testDescs.thermostatTemperature, prometheus.GaugeValue, 21, "home", "Home", "living", "Living"),
+		prometheus.MustNewConstMetric(testDescs.moduleNeedsAttention, prometheus.GaugeValue, 0, "home", "Home", "valve", "Valve"),
+	})
+	weather := naming.Wrap(staticCollector{
+		prometheus.MustNewConstMetric(weatherModuleNeedsAttentionDesc, prometheus.GaugeValue, 1, "home", "Home", "outdoor", "Outdoor"),
+		prometheus.MustNewConstMetric(netatmoUpDesc, prometheus.GaugeValue, 1),
+	})
+
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(thermostat, weather)
+
+	wantMetrics := `# HELP netatmo_energy_thermostat_temperature Netatmo Energy measured room temperature in degrees Celsius.
+# TYPE netatmo_energy_thermostat_temperature gauge
+netatmo_energy_thermostat_temperature{home_id="home",home_name="Home",room_id="living",room_name="Living"} 21
+# HELP netatmo_module_needs_attention Contains 1 if a module has a low battery, is unreachable or has a poor signal, 0 otherwise.
+# TYPE netatmo_module_needs_attention gauge
+netatmo_module_needs_attention{home_id="home",home_name="Home",module_id="valve",module_name="Valve"} 0
+# HELP netatmo_sensor_up Zero if there was an error during the last refresh try.
+# TYPE netatmo_sensor_up gauge
+netatmo_sensor_up 1
+# HELP netatmo_weather_module_needs_attention Netatmo Weather set to 1 if a module has a low battery, is unreachable or has a poor signal, 0 otherwise.
+# TYPE netatmo_weather_module_needs_attention gauge
+netatmo_weather_module_needs_attention{home_id="home",home_name="Home",module_id="outdoor",module_name="Outdoor"} 1
+`
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(wantMetrics)); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestMetricNaming_Filter(t *testing.T) {
+	// The filter is applied before renaming like in the exporter, so that it uses the default names.
+	filter := NewMetricFilter(nil, []string{"thermostat_temperature"}, "")
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(NewMetricNaming(NamingEnergyPrefix, nil).Wrap(Filter(staticCollector{
+		prometheus.MustNewConstMetric(testDescs.thermostatTemperature, prometheus.GaugeValue, 21, "home", "Home", "living", "Living"),
+		prometheus.MustNewConstMetric(testDescs.thermostatBoilerStatus, prometheus.GaugeValue, 1, "home", "Home", "", "", "home"),
+	}, filter)))
+
+	wantMetrics := `# HELP netatmo_energy_thermostat_boiler_status Netatmo Energy boiler status (1=on, 0=off). Depending on the boiler status mode per room (source="room") and/or per home (source="home").
+# TYPE netatmo_energy_thermostat_boiler_status gauge
+netatmo_energy_thermostat_boiler_status{home_id="home",home_name="Home",room_id="",room_name="",source="home"} 1
+`
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(wantMetrics)); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestMetricNaming_Default(t *testing.T) {
+	c := NewMinimalLabels()
+	if got := NewMetricNaming(NamingDefault, nil).Wrap(c); got != prometheus.Collector(c) {
+		t.Errorf("got wrapped collector %#v, want unchanged collector", got)
+	}
+}
+
+func TestMetricNaming_Prefix(t *testing.T) {
+	// The strategy keeps the prefix of the thermostat metrics, while custom names use the default names.
+	descs := newThermostatDescs("company_netatmo_")
+	naming := NewMetricNaming(NamingEnergyPrefix, map[string]string{
+		"netatmo_thermostat_setpoint": "company_netatmo_room_setpoint",
+	})
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(naming.Wrap(staticCollector{
+		prometheus.MustNewConstMetric(descs.thermostatTemperature, prometheus.GaugeValue, 21, "home", "Home", "living", "Living"),
+		prometheus.MustNewConstMetric(descs.thermostatSetpoint, prometheus.GaugeValue, 20, "home", "Home", "living", "Living"),
+		prometheus.MustNewConstMetric(descs.moduleNeedsAttention, prometheus.GaugeValue, 0, "home", "Home", "valve", "Valve"),
+	}))
+
+	wantMetrics := `# HELP company_netatmo_energy_thermostat_temperature Netatmo Energy measured room temperature in degrees Celsius.
+# TYPE company_netatmo_energy_thermostat_temperature gauge
+company_netatmo_energy_thermostat_temperature{home_id="home",home_name="Home",room_id="living",room_name="Living"} 21
+# HELP company_netatmo_module_needs_attention Contains 1 if a module has a low battery, is unreachable or has a poor signal, 0 otherwise.
+# TYPE company_netatmo_module_needs_attention gauge
+company_netatmo_module_needs_attention{home_id="home",home_name="Home",module_id="valve",module_name="Valve"} 0
+# HELP company_netatmo_room_setpoint Netatmo Energy target setpoint temperature in degrees Celsius.
+# TYPE company_netatmo_room_setpoint gauge
+company_netatmo_room_setpoint{home_id="home",home_name="Home",room_id="living",room_name="Living"} 20
+`
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(wantMetrics)); err != nil {
+		t.Error(err)
+	}
+}
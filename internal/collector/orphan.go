@@ -0,0 +1,17 @@
+package collector
+
+import "slices"
+
+var (
+	// roomModuleTypes contains the module types which are always assigned to a room.
+	roomModuleTypes = []string{"NATherm1", "NRV", "OTM"}
+)
+
+// isOrphaned returns true if the module should be assigned to a room, but roomID is empty or not one of rooms.
+func isOrphaned(moduleType, roomID string, rooms map[string]bool) bool {
+	if !slices.Contains(roomModuleTypes, moduleType) {
+		return false
+	}
+
+	return !rooms[roomID]
+}
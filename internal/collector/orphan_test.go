@@ -0,0 +1,55 @@
+package collector
+
+import (
+	"testing"
+)
+
+func TestIsOrphaned(t *testing.T) {
+	rooms := map[string]bool{
+		"1001": true,
+	}
+
+	tt := []struct {
+		desc       string
+		moduleType string
+		roomID     string
+		want       bool
+	}{
+		{
+			desc:       "valve in known room",
+			moduleType: "NRV",
+			roomID:     "1001",
+			want:       false,
+		},
+		{
+			desc:       "valve without room",
+			moduleType: "NRV",
+			roomID:     "",
+			want:       true,
+		},
+		{
+			desc:       "thermostat in unknown room",
+			moduleType: "NATherm1",
+			roomID:     "9999",
+			want:       true,
+		},
+		{
+			desc:       "relay without room",
+			moduleType: "NAPlug",
+			roomID:     "",
+			want:       false,
+		},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			got := isOrphaned(tc.moduleType, tc.roomID, rooms)
+			if got != tc.want {
+				t.Errorf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package collector
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// OutputConfig contains the settings of how the metrics of the exporter are reported.
+type OutputConfig struct {
+	// Filter removes the metrics which are not enabled.
+	Filter *MetricFilter
+	// Naming changes the names of the metrics.
+	Naming *MetricNaming
+	// Precision is the number of decimal places gauge values are rounded to. Negative values disable rounding.
+	Precision int
+	// MinimalLabels reports the names of homes, rooms and modules in separate info metrics.
+	MinimalLabels bool
+}
+
+// Output applies the settings of an OutputConfig to the collectors of the exporter. It is a collector itself, which
+// reports the number of emitted samples and the info metrics of the minimal labels.
+type Output struct {
+	config  OutputConfig
+	emitted *EmittedCounter
+	minimal *MinimalLabels
+	own     sequence
+}
+
+// NewOutput creates an Output without any wrapped collectors. Metrics are neither filtered nor renamed if the filter
+// or naming of the config are not set.
+func NewOutput(config OutputConfig) *Output {
+	if config.Filter == nil {
+		config.Filter = NewMetricFilter(nil, nil, "")
+	}
+	if config.Naming == nil {
+		config.Naming = NewMetricNaming(NamingDefault, nil)
+	}
+
+	o := &Output{
+		config:  config,
+		emitted: NewEmittedCounter(),
+		minimal: NewMinimalLabels(),
+	}
+
+	o.own = append(o.own, o.config.Naming.Wrap(Filter(o.emitted, o.config.Filter)))
+	if o.config.MinimalLabels {
+		o.own = append(o.own, o.config.Naming.Wrap(Filter(o.minimal, o.config.Filter)))
+	}
+
+	return o
+}
+
+// Wrap wraps a collector, so that its metrics are filtered, renamed, rounded and reported with minimal labels. The
+// emitted samples are counted and the info metrics are reported by the Output.
+func (o *Output) Wrap(c prometheus.Collector) prometheus.Collector {
+	c = o.wrap(c)
+	if o.config.MinimalLabels {
+		c = o.minimal.Wrap(c)
+	}
+
+	return o.emitted.Count(c)
+}
+
+// WrapTransient wraps a collector like Wrap, which only exists during a single request, like the collector of a
+// single home. Its samples are not counted and the info metrics of the minimal labels are reported by the returned
+// collector itself, so that nothing is kept after the request.
+func (o *Output) WrapTransient(c prometheus.Collector) prometheus.Collector {
+	c = o.wrap(c)
+	if !o.config.MinimalLabels {
+		return c
+	}
+
+	minimal := NewMinimalLabels()
+	return sequence{minimal.Wrap(c), o.config.Naming.Wrap(Filter(minimal, o.config.Filter))}
+}
+
+func (o *Output) wrap(c prometheus.Collector) prometheus.Collector {
+	return Round(o.config.Naming.Wrap(Filter(c, o.config.Filter)), o.config.Precision)
+}
+
+// Describe implements prometheus.Collector.
+func (o *Output) Describe(ch chan<- *prometheus.Desc) {
+	o.own.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (o *Output) Collect(ch chan<- prometheus.Metric) {
+	o.own.Collect(ch)
+}
+
+// sequence is a collector, which collects the collectors one after the other, so that the info metrics of minimal
+// labels are collected after the metrics they have been taken from.
+type sequence []prometheus.Collector
+
+func (s sequence) Describe(ch chan<- *prometheus.Desc) {
+	for _, c := range s {
+		c.Describe(ch)
+	}
+}
+
+func (s sequence) Collect(ch chan<- prometheus.Metric) {
+	for _, c := range s {
+		c.Collect(ch)
+	}
+}
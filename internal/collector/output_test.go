@@ -0,0 +1,61 @@
+package collector
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestOutput(t *testing.T) {
+	output := NewOutput(OutputConfig{
+		Filter:        NewMetricFilter(nil, []string{"up"}, prefix),
+		Naming:        NewMetricNaming(NamingEnergyPrefix, nil),
+		Precision:     1,
+		MinimalLabels: true,
+	})
+	thermostat := staticCollector{
+		prometheus.MustNewConstMetric(testDescs.thermostatTemperature, prometheus.GaugeValue, 21.04, "home", "Home", "living", "Living"),
+		prometheus.MustNewConstMetric(netatmoUpDesc, prometheus.GaugeValue, 1),
+	}
+
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(output, output.Wrap(thermostat))
+	if _, err := registry.Gather(); err != nil {
+		t.Fatalf("can not gather metrics: %s", err)
+	}
+
+	wantMetrics := `# HELP netatmo_energy_thermostat_temperature Netatmo Energy measured room temperature in degrees Celsius.
+# TYPE netatmo_energy_thermostat_temperature gauge
+netatmo_energy_thermostat_temperature{home_id="home",room_id="living"} 21
+# HELP netatmo_home_info Contains the name of a home. Only reported with minimal labels.
+# TYPE netatmo_home_info gauge
+netatmo_home_info{home_id="home",home_name="Home"} 1
+# HELP netatmo_metrics_emitted_total Number of samples of a metric emitted by the most recent collection of the exporter.
+# TYPE netatmo_metrics_emitted_total gauge
+netatmo_metrics_emitted_total{metric="netatmo_energy_thermostat_temperature"} 1
+`
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(wantMetrics)); err != nil {
+		t.Error(err)
+	}
+
+	// The home collector of a single request reports its own info metrics and is not counted.
+	home := prometheus.NewPedanticRegistry()
+	home.MustRegister(output.WrapTransient(thermostat))
+
+	wantHome := `# HELP netatmo_energy_thermostat_temperature Netatmo Energy measured room temperature in degrees Celsius.
+# TYPE netatmo_energy_thermostat_temperature gauge
+netatmo_energy_thermostat_temperature{home_id="home",room_id="living"} 21
+# HELP netatmo_home_info Contains the name of a home. Only reported with minimal labels.
+# TYPE netatmo_home_info gauge
+netatmo_home_info{home_id="home",home_name="Home"} 1
+`
+	if err := testutil.GatherAndCompare(home, strings.NewReader(wantHome)); err != nil {
+		t.Error(err)
+	}
+
+	if got := len(output.emitted.counts); got != 1 {
+		t.Errorf("got %d counted collectors, want 1", got)
+	}
+}
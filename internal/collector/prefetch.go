@@ -0,0 +1,77 @@
+package collector
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// homeStatusResult is the result of a homestatus request made ahead of the collection of the home.
+type homeStatusResult struct {
+	status *HomeStatusResponse
+	err    error
+}
+
+// defaultHomeConcurrency is the number of homes whose homestatus is requested at the same time by default.
+const defaultHomeConcurrency = 4
+
+// WithHomeStatusConcurrency requests the homestatus of up to concurrency homes at the same time, which shortens the
+// scrapes of accounts with several homes. The metrics are still created one home after another once all requests are
+// done. The homestatus delay is kept between the start of two requests. Values below two request the homes one after
+// another, which suits accounts with a tight rate-limit. The default is four.
+func WithHomeStatusConcurrency(concurrency int) ThermostatOption {
+	return func(c *ThermostatCollector) {
+		c.homeConcurrency = concurrency
+	}
+}
+
+// effectiveConcurrency returns the number of homestatus requests a collection of the homes runs at the same time.
+func (c *ThermostatCollector) effectiveConcurrency(homes int) int {
+	return min(max(1, c.homeConcurrency), homes)
+}
+
+// prefetchHomeStatus requests the homestatus of the homes using up to the configured number of concurrent requests
+// and returns the results by home ID. It returns after all started requests are done. Homes whose request was not
+// started before the context was done are missing from the results.
+func (c *ThermostatCollector) prefetchHomeStatus(ctx context.Context, homes []homeData) map[string]homeStatusResult {
+	c.log.Debugf("ThermostatCollector: requesting homestatus of %d homes with a concurrency of %d", len(homes), c.effectiveConcurrency(len(homes)))
+
+	var (
+		lock    sync.Mutex
+		results = make(map[string]homeStatusResult, len(homes))
+		slots   = make(chan struct{}, c.homeConcurrency)
+		wg      sync.WaitGroup
+	)
+
+	for i, home := range homes {
+		if _, cached := c.cachedStatus(home.ID); i > 0 && c.homeStatusDelay > 0 && !cached {
+			select {
+			case <-ctx.Done():
+			case <-time.After(c.homeStatusDelay):
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+		case slots <- struct{}{}:
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			status, err := c.homeStatus(ctx, home)
+
+			lock.Lock()
+			defer lock.Unlock()
+			results[home.ID] = homeStatusResult{status: status, err: err}
+		}()
+	}
+	wg.Wait()
+
+	return results
+}
@@ -0,0 +1,216 @@
+package collector
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sirupsen/logrus"
+)
+
+// slowClient delays the homestatus requests and records the highest number of requests running at the same time.
+type slowClient struct {
+	fakeClient
+	delay time.Duration
+
+	lock       sync.Mutex
+	running    int
+	maxRunning int
+}
+
+func (c *slowClient) HomeStatus(ctx context.Context, homeID string, deviceTypes []string) (*HomeStatusResponse, error) {
+	c.lock.Lock()
+	c.running++
+	c.maxRunning = max(c.maxRunning, c.running)
+	c.lock.Unlock()
+
+	time.Sleep(c.delay)
+
+	c.lock.Lock()
+	c.running--
+	c.lock.Unlock()
+
+	return c.fakeClient.HomeStatus(ctx, homeID, deviceTypes)
+}
+
+func TestThermostatCollector_HomeStatusConcurrency(t *testing.T) {
+	room := func(homeID string) *HomeStatusResponse {
+		return mustDecode[HomeStatusResponse](t, `{"body":{"home":{"id":"`+homeID+`","rooms":[
+			{"id":"living","name":"Living Room","therm_measured_temperature":20.5}
+		]}}}`)
+	}
+
+	client := &slowClient{
+		fakeClient: fakeClient{
+			homesData: mustDecode[HomesDataResponse](t, `{"body":{"homes":[
+				{"id":"first","name":"First"},
+				{"id":"broken","name":"Broken"},
+				{"id":"second","name":"Second"},
+				{"id":"third","name":"Third"}
+			]}}`),
+			homeStatus: map[string]*HomeStatusResponse{
+				"first":  room("first"),
+				"second": room("second"),
+				"third":  room("third"),
+			},
+		},
+		delay: 20 * time.Millisecond,
+	}
+	c := NewThermostatCollector(logrus.New(), client, WithHomeStatusConcurrency(2))
+
+	want := `# HELP netatmo_collection_state Netatmo Energy outcome of the last collection. The series of the current state is set to 1, the others to 0.
+# TYPE netatmo_collection_state gauge
+netatmo_collection_state{state="auth_error"} 0
+netatmo_collection_state{state="network_error"} 0
+netatmo_collection_state{state="ok"} 0
+netatmo_collection_state{state="partial"} 1
+netatmo_collection_state{state="rate_limited"} 0
+# HELP netatmo_thermostat_temperature Netatmo Energy measured room temperature in degrees Celsius.
+# TYPE netatmo_thermostat_temperature gauge
+netatmo_thermostat_temperature{home_id="first",home_name="First",room_id="living",room_name="Living Room"} 20.5
+netatmo_thermostat_temperature{home_id="second",home_name="Second",room_id="living",room_name="Living Room"} 20.5
+netatmo_thermostat_temperature{home_id="third",home_name="Third",room_id="living",room_name="Living Room"} 20.5
+`
+	if err := testutil.CollectAndCompare(c, strings.NewReader(want), "netatmo_collection_state", "netatmo_thermostat_temperature"); err != nil {
+		t.Errorf("metrics differ: %s", err)
+	}
+
+	if client.maxRunning != 2 {
+		t.Errorf("got %d concurrent requests, want 2", client.maxRunning)
+	}
+}
+
+// changingClient alternates the homestatus responses of every home, so that concurrent collections change the state
+// kept by the collector.
+type changingClient struct {
+	fakeClient
+	statuses map[string][]*HomeStatusResponse
+
+	lock  sync.Mutex
+	calls map[string]int
+}
+
+func (c *changingClient) HomeStatus(_ context.Context, homeID string, _ []string) (*HomeStatusResponse, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	statuses := c.statuses[homeID]
+	status := statuses[c.calls[homeID]%len(statuses)]
+	c.calls[homeID]++
+
+	return status, nil
+}
+
+func TestThermostatCollector_ConcurrentCollect(t *testing.T) {
+	status := func(homeID, mode string, boiler bool, setpoint string) *HomeStatusResponse {
+		return mustDecode[HomeStatusResponse](t, `{"body":{"home":{"id":"`+homeID+`","therm_mode":"`+mode+`",
+			"modules":[{"id":"relay-`+homeID+`","type":"NAPlug","boiler_status":`+strconv.FormatBool(boiler)+`}],
+			"rooms":[{"id":"room-`+homeID+`","name":"Room","therm_measured_temperature":19,"therm_setpoint_temperature":`+setpoint+`}]
+		}}}`)
+	}
+
+	homeIDs := []string{"first", "second", "third"}
+	client := &changingClient{
+		fakeClient: fakeClient{
+			homesData: mustDecode[HomesDataResponse](t, `{"body":{"homes":[
+				{"id":"first","name":"First"},
+				{"id":"second","name":"Second"},
+				{"id":"third","name":"Third"}
+			]}}`),
+		},
+		statuses: map[string][]*HomeStatusResponse{},
+		calls:    map[string]int{},
+	}
+	for _, homeID := range homeIDs {
+		client.statuses[homeID] = []*HomeStatusResponse{
+			status(homeID, "schedule", false, "20"),
+			status(homeID, "away", true, "21"),
+		}
+	}
+
+	c := NewThermostatCollector(logrus.New(), client,
+		WithHomeStatusConcurrency(3),
+		WithHomesDataInterval(time.Hour),
+		WithBoilerSampleInterval(time.Minute),
+		WithUnderheating(0.5, 0),
+	)
+
+	// The first collection requests homesdata, which the fake client does not protect against concurrent requests.
+	testutil.CollectAndCount(c)
+
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			testutil.CollectAndCount(c)
+		}()
+		go func() {
+			defer wg.Done()
+			testutil.CollectAndCount(c.Home("second"))
+		}()
+		go func() {
+			defer wg.Done()
+			c.sampleBoilers(context.Background())
+		}()
+	}
+	wg.Wait()
+
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(c)
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("can not gather metrics: %s", err)
+	}
+
+	requests := 0
+	for _, calls := range client.calls {
+		requests += calls
+	}
+
+	// The values tell whether the metric is a counter.
+	counters := map[string]bool{
+		"netatmo_setpoint_changes_total":      true,
+		"netatmo_home_mode_changes_total":     true,
+		"netatmo_boiler_cycles_total":         true,
+		"netatmo_room_underheating":           false,
+		"netatmo_thermostat_temperature":      false,
+		"netatmo_boiler_status_samples_total": true,
+	}
+	for _, family := range families {
+		counter, ok := counters[family.GetName()]
+		if !ok {
+			continue
+		}
+		delete(counters, family.GetName())
+
+		if got := len(family.GetMetric()); got != len(homeIDs) {
+			t.Errorf("got %d series of %s, want %d", got, family.GetName(), len(homeIDs))
+		}
+
+		for _, m := range family.GetMetric() {
+			value := m.GetGauge().GetValue()
+			if counter {
+				value = m.GetCounter().GetValue()
+			}
+
+			// Every collection and sample alternates the responses, so all counters have to grow, but not beyond
+			// the number of responses.
+			switch {
+			case counter && (value <= 0 || value > float64(requests)):
+				t.Errorf("got %s %v, want a value between 1 and the number of requests", family.GetName(), value)
+			case family.GetName() == "netatmo_room_underheating" && value != 1:
+				t.Errorf("got %s %v, want 1", family.GetName(), value)
+			}
+		}
+	}
+
+	for name := range counters {
+		t.Errorf("metric %s is missing", name)
+	}
+}
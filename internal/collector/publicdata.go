@@ -0,0 +1,170 @@
+package collector
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"slices"
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	publicLabels = []string{"station"}
+
+	publicStationsDesc = newDesc(
+		prefix+"public_stations",
+		"Number of public Netatmo weather stations in the configured area.",
+		nil,
+	)
+
+	publicTemperatureDesc = newMeasurementDesc(
+		prefix+"public_temperature_celsius",
+		"Temperature measured by a public Netatmo weather station in degrees Celsius.",
+		publicLabels,
+	)
+
+	publicPressureDesc = newMeasurementDesc(
+		prefix+"public_pressure_mb",
+		"Atmospheric pressure measured by a public Netatmo weather station in millibar.",
+		publicLabels,
+	)
+
+	publicRainDesc = newDesc(
+		prefix+"public_rain_24h_mm",
+		"Rain amount of the last 24 hours measured by a public Netatmo weather station in millimeters.",
+		publicLabels,
+	)
+)
+
+// BoundingBox describes a geographic area using its south-west and north-east corners.
+type BoundingBox struct {
+	LatSW, LonSW float64
+	LatNE, LonNE float64
+}
+
+// PublicDataCollector is a Prometheus collector for the data of public weather stations in an area.
+// The stations are identified by a hash of their ID, so that the data can not easily be connected to a person.
+type PublicDataCollector struct {
+	log    logrus.FieldLogger
+	client NetatmoClient
+	area   BoundingBox
+}
+
+// NewPublicDataCollector creates a new PublicDataCollector for the provided area.
+func NewPublicDataCollector(log logrus.FieldLogger, client NetatmoClient, area BoundingBox) *PublicDataCollector {
+	return &PublicDataCollector{
+		log:    log,
+		client: client,
+		area:   area,
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *PublicDataCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- publicStationsDesc
+	ch <- publicTemperatureDesc
+	ch <- publicPressureDesc
+	ch <- publicRainDesc
+}
+
+// Collect implements prometheus.Collector.
+func (c *PublicDataCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx := context.Background()
+
+	data, err := c.client.PublicData(ctx, c.area)
+	if err != nil {
+		logAPIError(c.log, err, "PublicDataCollector: error fetching publicdata")
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(publicStationsDesc, prometheus.GaugeValue, float64(len(data.Body)))
+
+	for _, station := range data.Body {
+		labels := []string{station.anonymousID()}
+
+		sendOptional(ch, publicTemperatureDesc, station.latest("temperature"), labels...)
+		sendOptional(ch, publicPressureDesc, station.latest("pressure"), labels...)
+		sendOptional(ch, publicRainDesc, station.rain24h(), labels...)
+	}
+}
+
+// PublicDataResponse contains the response of the getpublicdata endpoint.
+type PublicDataResponse struct {
+	Body []publicStation `json:"body"`
+}
+
+type publicStation struct {
+	ID       string                   `json:"_id"`
+	Measures map[string]publicMeasure `json:"measures"`
+}
+
+// publicMeasure contains the measurements of one module of a public station. Most modules report their values
+// in Results keyed by timestamp, with Types naming the values. The rain gauge uses separate fields instead.
+type publicMeasure struct {
+	Results map[string][]float64 `json:"res"`
+	Types   []string             `json:"type"`
+	Rain24h *float64             `json:"rain_24h"`
+}
+
+func (s publicStation) anonymousID() string {
+	hash := sha256.Sum256([]byte(s.ID))
+	return hex.EncodeToString(hash[:6])
+}
+
+// latest returns the most recent value of the measurement type from any module of the station.
+func (s publicStation) latest(measureType string) *float64 {
+	var (
+		latestTime int64
+		result     *float64
+	)
+	for _, measure := range s.Measures {
+		index := slices.Index(measure.Types, measureType)
+		if index < 0 {
+			continue
+		}
+
+		for timestamp, values := range measure.Results {
+			t, err := strconv.ParseInt(timestamp, 10, 64)
+			if err != nil || t < latestTime || index >= len(values) {
+				continue
+			}
+
+			latestTime = t
+			value := values[index]
+			result = &value
+		}
+	}
+
+	return result
+}
+
+func (s publicStation) rain24h() *float64 {
+	for _, measure := range s.Measures {
+		if measure.Rain24h != nil {
+			return measure.Rain24h
+		}
+	}
+
+	return nil
+}
+
+// PublicData implements NetatmoClient.
+func (c *httpNetatmoClient) PublicData(ctx context.Context, area BoundingBox) (*PublicDataResponse, error) {
+	query := url.Values{}
+	query.Set("lat_sw", strconv.FormatFloat(area.LatSW, 'f', -1, 64))
+	query.Set("lon_sw", strconv.FormatFloat(area.LonSW, 'f', -1, 64))
+	query.Set("lat_ne", strconv.FormatFloat(area.LatNE, 'f', -1, 64))
+	query.Set("lon_ne", strconv.FormatFloat(area.LonNE, 'f', -1, 64))
+	query.Set("filter", "true")
+
+	var result PublicDataResponse
+	if err := c.get(ctx, "getpublicdata", query, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
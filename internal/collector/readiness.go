@@ -0,0 +1,53 @@
+package collector
+
+import (
+	"sync/atomic"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var readyDesc = newDesc(
+	prefix+"ready",
+	"Set to 1 once data has been collected successfully from the Netatmo API, 0 before, for example until the exporter is authenticated.",
+	nil,
+)
+
+// Readiness records whether any collector has collected data successfully since the exporter was started. It is a
+// collector reporting this as netatmo_ready.
+type Readiness struct {
+	ready atomic.Bool
+}
+
+// NewReadiness creates a Readiness, which is not ready yet.
+func NewReadiness() *Readiness {
+	return &Readiness{}
+}
+
+// Ready returns true once data has been collected successfully.
+func (r *Readiness) Ready() bool {
+	return r.ready.Load()
+}
+
+// markReady records a successful collection. It does nothing if r is nil, so that collectors do not have to check
+// whether readiness is recorded.
+func (r *Readiness) markReady() {
+	if r == nil {
+		return
+	}
+
+	r.ready.Store(true)
+}
+
+// Describe implements prometheus.Collector.
+func (r *Readiness) Describe(ch chan<- *prometheus.Desc) {
+	ch <- readyDesc
+}
+
+// Collect implements prometheus.Collector.
+func (r *Readiness) Collect(ch chan<- prometheus.Metric) {
+	ready := 0.0
+	if r.Ready() {
+		ready = 1.0
+	}
+	ch <- prometheus.MustNewConstMetric(readyDesc, prometheus.GaugeValue, ready)
+}
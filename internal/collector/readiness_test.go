@@ -0,0 +1,42 @@
+package collector
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sirupsen/logrus"
+)
+
+func TestReadiness(t *testing.T) {
+	readiness := NewReadiness()
+	client := &fakeClient{
+		homesErr: errors.New("test error"),
+	}
+	c := NewThermostatCollector(logrus.New(), client, WithReadiness(readiness))
+
+	want := func(ready string) string {
+		return `# HELP netatmo_ready Set to 1 once data has been collected successfully from the Netatmo API, 0 before, for example until the exporter is authenticated.
+# TYPE netatmo_ready gauge
+netatmo_ready ` + ready + "\n"
+	}
+
+	testutil.CollectAndCount(c)
+	if err := testutil.CollectAndCompare(readiness, strings.NewReader(want("0"))); err != nil {
+		t.Errorf("metrics after failed collection differ: %s", err)
+	}
+
+	client.homesErr = nil
+	client.homesData = mustDecode[HomesDataResponse](t, `{"body":{"homes":[]}}`)
+	testutil.CollectAndCount(c)
+	if err := testutil.CollectAndCompare(readiness, strings.NewReader(want("1"))); err != nil {
+		t.Errorf("metrics after successful collection differ: %s", err)
+	}
+
+	client.homesErr = errors.New("test error")
+	testutil.CollectAndCount(c)
+	if err := testutil.CollectAndCompare(readiness, strings.NewReader(want("1"))); err != nil {
+		t.Errorf("metrics after later failed collection differ: %s", err)
+	}
+}
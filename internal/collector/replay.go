@@ -0,0 +1,134 @@
+package collector
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"golang.org/x/oauth2"
+)
+
+// replayBaseURL is the base URL of the requests served by replayTransport. The host is never resolved.
+const replayBaseURL = "http://replay/api/"
+
+// NewReplayClient creates a NetatmoClient, which serves responses recorded in dir instead of requesting the Netatmo
+// API. The responses are decoded like real responses, so that the collectors can be tested offline.
+//
+// Each response is read from a file named after the endpoint, for example "homesdata.json". Responses of homestatus
+// are read from "homestatus_<home_id>.json". A sequence of responses is recorded in subdirectories of dir, which are
+// replayed in the order of their names: the first request of an endpoint is served from the first subdirectory
+// containing its file, the second request from the next one and so on, starting again with the first one after the
+// last. Requests without a recorded response fail with a 404 status.
+func NewReplayClient(dir string, stats *APIStats) (NetatmoClient, error) {
+	steps, err := replaySteps(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return &httpNetatmoClient{
+		baseURL: replayBaseURL,
+		tokenFunc: func() (*oauth2.Token, error) {
+			return &oauth2.Token{AccessToken: "replay"}, nil
+		},
+		stats: stats,
+		transport: &replayTransport{
+			steps:    steps,
+			requests: map[string]int{},
+		},
+	}, nil
+}
+
+// replaySteps returns the directories containing the recorded responses, which is dir itself if it has no
+// subdirectories.
+func replaySteps(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("error reading replay directory: %w", err)
+	}
+
+	var steps []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			steps = append(steps, filepath.Join(dir, entry.Name()))
+		}
+	}
+	if len(steps) == 0 {
+		return []string{dir}, nil
+	}
+	sort.Strings(steps)
+
+	return steps, nil
+}
+
+// replayTransport serves the recorded responses of NewReplayClient.
+type replayTransport struct {
+	steps []string
+
+	lock sync.Mutex
+	// requests contains the number of requests served for each file name.
+	requests map[string]int
+}
+
+func (t *replayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	fileName := path.Base(req.URL.Path)
+	if fileName == "homestatus" {
+		fileName += "_" + filepath.Base(req.URL.Query().Get("home_id"))
+	}
+	fileName += ".json"
+
+	data, ok, err := t.next(fileName)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return replayResponse(req, http.StatusNotFound, []byte(`{"error":{"code":404,"message":"no recorded response"}}`)), nil
+	}
+
+	return replayResponse(req, http.StatusOK, data), nil
+}
+
+// next reads the next recorded response for fileName. It returns false if no step contains the file.
+func (t *replayTransport) next(fileName string) ([]byte, bool, error) {
+	var recorded []string
+	for _, step := range t.steps {
+		name := filepath.Join(step, fileName)
+		if _, err := os.Stat(name); err == nil {
+			recorded = append(recorded, name)
+		}
+	}
+	if len(recorded) == 0 {
+		return nil, false, nil
+	}
+
+	t.lock.Lock()
+	request := t.requests[fileName]
+	t.requests[fileName]++
+	t.lock.Unlock()
+
+	data, err := os.ReadFile(recorded[request%len(recorded)])
+	if err != nil {
+		return nil, false, fmt.Errorf("error reading recorded response: %w", err)
+	}
+
+	return data, true, nil
+}
+
+func replayResponse(req *http.Request, statusCode int, body []byte) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", statusCode, http.StatusText(statusCode)),
+		StatusCode:    statusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
@@ -0,0 +1,88 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sirupsen/logrus"
+)
+
+func TestReplayClient_Fixture(t *testing.T) {
+	dir := filepath.Join("testdata", "multi-home")
+	client, err := NewReplayClient(dir, nil)
+	if err != nil {
+		t.Fatalf("error creating client: %s", err)
+	}
+
+	c := NewThermostatCollector(logrus.New(), client)
+	c.clock = func() time.Time {
+		return time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	}
+
+	want, err := os.Open(filepath.Join(dir, "metrics.prom"))
+	if err != nil {
+		t.Fatalf("error opening expected metrics: %s", err)
+	}
+	defer want.Close()
+
+	if err := testutil.CollectAndCompare(c, want); err != nil {
+		t.Errorf("metrics differ: %s", err)
+	}
+}
+
+func TestReplayClient_Sequence(t *testing.T) {
+	dir := t.TempDir()
+	writeFile := func(step, fileName, content string) {
+		t.Helper()
+
+		if err := os.MkdirAll(filepath.Join(dir, step), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, step, fileName), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile("01", "homesdata.json", `{"body":{"homes":[{"id":"home","name":"First"}]}}`)
+	writeFile("01", "homestatus_home.json", `{"body":{"home":{"id":"home","therm_mode":"schedule"}}}`)
+	writeFile("02", "homestatus_home.json", `{"body":{"home":{"id":"home","therm_mode":"away"}}}`)
+
+	client, err := NewReplayClient(dir, nil)
+	if err != nil {
+		t.Fatalf("error creating client: %s", err)
+	}
+
+	ctx := context.Background()
+	for _, want := range []string{"schedule", "away", "schedule"} {
+		status, err := client.HomeStatus(ctx, "home", nil)
+		if err != nil {
+			t.Fatalf("error getting home status: %s", err)
+		}
+
+		if got := status.Body.Home.ThermMode; got != want {
+			t.Errorf("got mode %q, want %q", got, want)
+		}
+	}
+
+	for i := 0; i < 2; i++ {
+		homes, err := client.HomesData(ctx)
+		if err != nil {
+			t.Fatalf("error getting homes data: %s", err)
+		}
+
+		if got := homes.Body.Homes[0].Name; got != "First" {
+			t.Errorf("got home %q, want %q", got, "First")
+		}
+	}
+
+	_, err = client.StationsData(ctx)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Errorf("got error %v, want error with status %d", err, http.StatusNotFound)
+	}
+}
@@ -0,0 +1,158 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	apiBaseURL = "https://api.netatmo.com/api/"
+
+	// snippetLength is the maximum number of bytes of a non-JSON response included in the debug log.
+	snippetLength = 200
+	// drainLength is the maximum number of bytes read from the rest of a response to reuse its connection. The
+	// connection of larger responses is closed instead.
+	drainLength = 64 << 10
+)
+
+// ErrNonJSONResponse is returned when the Netatmo API responds with something else than JSON, usually the HTML
+// error page of a proxy during an outage.
+var ErrNonJSONResponse = errors.New("NetAtmo returned non-JSON response, likely an outage")
+
+// getJSON executes a GET request against an endpoint of the Netatmo API at baseURL and decodes the JSON response
+// into result. All errors returned are of type *APIError.
+func getJSON(ctx context.Context, client *http.Client, baseURL, endpoint string, query url.Values, result any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+endpoint, nil)
+	if err != nil {
+		return &APIError{
+			Endpoint: endpoint,
+			Err:      fmt.Errorf("creating request: %w", err),
+		}
+	}
+	req.URL.RawQuery = query.Encode()
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return newTransportError(endpoint, fmt.Errorf("executing request: %w", err))
+	}
+	defer func() {
+		// The rest of the body needs to be read, so that the connection can be reused by the next request.
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, drainLength))
+		resp.Body.Close()
+	}()
+
+	contentType := resp.Header.Get("Content-Type")
+	if resp.StatusCode != http.StatusOK {
+		apiErr := newStatusError(endpoint, resp)
+		if !isJSONContentType(contentType) {
+			apiErr.Err = fmt.Errorf("%w: %w", apiErr.Err, ErrNonJSONResponse)
+			apiErr.detail = responseDetail(contentType, resp.Body)
+			return apiErr
+		}
+		addErrorBody(apiErr, resp.Body)
+		return apiErr
+	}
+
+	if !isJSONContentType(contentType) {
+		return &APIError{
+			Endpoint:  endpoint,
+			Err:       ErrNonJSONResponse,
+			transient: true,
+			detail:    responseDetail(contentType, resp.Body),
+		}
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return newDecodeError(endpoint, err)
+	}
+
+	if body, ok := result.(errorBody); ok {
+		if respErr := body.responseError(); respErr != nil {
+			return newResponseError(endpoint, respErr)
+		}
+	}
+
+	return nil
+}
+
+// newDecodeError creates the error for a JSON response which could not be decoded. A response which ended early,
+// for example because the connection was closed, is transient. Any other response which does not match the expected
+// format is permanent, because retrying it returns the same response.
+func newDecodeError(endpoint string, err error) *APIError {
+	apiErr := &APIError{
+		Endpoint: endpoint,
+		Err:      fmt.Errorf("decoding response: %w", err),
+	}
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		apiErr.transient = true
+		return apiErr
+	}
+	apiErr.hint = "response does not have the format expected by the exporter, the API might have changed"
+
+	return apiErr
+}
+
+// errorBody is implemented by the responses which can contain an error instead of their data.
+type errorBody interface {
+	responseError() *ResponseError
+}
+
+// addErrorBody adds the body of a failed JSON response to the status error apiErr. The error object contained in
+// most of these bodies describes the failure more precisely than the status code, so its code takes precedence when
+// classifying the error. Other bodies are included in the error message, truncated to snippetLength bytes.
+func addErrorBody(apiErr *APIError, body io.Reader) {
+	data, _ := io.ReadAll(io.LimitReader(body, drainLength))
+
+	var result struct {
+		Error *ResponseError `json:"error"`
+	}
+	if err := json.Unmarshal(data, &result); err == nil && result.Error != nil {
+		respErr := newResponseError(apiErr.Endpoint, result.Error)
+		apiErr.Err = fmt.Errorf("%w: %w", apiErr.Err, result.Error)
+		apiErr.transient = apiErr.transient || respErr.transient
+		apiErr.hint = respErr.hint
+		if apiErr.transient {
+			apiErr.hint = ""
+		}
+		return
+	}
+
+	snippet := strings.TrimSpace(string(data[:min(len(data), snippetLength)]))
+	if snippet != "" {
+		apiErr.Err = fmt.Errorf("%w: body %q", apiErr.Err, snippet)
+	}
+}
+
+// responseDetail describes a non-JSON response for the debug log using its content type and the start of the body.
+func responseDetail(contentType string, body io.Reader) string {
+	snippet, _ := io.ReadAll(io.LimitReader(body, snippetLength))
+	return fmt.Sprintf("content type %q, body %q", contentType, strings.TrimSpace(string(snippet)))
+}
+
+// isJSONContentType returns true, if the content type of a response can contain JSON. Responses without a content
+// type and plain text are accepted as well, because the API does not always set the content type.
+func isJSONContentType(contentType string) bool {
+	if contentType == "" {
+		return true
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	switch {
+	case mediaType == "application/json", mediaType == "text/json", mediaType == "text/plain",
+		strings.HasSuffix(mediaType, "+json"):
+		return true
+	default:
+		return false
+	}
+}
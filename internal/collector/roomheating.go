@@ -0,0 +1,77 @@
+package collector
+
+import (
+	"sync"
+	"time"
+)
+
+// maxRoomHeatingGap is the longest time between two observations of a room, which is still counted as heating time.
+// Longer gaps, for example caused by failing requests, are skipped instead of guessing the state in between.
+const maxRoomHeatingGap = 30 * time.Minute
+
+// roomHeatingState contains the estimated heating time of each room on the current day.
+type roomHeatingState struct {
+	sync.Mutex
+	rooms map[string]*roomHeating
+}
+
+// roomHeating contains the last observation of a room and the heating time counted on its day.
+type roomHeating struct {
+	day     string
+	last    time.Time
+	heating bool
+	seconds float64
+}
+
+// observeRoomHeating records whether the valves of a room request heating power at now, which needs to be in the
+// location of the home, and returns the estimated heating time since local midnight. The time between two
+// observations is counted as heating time if the room was heating at the earlier one. Observations are made by the
+// scrapes and the boiler sampler, so the estimate is only as accurate as their interval. The time is reset at local
+// midnight and is not known for the time before the first observation.
+func (c *ThermostatCollector) observeRoomHeating(roomID string, heating bool, now time.Time) float64 {
+	c.roomHeatingState.Lock()
+	defer c.roomHeatingState.Unlock()
+
+	day := now.Format(time.DateOnly)
+	state, ok := c.roomHeatingState.rooms[roomID]
+	if !ok {
+		state = &roomHeating{day: day, last: now}
+		c.roomHeatingState.rooms[roomID] = state
+	}
+
+	if !now.Before(state.last) {
+		from := state.last
+		if state.day != day {
+			midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+			if from.Before(midnight) {
+				from = midnight
+			}
+			state.day = day
+			state.seconds = 0
+		}
+
+		if state.heating && now.Sub(state.last) <= maxRoomHeatingGap {
+			state.seconds += now.Sub(from).Seconds()
+		}
+		state.last = now
+	}
+	state.heating = heating
+
+	return state.seconds
+}
+
+// observeRoomsHeating records the heating power requests of all rooms in a home status.
+func (c *ThermostatCollector) observeRoomsHeating(home homeData, rooms []roomStatus) {
+	now := c.clock().In(homeLocation(home.Timezone))
+	roomIDs := roomIDsByName(home.Rooms)
+	for _, room := range rooms {
+		if room.ID == "" {
+			room.ID = roomIDs[room.Name]
+		}
+		if room.ID == "" || room.HeatingPowerRequest == nil {
+			continue
+		}
+
+		c.observeRoomHeating(room.ID, *room.HeatingPowerRequest > 0, now)
+	}
+}
@@ -0,0 +1,73 @@
+package collector
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestObserveRoomHeating(t *testing.T) {
+	location, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2024, 1, 1, 22, 0, 0, 0, location)
+
+	type observation struct {
+		offset  time.Duration
+		heating bool
+		want    float64
+	}
+
+	tt := []struct {
+		desc         string
+		observations []observation
+	}{
+		{
+			desc: "first observation",
+			observations: []observation{
+				{offset: 0, heating: true, want: 0},
+			},
+		},
+		{
+			desc: "heating between observations",
+			observations: []observation{
+				{offset: 0, heating: true, want: 0},
+				{offset: 5 * time.Minute, heating: false, want: 300},
+				{offset: 10 * time.Minute, heating: true, want: 300},
+				{offset: 15 * time.Minute, heating: true, want: 600},
+			},
+		},
+		{
+			desc: "gap is skipped",
+			observations: []observation{
+				{offset: 0, heating: true, want: 0},
+				{offset: time.Hour, heating: true, want: 0},
+				{offset: time.Hour + 10*time.Minute, heating: true, want: 600},
+			},
+		},
+		{
+			desc: "reset at local midnight",
+			observations: []observation{
+				{offset: time.Hour + 50*time.Minute, heating: true, want: 0},
+				{offset: 2*time.Hour + 10*time.Minute, heating: true, want: 600},
+				{offset: 2*time.Hour + 20*time.Minute, heating: false, want: 1200},
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			c := NewThermostatCollector(logrus.New(), &fakeClient{}, WithRoomHeatingTime(true))
+			for i, o := range tc.observations {
+				if got := c.observeRoomHeating("room", o.heating, start.Add(o.offset)); got != o.want {
+					t.Errorf("observation %d: got %v seconds, want %v", i, got, o.want)
+				}
+			}
+		})
+	}
+}
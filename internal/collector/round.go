@@ -0,0 +1,65 @@
+package collector
+
+import (
+	"math"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// Round wraps a collector, so that the values of the gauges of measured values, like temperatures, are rounded to the
+// provided number of decimal places. Timestamps, ratios, info metrics and metrics of other packages are not changed.
+// A negative number of digits disables rounding.
+func Round(c prometheus.Collector, digits int) prometheus.Collector {
+	if digits < 0 {
+		return c
+	}
+
+	return &roundedCollector{
+		Collector: c,
+		factor:    math.Pow10(digits),
+	}
+}
+
+type roundedCollector struct {
+	prometheus.Collector
+	factor float64
+}
+
+func (c *roundedCollector) Collect(ch chan<- prometheus.Metric) {
+	metrics := make(chan prometheus.Metric)
+	go func() {
+		c.Collector.Collect(metrics)
+		close(metrics)
+	}()
+
+	for m := range metrics {
+		if def, ok := definition(m.Desc()); !ok || !def.measurement {
+			ch <- m
+			continue
+		}
+
+		ch <- roundedMetric{
+			Metric: m,
+			factor: c.factor,
+		}
+	}
+}
+
+type roundedMetric struct {
+	prometheus.Metric
+	factor float64
+}
+
+func (m roundedMetric) Write(out *dto.Metric) error {
+	if err := m.Metric.Write(out); err != nil {
+		return err
+	}
+
+	if out.Gauge != nil && out.Gauge.Value != nil {
+		value := math.Round(*out.Gauge.Value*m.factor) / m.factor
+		out.Gauge.Value = &value
+	}
+
+	return nil
+}
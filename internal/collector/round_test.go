@@ -0,0 +1,31 @@
+package collector
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestRound(t *testing.T) {
+	c := Round(staticCollector{
+		prometheus.MustNewConstMetric(testDescs.thermostatTemperature, prometheus.GaugeValue, 21.0438, "home", "Home", "living", "Living"),
+		prometheus.MustNewConstMetric(testDescs.homeLastSuccess, prometheus.GaugeValue, 1700000000.567, "home", "Home"),
+		prometheus.MustNewConstMetric(testDescs.homeDataCompleteness, prometheus.GaugeValue, 0.6667, "home", "Home"),
+	}, 1)
+
+	wantMetrics := `# HELP netatmo_home_data_completeness Netatmo Energy fraction of the rooms of a home in homestatus which reported a measured temperature.
+# TYPE netatmo_home_data_completeness gauge
+netatmo_home_data_completeness{home_id="home",home_name="Home"} 0.6667
+# HELP netatmo_thermostat_last_success_timestamp_seconds Netatmo Energy unix timestamp of the last successful homestatus request of a home. It is kept while the requests of the home fail.
+# TYPE netatmo_thermostat_last_success_timestamp_seconds gauge
+netatmo_thermostat_last_success_timestamp_seconds{home_id="home",home_name="Home"} 1.700000000567e+09
+# HELP netatmo_thermostat_temperature Netatmo Energy measured room temperature in degrees Celsius.
+# TYPE netatmo_thermostat_temperature gauge
+netatmo_thermostat_temperature{home_id="home",home_name="Home",room_id="living",room_name="Living"} 21
+`
+	if err := testutil.CollectAndCompare(c, strings.NewReader(wantMetrics)); err != nil {
+		t.Error(err)
+	}
+}
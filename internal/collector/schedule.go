@@ -0,0 +1,263 @@
+package collector
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	minutesPerDay  = 24 * 60
+	minutesPerWeek = 7 * minutesPerDay
+
+	// zoneTypeComfort is the type of the comfort zone, which is created for every schedule.
+	zoneTypeComfort = 0
+)
+
+// schedule contains a weekly schedule as returned by homesdata.
+type schedule struct {
+	ID        string           `json:"id"`
+	Name      string           `json:"name"`
+	Type      string           `json:"type"`
+	Selected  bool             `json:"selected"`
+	Timetable []timetableEntry `json:"timetable"`
+	Zones     []scheduleZone   `json:"zones"`
+}
+
+// timetableEntry activates a zone starting at Offset minutes after Monday 00:00 in the timezone of the home.
+type timetableEntry struct {
+	ZoneID int `json:"zone_id"`
+	Offset int `json:"m_offset"`
+}
+
+type scheduleZone struct {
+	ID    int        `json:"id"`
+	Name  string     `json:"name"`
+	Type  int        `json:"type"`
+	Rooms []zoneRoom `json:"rooms"`
+	// RoomsTemp contains the same information as Rooms in an older format.
+	RoomsTemp []struct {
+		RoomID      string   `json:"room_id"`
+		Temperature *float64 `json:"temp"`
+	} `json:"rooms_temp"`
+}
+
+type zoneRoom struct {
+	ID                  string   `json:"id"`
+	SetpointTemperature *float64 `json:"therm_setpoint_temperature"`
+}
+
+// activeSchedule returns the selected heating schedule of a home or nil if there is none.
+func activeSchedule(schedules []schedule) *schedule {
+	for i, s := range schedules {
+		if s.Selected && (s.Type == "" || s.Type == "therm") {
+			return &schedules[i]
+		}
+	}
+
+	return nil
+}
+
+func (s *schedule) zone(id int) *scheduleZone {
+	for i, z := range s.Zones {
+		if z.ID == id {
+			return &s.Zones[i]
+		}
+	}
+
+	return nil
+}
+
+// entryAt returns the index of the timetable entry active at the minute of the week. The timetable is cyclic,
+// so before the first entry of the week the last entry is still active.
+func (s *schedule) entryAt(minute int) int {
+	active := len(s.Timetable) - 1
+	for i, entry := range s.Timetable {
+		if entry.Offset > minute {
+			break
+		}
+		active = i
+	}
+
+	return active
+}
+
+// activeZone returns the zone active at the time t or nil if the schedule has no timetable.
+func (s *schedule) activeZone(t time.Time) *scheduleZone {
+	if len(s.Timetable) == 0 {
+		return nil
+	}
+
+	return s.zone(s.Timetable[s.entryAt(minuteOfWeek(t))].ZoneID)
+}
+
+// comfortZone returns the comfort zone of the schedule or nil if there is none.
+func (s *schedule) comfortZone() *scheduleZone {
+	for i, z := range s.Zones {
+		if z.Type == zoneTypeComfort {
+			return &s.Zones[i]
+		}
+	}
+
+	return nil
+}
+
+// overriddenAboveComfort returns true if the setpoint of the room has been overridden manually or by the max mode
+// to a temperature above the comfort temperature of the room in the schedule. Rooms without a comfort temperature
+// are never counted.
+func (s *schedule) overriddenAboveComfort(room roomStatus) bool {
+	if room.SetpointTemperature == nil || (room.SetpointMode != "manual" && room.SetpointMode != "max") {
+		return false
+	}
+
+	comfort := s.comfortZone().roomSetpoint(room.ID)
+	return comfort != nil && *room.SetpointTemperature > *comfort
+}
+
+// roomSetpoint returns the temperature the zone sets for the room, nil if the zone does not contain the room.
+func (z *scheduleZone) roomSetpoint(roomID string) *float64 {
+	if z == nil {
+		return nil
+	}
+
+	for _, r := range z.Rooms {
+		if r.ID == roomID {
+			return r.SetpointTemperature
+		}
+	}
+
+	for _, r := range z.RoomsTemp {
+		if r.RoomID == roomID {
+			return r.Temperature
+		}
+	}
+
+	return nil
+}
+
+type timeslot struct {
+	Offset   int
+	Setpoint float64
+}
+
+// timeslotsForDay returns the setpoints of the room starting on the day of t. The first timeslot always starts at
+// midnight, even if the timetable entry started on the previous day.
+func (s *schedule) timeslotsForDay(roomID string, t time.Time) []timeslot {
+	if len(s.Timetable) == 0 {
+		return nil
+	}
+
+	dayStart := minuteOfWeek(t) / minutesPerDay * minutesPerDay
+	dayEnd := dayStart + minutesPerDay
+
+	var result []timeslot
+	first := s.entryAt(dayStart)
+	for i := 0; i < len(s.Timetable); i++ {
+		entry := s.Timetable[(first+i)%len(s.Timetable)]
+		offset := entry.Offset
+		if i == 0 {
+			offset = dayStart
+		} else if offset < dayStart || offset >= dayEnd {
+			break
+		}
+
+		setpoint := s.zone(entry.ZoneID).roomSetpoint(roomID)
+		if setpoint == nil {
+			continue
+		}
+
+		result = append(result, timeslot{
+			Offset:   offset,
+			Setpoint: *setpoint,
+		})
+	}
+
+	return result
+}
+
+// nextChange returns the time when the setpoint of the room changes next according to the schedule. It returns
+// the zero time if the setpoint does not change during the whole week.
+func (s *schedule) nextChange(roomID string, t time.Time) time.Time {
+	if len(s.Timetable) == 0 {
+		return time.Time{}
+	}
+
+	now := minuteOfWeek(t)
+	current := s.entryAt(now)
+	currentSetpoint := s.zone(s.Timetable[current].ZoneID).roomSetpoint(roomID)
+
+	for i := 1; i <= len(s.Timetable); i++ {
+		entry := s.Timetable[(current+i)%len(s.Timetable)]
+		setpoint := s.zone(entry.ZoneID).roomSetpoint(roomID)
+		if equalSetpoint(setpoint, currentSetpoint) {
+			continue
+		}
+
+		delta := (entry.Offset - now + minutesPerWeek) % minutesPerWeek
+		return t.Truncate(time.Minute).Add(time.Duration(delta) * time.Minute)
+	}
+
+	return time.Time{}
+}
+
+func equalSetpoint(a, b *float64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return *a == *b
+}
+
+// minuteOfWeek returns the minutes since Monday 00:00 in the location of t.
+func minuteOfWeek(t time.Time) int {
+	weekday := (int(t.Weekday()) + 6) % 7
+	return weekday*minutesPerDay + t.Hour()*60 + t.Minute()
+}
+
+// homeLocation returns the location for the timezone of a home, falling back to UTC for unknown timezones.
+func homeLocation(timezone string) *time.Location {
+	if timezone == "" {
+		return time.UTC
+	}
+
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return time.UTC
+	}
+
+	return location
+}
+
+func collectSchedule(ch chan<- prometheus.Metric, descs *thermostatDescs, s *schedule, now time.Time, labels []string, roomID string) {
+	if zone := s.activeZone(now); zone != nil && zone.roomSetpoint(roomID) != nil {
+		ch <- prometheus.MustNewConstMetric(
+			descs.roomActiveZone,
+			prometheus.GaugeValue,
+			1,
+			append(labels[:len(labels):len(labels)], strconv.Itoa(zone.ID), zone.Name, strconv.Itoa(zone.Type))...,
+		)
+	}
+
+	if setpoint := s.comfortZone().roomSetpoint(roomID); setpoint != nil {
+		ch <- prometheus.MustNewConstMetric(descs.roomComfortSetpoint, prometheus.GaugeValue, *setpoint, labels...)
+	}
+
+	for _, slot := range s.timeslotsForDay(roomID, now) {
+		ch <- prometheus.MustNewConstMetric(
+			descs.scheduleTimeslotSetpoint,
+			prometheus.GaugeValue,
+			slot.Setpoint,
+			append(labels[:len(labels):len(labels)], strconv.Itoa(slot.Offset))...,
+		)
+	}
+
+	if next := s.nextChange(roomID, now); !next.IsZero() {
+		ch <- prometheus.MustNewConstMetric(
+			descs.nextSetpointChange,
+			prometheus.GaugeValue,
+			float64(next.Unix()),
+			labels...,
+		)
+	}
+}
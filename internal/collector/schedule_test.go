@@ -0,0 +1,281 @@
+package collector
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sirupsen/logrus"
+)
+
+func testSchedule() *schedule {
+	zone := func(id int, temp float64) scheduleZone {
+		return scheduleZone{
+			ID: id,
+			Rooms: []zoneRoom{
+				{ID: "room", SetpointTemperature: &temp},
+			},
+		}
+	}
+
+	return &schedule{
+		Selected: true,
+		Timetable: []timetableEntry{
+			{ZoneID: 1, Offset: 0},
+			{ZoneID: 0, Offset: 6 * 60},
+			{ZoneID: 1, Offset: 22 * 60},
+			{ZoneID: 0, Offset: minutesPerDay + 6*60},
+			{ZoneID: 2, Offset: minutesPerDay + 8*60},
+			{ZoneID: 1, Offset: minutesPerDay + 22*60},
+		},
+		Zones: []scheduleZone{
+			zone(0, 20),
+			zone(1, 17),
+			zone(2, 17),
+		},
+	}
+}
+
+func TestScheduleTimeslotsForDay(t *testing.T) {
+	tt := []struct {
+		desc      string
+		time      time.Time
+		wantSlots []timeslot
+	}{
+		{
+			desc: "monday",
+			time: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+			wantSlots: []timeslot{
+				{Offset: 0, Setpoint: 17},
+				{Offset: 6 * 60, Setpoint: 20},
+				{Offset: 22 * 60, Setpoint: 17},
+			},
+		},
+		{
+			desc: "tuesday",
+			time: time.Date(2024, 1, 2, 1, 0, 0, 0, time.UTC),
+			wantSlots: []timeslot{
+				{Offset: minutesPerDay, Setpoint: 17},
+				{Offset: minutesPerDay + 6*60, Setpoint: 20},
+				{Offset: minutesPerDay + 8*60, Setpoint: 17},
+				{Offset: minutesPerDay + 22*60, Setpoint: 17},
+			},
+		},
+		{
+			desc: "sunday continues last entry",
+			time: time.Date(2024, 1, 7, 12, 0, 0, 0, time.UTC),
+			wantSlots: []timeslot{
+				{Offset: 6 * minutesPerDay, Setpoint: 17},
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			got := testSchedule().timeslotsForDay("room", tc.time)
+			if diff := cmp.Diff(got, tc.wantSlots); diff != "" {
+				t.Errorf("timeslots differ: -got+want\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestScheduleNextChange(t *testing.T) {
+	tt := []struct {
+		desc     string
+		time     time.Time
+		roomID   string
+		wantTime time.Time
+	}{
+		{
+			desc:     "same day",
+			time:     time.Date(2024, 1, 1, 12, 30, 15, 0, time.UTC),
+			roomID:   "room",
+			wantTime: time.Date(2024, 1, 1, 22, 0, 0, 0, time.UTC),
+		},
+		{
+			desc:     "next zone",
+			time:     time.Date(2024, 1, 2, 7, 0, 0, 0, time.UTC),
+			roomID:   "room",
+			wantTime: time.Date(2024, 1, 2, 8, 0, 0, 0, time.UTC),
+		},
+		{
+			desc:     "skip zones with same setpoint",
+			time:     time.Date(2024, 1, 2, 9, 0, 0, 0, time.UTC),
+			roomID:   "room",
+			wantTime: time.Date(2024, 1, 8, 6, 0, 0, 0, time.UTC),
+		},
+		{
+			desc:     "wrap around week",
+			time:     time.Date(2024, 1, 3, 12, 0, 0, 0, time.UTC),
+			roomID:   "room",
+			wantTime: time.Date(2024, 1, 8, 6, 0, 0, 0, time.UTC),
+		},
+		{
+			desc:     "unknown room",
+			time:     time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+			roomID:   "other",
+			wantTime: time.Time{},
+		},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			got := testSchedule().nextChange(tc.roomID, tc.time)
+			if !got.Equal(tc.wantTime) {
+				t.Errorf("got time %s, want %s", got, tc.wantTime)
+			}
+		})
+	}
+}
+
+func TestScheduleActiveZone(t *testing.T) {
+	tt := []struct {
+		desc       string
+		time       time.Time
+		wantZoneID int
+	}{
+		{
+			desc:       "day",
+			time:       time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+			wantZoneID: 0,
+		},
+		{
+			desc:       "night",
+			time:       time.Date(2024, 1, 1, 23, 0, 0, 0, time.UTC),
+			wantZoneID: 1,
+		},
+		{
+			desc:       "other zone",
+			time:       time.Date(2024, 1, 2, 9, 0, 0, 0, time.UTC),
+			wantZoneID: 2,
+		},
+		{
+			desc:       "last entry of week",
+			time:       time.Date(2024, 1, 7, 12, 0, 0, 0, time.UTC),
+			wantZoneID: 1,
+		},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			got := testSchedule().activeZone(tc.time)
+			if got == nil {
+				t.Fatal("got no zone")
+			}
+
+			if got.ID != tc.wantZoneID {
+				t.Errorf("got zone %d, want %d", got.ID, tc.wantZoneID)
+			}
+		})
+	}
+}
+
+func TestScheduleComfortZone(t *testing.T) {
+	comfort, night := 20.5, 16.0
+	s := &schedule{
+		Zones: []scheduleZone{
+			{ID: 1, Type: 1, Rooms: []zoneRoom{{ID: "room", SetpointTemperature: &night}}},
+			{ID: 0, Type: zoneTypeComfort, Rooms: []zoneRoom{{ID: "room", SetpointTemperature: &comfort}}},
+		},
+	}
+
+	got := s.comfortZone().roomSetpoint("room")
+	if got == nil || *got != comfort {
+		t.Errorf("got comfort setpoint %v, want %f", got, comfort)
+	}
+
+	if got := s.comfortZone().roomSetpoint("other"); got != nil {
+		t.Errorf("got comfort setpoint %f for unknown room, want nil", *got)
+	}
+
+	if got := (&schedule{}).comfortZone(); got != nil {
+		t.Errorf("got comfort zone %v for schedule without zones, want nil", got)
+	}
+}
+
+func TestScheduleOverriddenAboveComfort(t *testing.T) {
+	temp := func(v float64) *float64 {
+		return &v
+	}
+
+	tt := []struct {
+		desc string
+		room roomStatus
+		want bool
+	}{
+		{
+			desc: "manual above comfort",
+			room: roomStatus{ID: "room", SetpointTemperature: temp(22), SetpointMode: "manual"},
+			want: true,
+		},
+		{
+			desc: "max mode above comfort",
+			room: roomStatus{ID: "room", SetpointTemperature: temp(30), SetpointMode: "max"},
+			want: true,
+		},
+		{
+			desc: "manual at comfort",
+			room: roomStatus{ID: "room", SetpointTemperature: temp(20), SetpointMode: "manual"},
+		},
+		{
+			desc: "schedule above comfort",
+			room: roomStatus{ID: "room", SetpointTemperature: temp(22), SetpointMode: "schedule"},
+		},
+		{
+			desc: "room without comfort temperature",
+			room: roomStatus{ID: "other", SetpointTemperature: temp(22), SetpointMode: "manual"},
+		},
+		{
+			desc: "no setpoint",
+			room: roomStatus{ID: "room", SetpointMode: "manual"},
+		},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			if got := testSchedule().overriddenAboveComfort(tc.room); got != tc.want {
+				t.Errorf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestThermostatCollector_HomeSchedules(t *testing.T) {
+	client := &fakeClient{
+		homesData: mustDecode[HomesDataResponse](t, `{"body":{"homes":[{"id":"home","name":"Home","schedules":[
+			{"id":"winter","name":"Winter","type":"therm","selected":true},
+			{"id":"away","name":"Holidays","type":"therm"},
+			{"id":"cooling","name":"Summer","type":"cooling","selected":true}
+		]}]}}`),
+		homeStatus: map[string]*HomeStatusResponse{
+			"home": mustDecode[HomeStatusResponse](t, `{"body":{"home":{"id":"home"}}}`),
+		},
+	}
+	c := NewThermostatCollector(logrus.New(), client)
+
+	want := `# HELP netatmo_home_active_schedule_info Netatmo Energy active heating schedule of a home. The value is always 1.
+# TYPE netatmo_home_active_schedule_info gauge
+netatmo_home_active_schedule_info{home_id="home",home_name="Home",schedule_id="winter",schedule_name="Winter"} 1
+# HELP netatmo_home_schedules_total Netatmo Energy number of schedules of a home in homesdata, including schedules of other types than heating.
+# TYPE netatmo_home_schedules_total gauge
+netatmo_home_schedules_total{home_id="home",home_name="Home"} 3
+`
+	if err := testutil.CollectAndCompare(c, strings.NewReader(want), "netatmo_home_active_schedule_info", "netatmo_home_schedules_total"); err != nil {
+		t.Errorf("metrics differ: %s", err)
+	}
+}
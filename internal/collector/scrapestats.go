@@ -0,0 +1,36 @@
+package collector
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	phaseHomesData  = "homesdata"
+	phaseHomeStatus = "homestatus"
+)
+
+// scrapeErrorState counts the failed requests of the collections by phase.
+type scrapeErrorState struct {
+	sync.Mutex
+	errors map[string]float64
+}
+
+func (s *scrapeErrorState) add(phase string) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.errors[phase]++
+}
+
+// collect reports the errors of all phases, including the phases without errors, so that their rate can be
+// calculated from the start.
+func (s *scrapeErrorState) collect(ch chan<- prometheus.Metric, desc *prometheus.Desc) {
+	s.Lock()
+	defer s.Unlock()
+
+	for _, phase := range []string{phaseHomesData, phaseHomeStatus} {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.CounterValue, s.errors[phase], phase)
+	}
+}
@@ -0,0 +1,38 @@
+package collector
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sirupsen/logrus"
+)
+
+func TestThermostatCollector_ScrapeErrors(t *testing.T) {
+	client := &fakeClient{
+		homesErr: &APIError{Endpoint: "homesdata", Err: errors.New("connection reset"), transient: true},
+	}
+	c := NewThermostatCollector(logrus.New(), client)
+
+	want := func(homesData, homeStatus string) string {
+		return `# HELP netatmo_scrape_errors_total Netatmo Energy number of failed requests during the collection of the thermostat metrics by phase of the collection.
+# TYPE netatmo_scrape_errors_total counter
+netatmo_scrape_errors_total{phase="homesdata"} ` + homesData + `
+netatmo_scrape_errors_total{phase="homestatus"} ` + homeStatus + "\n"
+	}
+	metrics := []string{"netatmo_scrape_errors_total"}
+
+	if err := testutil.CollectAndCompare(c, strings.NewReader(want("1", "0")), metrics...); err != nil {
+		t.Errorf("metrics after homesdata error differ: %s", err)
+	}
+
+	client.homesErr = nil
+	client.homesData = mustDecode[HomesDataResponse](t, `{"body":{"homes":[{"id":"home","name":"Home"},{"id":"broken","name":"Broken"}]}}`)
+	client.homeStatus = map[string]*HomeStatusResponse{
+		"home": mustDecode[HomeStatusResponse](t, `{"body":{"home":{"id":"home"}}}`),
+	}
+	if err := testutil.CollectAndCompare(c, strings.NewReader(want("1", "1")), metrics...); err != nil {
+		t.Errorf("metrics after homestatus error differ: %s", err)
+	}
+}
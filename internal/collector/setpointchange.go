@@ -0,0 +1,35 @@
+package collector
+
+import (
+	"sync"
+	"time"
+)
+
+// setpointChangeState contains the last setpoint of each room and the time of the last setpoint change of each home.
+type setpointChangeState struct {
+	sync.Mutex
+	rooms map[string]float64
+	homes map[string]time.Time
+}
+
+// lastSetpointChange records the current setpoints of the rooms of a home and returns the time of the last
+// change. Rooms which are seen for the first time do not count as a change.
+func (c *ThermostatCollector) lastSetpointChange(homeID string, setpoints map[string]float64, now time.Time) time.Time {
+	c.setpointChangeState.Lock()
+	defer c.setpointChangeState.Unlock()
+
+	changed := false
+	for roomID, setpoint := range setpoints {
+		last, ok := c.setpointChangeState.rooms[roomID]
+		if ok && last != setpoint {
+			changed = true
+		}
+		c.setpointChangeState.rooms[roomID] = setpoint
+	}
+
+	if _, ok := c.setpointChangeState.homes[homeID]; !ok || changed {
+		c.setpointChangeState.homes[homeID] = now
+	}
+
+	return c.setpointChangeState.homes[homeID]
+}
@@ -0,0 +1,64 @@
+package collector
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestThermostatCollector_LastSetpointChange(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	c := NewThermostatCollector(logrus.New(), nil)
+
+	tt := []struct {
+		desc      string
+		advance   time.Duration
+		setpoints map[string]float64
+		want      time.Time
+	}{
+		{
+			desc:      "first collection",
+			setpoints: map[string]float64{"living": 20, "bedroom": 18},
+			want:      start,
+		},
+		{
+			desc:      "unchanged",
+			advance:   time.Hour,
+			setpoints: map[string]float64{"living": 20, "bedroom": 18},
+			want:      start,
+		},
+		{
+			desc:      "new room",
+			advance:   time.Hour,
+			setpoints: map[string]float64{"living": 20, "bedroom": 18, "hall": 16},
+			want:      start,
+		},
+		{
+			desc:      "changed",
+			advance:   time.Hour,
+			setpoints: map[string]float64{"living": 21, "bedroom": 18, "hall": 16},
+			want:      start.Add(3 * time.Hour),
+		},
+		{
+			desc:      "room without setpoint",
+			advance:   time.Hour,
+			setpoints: map[string]float64{"living": 21},
+			want:      start.Add(3 * time.Hour),
+		},
+	}
+
+	now := start
+	for _, tc := range tt {
+		now = now.Add(tc.advance)
+
+		got := c.lastSetpointChange("home", tc.setpoints, now)
+		if !got.Equal(tc.want) {
+			t.Errorf("%s: got %s, want %s", tc.desc, got, tc.want)
+		}
+	}
+
+	if got := c.lastSetpointChange("other", map[string]float64{}, now); !got.Equal(now) {
+		t.Errorf("got %s for other home, want %s", got, now)
+	}
+}
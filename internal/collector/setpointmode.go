@@ -0,0 +1,29 @@
+package collector
+
+import (
+	"slices"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	// setpointModes contains the setpoint modes of rooms documented by Netatmo, which are always reported.
+	setpointModes = []string{"away", "hg", "home", "manual", "max", "off", "schedule"}
+)
+
+// sendSetpointMode sends one series per setpoint mode, of which only the series of current is set to 1. A mode
+// unknown to the exporter is reported in an additional series, so that it is not lost.
+func sendSetpointMode(ch chan<- prometheus.Metric, desc *prometheus.Desc, current string, labels []string) {
+	labels = labels[:len(labels):len(labels)]
+	for _, mode := range setpointModes {
+		value := 0.0
+		if mode == current {
+			value = 1.0
+		}
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, value, append(labels, mode)...)
+	}
+
+	if !slices.Contains(setpointModes, current) {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, 1, append(labels, current)...)
+	}
+}
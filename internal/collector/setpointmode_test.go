@@ -0,0 +1,45 @@
+package collector
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sirupsen/logrus"
+)
+
+func TestThermostatCollector_SetpointMode(t *testing.T) {
+	client := &fakeClient{
+		homesData: mustDecode[HomesDataResponse](t, `{"body":{"homes":[{"id":"home","name":"Home"}]}}`),
+		homeStatus: map[string]*HomeStatusResponse{
+			"home": mustDecode[HomeStatusResponse](t, `{"body":{"home":{"id":"home","rooms":[
+				{"id":"living","name":"Living Room","therm_setpoint_mode":"manual"},
+				{"id":"bedroom","name":"Bedroom","therm_setpoint_mode":"boost"},
+				{"id":"hall","name":"Hall"}
+			]}}}`),
+		},
+	}
+	c := NewThermostatCollector(logrus.New(), client)
+
+	want := `# HELP netatmo_thermostat_setpoint_mode Netatmo Energy setpoint mode of a room. The series of the current mode is set to 1, the others to 0.
+# TYPE netatmo_thermostat_setpoint_mode gauge
+netatmo_thermostat_setpoint_mode{home_id="home",home_name="Home",mode="away",room_id="bedroom",room_name="Bedroom"} 0
+netatmo_thermostat_setpoint_mode{home_id="home",home_name="Home",mode="away",room_id="living",room_name="Living Room"} 0
+netatmo_thermostat_setpoint_mode{home_id="home",home_name="Home",mode="boost",room_id="bedroom",room_name="Bedroom"} 1
+netatmo_thermostat_setpoint_mode{home_id="home",home_name="Home",mode="hg",room_id="bedroom",room_name="Bedroom"} 0
+netatmo_thermostat_setpoint_mode{home_id="home",home_name="Home",mode="hg",room_id="living",room_name="Living Room"} 0
+netatmo_thermostat_setpoint_mode{home_id="home",home_name="Home",mode="home",room_id="bedroom",room_name="Bedroom"} 0
+netatmo_thermostat_setpoint_mode{home_id="home",home_name="Home",mode="home",room_id="living",room_name="Living Room"} 0
+netatmo_thermostat_setpoint_mode{home_id="home",home_name="Home",mode="manual",room_id="bedroom",room_name="Bedroom"} 0
+netatmo_thermostat_setpoint_mode{home_id="home",home_name="Home",mode="manual",room_id="living",room_name="Living Room"} 1
+netatmo_thermostat_setpoint_mode{home_id="home",home_name="Home",mode="max",room_id="bedroom",room_name="Bedroom"} 0
+netatmo_thermostat_setpoint_mode{home_id="home",home_name="Home",mode="max",room_id="living",room_name="Living Room"} 0
+netatmo_thermostat_setpoint_mode{home_id="home",home_name="Home",mode="off",room_id="bedroom",room_name="Bedroom"} 0
+netatmo_thermostat_setpoint_mode{home_id="home",home_name="Home",mode="off",room_id="living",room_name="Living Room"} 0
+netatmo_thermostat_setpoint_mode{home_id="home",home_name="Home",mode="schedule",room_id="bedroom",room_name="Bedroom"} 0
+netatmo_thermostat_setpoint_mode{home_id="home",home_name="Home",mode="schedule",room_id="living",room_name="Living Room"} 0
+`
+	if err := testutil.CollectAndCompare(c, strings.NewReader(want), "netatmo_thermostat_setpoint_mode"); err != nil {
+		t.Errorf("metrics differ: %s", err)
+	}
+}
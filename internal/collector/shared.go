@@ -0,0 +1,90 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// sharedClient wraps a NetatmoClient, so that identical requests running at the same time share a single request.
+type sharedClient struct {
+	client   NetatmoClient
+	requests singleflight.Group
+}
+
+// NewSharedClient wraps client, so that concurrent collections, for example by two Prometheus servers scraping at
+// the same time, share a single request to the Netatmo API and its result instead of each doing their own request.
+// The shared request uses the context of the collection which started it. The results are shared by all callers
+// and must not be modified.
+func NewSharedClient(client NetatmoClient) NetatmoClient {
+	return &sharedClient{
+		client: client,
+	}
+}
+
+// shared executes fn, unless a request with the same key is already running, in which case its result is returned.
+func shared[T any](g *singleflight.Group, key string, fn func() (T, error)) (T, error) {
+	v, err, _ := g.Do(key, func() (any, error) {
+		return fn()
+	})
+
+	return v.(T), err
+}
+
+// HomesData implements NetatmoClient.
+func (c *sharedClient) HomesData(ctx context.Context) (*HomesDataResponse, error) {
+	return shared(&c.requests, "homesdata", func() (*HomesDataResponse, error) {
+		return c.client.HomesData(ctx)
+	})
+}
+
+// HomeStatus implements NetatmoClient.
+func (c *sharedClient) HomeStatus(ctx context.Context, homeID string, deviceTypes []string) (*HomeStatusResponse, error) {
+	key := "homestatus/" + homeID + "/" + strings.Join(deviceTypes, ",")
+
+	return shared(&c.requests, key, func() (*HomeStatusResponse, error) {
+		return c.client.HomeStatus(ctx, homeID, deviceTypes)
+	})
+}
+
+// StationsData implements NetatmoClient.
+func (c *sharedClient) StationsData(ctx context.Context) (*StationsDataResponse, error) {
+	return shared(&c.requests, "getstationsdata", func() (*StationsDataResponse, error) {
+		return c.client.StationsData(ctx)
+	})
+}
+
+// Measure implements NetatmoClient.
+func (c *sharedClient) Measure(ctx context.Context, params MeasureRequest) ([]MeasureSample, error) {
+	key := fmt.Sprintf("getmeasure/%s/%s/%s/%s/%d/%s", params.DeviceID, params.ModuleID, params.Scale,
+		strings.Join(params.Types, ","), params.DateBegin.Unix(), params.DateEnd)
+
+	return shared(&c.requests, key, func() ([]MeasureSample, error) {
+		return c.client.Measure(ctx, params)
+	})
+}
+
+// PublicData implements NetatmoClient.
+func (c *sharedClient) PublicData(ctx context.Context, area BoundingBox) (*PublicDataResponse, error) {
+	key := fmt.Sprintf("getpublicdata/%v/%v/%v/%v", area.LatSW, area.LonSW, area.LatNE, area.LonNE)
+
+	return shared(&c.requests, key, func() (*PublicDataResponse, error) {
+		return c.client.PublicData(ctx, area)
+	})
+}
+
+// SecurityHomeData implements NetatmoClient.
+func (c *sharedClient) SecurityHomeData(ctx context.Context) (*SecurityHomeDataResponse, error) {
+	return shared(&c.requests, "gethomedata", func() (*SecurityHomeDataResponse, error) {
+		return c.client.SecurityHomeData(ctx)
+	})
+}
+
+// ThermostatsData implements NetatmoClient.
+func (c *sharedClient) ThermostatsData(ctx context.Context) (*ThermostatsDataResponse, error) {
+	return shared(&c.requests, "getthermostatsdata", func() (*ThermostatsDataResponse, error) {
+		return c.client.ThermostatsData(ctx)
+	})
+}
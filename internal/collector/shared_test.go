@@ -0,0 +1,76 @@
+package collector
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// blockingClient counts the homesdata requests and blocks them until release is closed.
+type blockingClient struct {
+	fakeClient
+	calls   atomic.Int32
+	release chan struct{}
+}
+
+func (c *blockingClient) HomesData(ctx context.Context) (*HomesDataResponse, error) {
+	c.calls.Add(1)
+	<-c.release
+
+	return c.fakeClient.HomesData(ctx)
+}
+
+func TestSharedClient(t *testing.T) {
+	const collections = 5
+
+	client := &blockingClient{
+		fakeClient: fakeClient{
+			homesData: mustDecode[HomesDataResponse](t, `{"body":{"homes":[{"id":"home","name":"Home"}]}}`),
+		},
+		release: make(chan struct{}),
+	}
+	shared := NewSharedClient(client)
+
+	var wg sync.WaitGroup
+	results := make([]*HomesDataResponse, collections)
+	for i := range collections {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			result, err := shared.HomesData(context.Background())
+			if err != nil {
+				t.Errorf("got error: %s", err)
+			}
+			results[i] = result
+		}()
+	}
+
+	// Give all collections the chance to join the running request.
+	for client.calls.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(client.release)
+	wg.Wait()
+
+	if calls := client.calls.Load(); calls != 1 {
+		t.Errorf("got %d requests, want 1", calls)
+	}
+
+	for i, result := range results {
+		if result != client.homesData {
+			t.Errorf("collection %d got a different result", i)
+		}
+	}
+
+	if _, err := shared.HomesData(context.Background()); err != nil {
+		t.Errorf("got error: %s", err)
+	}
+
+	if calls := client.calls.Load(); calls != 2 {
+		t.Errorf("got %d requests after the shared request was done, want 2", calls)
+	}
+}
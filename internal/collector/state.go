@@ -0,0 +1,87 @@
+package collector
+
+import (
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ThermostatState contains the state of a home as collected by the ThermostatCollector.
+type ThermostatState struct {
+	HomeID   string `json:"homeId"`
+	HomeName string `json:"homeName"`
+	// BoilerStatus is the per-home boiler status, which is not set in the BoilerStatusRoom mode.
+	BoilerStatus *float64    `json:"boilerStatus"`
+	Rooms        []RoomState `json:"rooms"`
+}
+
+// RoomState contains the state of a room. Values not reported by the Netatmo API are nil.
+type RoomState struct {
+	ID           string   `json:"id"`
+	Name         string   `json:"name"`
+	Temperature  *float64 `json:"temperature"`
+	Setpoint     *float64 `json:"setpoint"`
+	BoilerStatus *float64 `json:"boilerStatus"`
+}
+
+// StatePublisher receives the state of the collected homes after every collection of the ThermostatCollector,
+// for example to forward it to a home-automation system. Publish is called during the scrape, so it must not block.
+type StatePublisher interface {
+	Publish(homes []ThermostatState)
+}
+
+// StoredState contains the state of a home and the time it was collected.
+type StoredState struct {
+	ThermostatState
+	Updated time.Time `json:"updated"`
+}
+
+// StateStore implements StatePublisher by keeping the latest state of every home, for example to serve it over
+// HTTP without additional requests to the Netatmo API. Homes which are not part of a collection, for example
+// because of the maximum number of homes per scrape, keep their previous state.
+type StateStore struct {
+	clock func() time.Time
+
+	lock  sync.Mutex
+	homes map[string]StoredState
+}
+
+// NewStateStore creates an empty StateStore.
+func NewStateStore() *StateStore {
+	return &StateStore{
+		clock: time.Now,
+		homes: map[string]StoredState{},
+	}
+}
+
+// Publish implements StatePublisher.
+func (s *StateStore) Publish(homes []ThermostatState) {
+	now := s.clock()
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, home := range homes {
+		s.homes[home.HomeID] = StoredState{
+			ThermostatState: home,
+			Updated:         now,
+		}
+	}
+}
+
+// States returns the latest state of all homes collected so far, sorted by home ID.
+func (s *StateStore) States() []StoredState {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	result := make([]StoredState, 0, len(s.homes))
+	for _, home := range s.homes {
+		result = append(result, home)
+	}
+	slices.SortFunc(result, func(a, b StoredState) int {
+		return strings.Compare(a.HomeID, b.HomeID)
+	})
+
+	return result
+}
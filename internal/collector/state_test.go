@@ -0,0 +1,40 @@
+package collector
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestStateStore(t *testing.T) {
+	first := time.Unix(1700000000, 0)
+	second := first.Add(5 * time.Minute)
+
+	store := NewStateStore()
+	store.clock = func() time.Time { return first }
+	store.Publish([]ThermostatState{
+		{HomeID: "home-b", HomeName: "B"},
+		{HomeID: "home-a", HomeName: "A"},
+	})
+
+	// The second collection only contains one of the homes, the other keeps its state.
+	store.clock = func() time.Time { return second }
+	store.Publish([]ThermostatState{
+		{HomeID: "home-b", HomeName: "B renamed"},
+	})
+
+	want := []StoredState{
+		{
+			ThermostatState: ThermostatState{HomeID: "home-a", HomeName: "A"},
+			Updated:         first,
+		},
+		{
+			ThermostatState: ThermostatState{HomeID: "home-b", HomeName: "B renamed"},
+			Updated:         second,
+		},
+	}
+	if diff := cmp.Diff(want, store.States()); diff != "" {
+		t.Errorf("states differ: -want +got\n%s", diff)
+	}
+}
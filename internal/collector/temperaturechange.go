@@ -0,0 +1,51 @@
+package collector
+
+import (
+	"sync"
+	"time"
+)
+
+// temperatureChangeInterval is the minimum time between the two readings used for the rate of change. The Netatmo
+// API only updates the temperatures every few minutes in steps of 0.1 degrees, so readings closer together than
+// this would mostly report no change or large jumps.
+const temperatureChangeInterval = 15 * time.Minute
+
+// temperatureChangeState contains the previous temperature reading and the most recent rate of change of each room.
+type temperatureChangeState struct {
+	sync.Mutex
+	rooms map[string]*temperatureReadings
+}
+
+type temperatureReadings struct {
+	temperature float64
+	time        time.Time
+	// change is nil until two readings are available.
+	change *float64
+}
+
+// temperatureChange records the temperature of the room and returns its rate of change per hour. The reading is
+// only replaced once temperatureChangeInterval has passed, in between the previous rate is returned. The result
+// is nil until the room has two readings.
+func (c *ThermostatCollector) temperatureChange(roomID string, temperature float64, now time.Time) *float64 {
+	c.temperatureChangeState.Lock()
+	defer c.temperatureChangeState.Unlock()
+
+	readings, ok := c.temperatureChangeState.rooms[roomID]
+	if !ok {
+		c.temperatureChangeState.rooms[roomID] = &temperatureReadings{
+			temperature: temperature,
+			time:        now,
+		}
+		return nil
+	}
+
+	elapsed := now.Sub(readings.time)
+	if elapsed >= temperatureChangeInterval {
+		change := (temperature - readings.temperature) / elapsed.Hours()
+		readings.change = &change
+		readings.temperature = temperature
+		readings.time = now
+	}
+
+	return readings.change
+}
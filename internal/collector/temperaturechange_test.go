@@ -0,0 +1,78 @@
+package collector
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sirupsen/logrus"
+)
+
+func TestThermostatCollector_TemperatureChange(t *testing.T) {
+	client := &fakeClient{
+		homesData: mustDecode[HomesDataResponse](t, `{"body":{"homes":[{"id":"home","name":"Home","rooms":[{"id":"room","name":"Bedroom"}]}]}}`),
+	}
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	c := NewThermostatCollector(logrus.New(), client)
+	c.clock = func() time.Time {
+		return now
+	}
+
+	tt := []struct {
+		desc        string
+		advance     time.Duration
+		temperature float64
+		want        string
+	}{
+		{
+			desc:        "first reading",
+			temperature: 18,
+			want:        "",
+		},
+		{
+			desc:        "second reading too early",
+			advance:     5 * time.Minute,
+			temperature: 18.5,
+			want:        "",
+		},
+		{
+			desc:        "heating up",
+			advance:     25 * time.Minute,
+			temperature: 19,
+			want:        "2",
+		},
+		{
+			desc:        "keep rate between readings",
+			advance:     time.Minute,
+			temperature: 19.1,
+			want:        "2",
+		},
+		{
+			desc:        "cooling down",
+			advance:     59 * time.Minute,
+			temperature: 18.5,
+			want:        "-0.5",
+		},
+	}
+
+	for _, tc := range tt {
+		now = now.Add(tc.advance)
+		client.homeStatus = map[string]*HomeStatusResponse{
+			"home": mustDecode[HomeStatusResponse](t, fmt.Sprintf(`{"body":{"home":{"id":"home","rooms":[
+				{"id":"room","therm_measured_temperature":%v}
+			]}}}`, tc.temperature)),
+		}
+
+		want := ""
+		if tc.want != "" {
+			want = `# HELP netatmo_room_temperature_change_per_hour Netatmo Energy rate of change of the room temperature in degrees Celsius per hour between the two most recent readings of the exporter.
+# TYPE netatmo_room_temperature_change_per_hour gauge
+netatmo_room_temperature_change_per_hour{home_id="home",home_name="Home",room_id="room",room_name="Bedroom"} ` + tc.want + "\n"
+		}
+		if err := testutil.CollectAndCompare(c, strings.NewReader(want), "netatmo_room_temperature_change_per_hour"); err != nil {
+			t.Errorf("%s: metrics differ: %s", tc.desc, err)
+		}
+	}
+}
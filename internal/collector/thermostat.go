@@ -3,248 +3,1444 @@ package collector
 import (
 	"context"
 	"encoding/json"
-	"fmt"
-	"net/http"
+	"errors"
+	"math"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/sirupsen/logrus"
-	"golang.org/x/oauth2"
 )
 
 var (
 	thermostatLabels = []string{"home_id", "home_name", "room_id", "room_name"}
 
-	thermostatTemperatureDesc = prometheus.NewDesc(
-		prefix+"thermostat_temperature",
-		"Netatmo Energy measured room temperature in degrees Celsius.",
-		thermostatLabels,
-		nil,
-	)
-
-	thermostatSetpointDesc = prometheus.NewDesc(
-		prefix+"thermostat_setpoint",
-		"Netatmo Energy target setpoint temperature in degrees Celsius.",
-		thermostatLabels,
-		nil,
-	)
-
-	thermostatBoilerStatusDesc = prometheus.NewDesc(
-		prefix+"thermostat_boiler_status",
-		"Netatmo Energy boiler status (1=on, 0=off). Per-room when possibile, otherwise per-home.",
-		thermostatLabels,
-		nil,
-	)
+	// thermostatModuleLabels are the labels of metrics of single modules, like valves, in a room.
+	thermostatModuleLabels = []string{"home_id", "home_name", "room_id", "module_id"}
+
+	// relayTypes contains the module types of relays.
+	relayTypes = []string{"NAPlug", "OTH"}
 )
 
+// BoilerStatusMode selects what is reported as netatmo_thermostat_boiler_status.
+type BoilerStatusMode string
+
+const (
+	// BoilerStatusMixed reports the boiler status of the module in a room if there is one and the per-home boiler
+	// status otherwise.
+	BoilerStatusMixed BoilerStatusMode = "mixed"
+	// BoilerStatusRoom only reports the heating demand of each room derived from heating_power_request.
+	BoilerStatusRoom BoilerStatusMode = "room"
+	// BoilerStatusBoiler only reports the per-home boiler firing state.
+	BoilerStatusBoiler BoilerStatusMode = "boiler"
+)
 
 type ThermostatCollector struct {
-	log       logrus.FieldLogger
-	tokenFunc func() (*oauth2.Token, error)
+	log              logrus.FieldLogger
+	client           NetatmoClient
+	clock            func() time.Time
+	boilerOnInterval time.Duration
+	excludeHomes     *regexp.Regexp
+	includeHomes     map[string]bool
+	homeStatusDelay  time.Duration
+	dualUnits        bool
+	collectTimeout   time.Duration
+	maxHomes         int
+	comfortScore     bool
+	missingAsNaN     bool
+	readiness        *Readiness
+	attention        AttentionThresholds
+	boilerStatusMode BoilerStatusMode
+
+	homesDataInterval    time.Duration
+	publishers           []StatePublisher
+	boilerSampleInterval time.Duration
+	debugRooms           bool
+	roomHeatingTime      bool
+	boilerAvailable      bool
+
+	underheatingThreshold float64
+	underheatingDuration  time.Duration
+	heatingSeasonWindow   time.Duration
+
+	homeStatusRetries    int
+	homeStatusRetryDelay time.Duration
+	homeStatusCacheTTL   time.Duration
+	homeConcurrency      int
+	// homeStatusInFlight is the number of homestatus requests currently running, including those of other scrapes.
+	homeStatusInFlight atomic.Int64
+	deviceTypes        []string
+
+	metricPrefix string
+	descs        *thermostatDescs
+
+	homesLock    sync.Mutex
+	cachedHomes  []homeData
+	cachedUser   *homesUser
+	homesUpdated time.Time
+	homesOffset  int
+	warnedEmpty  bool
+
+	boilerOn     boilerOnState
+	dutyCycle    dutyCycleState
+	boilerCycles boilerCycleState
+
+	underheatingState      underheatingState
+	valveSaturationState   valveSaturationState
+	roomHeatingState       roomHeatingState
+	heatingSeasonState     heatingSeasonState
+	temperatureChangeState temperatureChangeState
+	setpointChangeState    setpointChangeState
+	homeRetries            homeRetryState
+	homeStatusCache        homeStatusCache
+	homeSuccess            homeSuccessState
+	scrapeErrors           scrapeErrorState
+
+	setpointsLock sync.Mutex
+	setpoints     map[string]*setpointState
+
+	modesLock sync.Mutex
+	modes     map[string]*modeState
+}
+
+// setpointState contains the last setpoint of a room and the number of changes observed.
+type setpointState struct {
+	last    float64
+	changes float64
+}
+
+// modeState contains the last therm_mode of a home and the number of changes observed.
+type modeState struct {
+	last    string
+	changes float64
+}
+
+// ThermostatOption sets an optional parameter of the ThermostatCollector.
+type ThermostatOption func(c *ThermostatCollector)
+
+// WithBoilerOnInterval enables retrieving the time the boiler was switched on from getmeasure once per interval.
+func WithBoilerOnInterval(interval time.Duration) ThermostatOption {
+	return func(c *ThermostatCollector) {
+		c.boilerOnInterval = interval
+	}
+}
+
+// WithExcludedHomes skips all homes with a name matching the pattern, for example demo homes.
+func WithExcludedHomes(pattern *regexp.Regexp) ThermostatOption {
+	return func(c *ThermostatCollector) {
+		c.excludeHomes = pattern
+	}
+}
+
+// WithIncludedHomes only collects the homes whose ID or name is contained in homes. All homes are collected if homes
+// is empty. Homes matching the pattern of WithExcludedHomes are skipped even if they are included.
+func WithIncludedHomes(homes []string) ThermostatOption {
+	return func(c *ThermostatCollector) {
+		c.includeHomes = nil
+		if len(homes) > 0 {
+			c.includeHomes = make(map[string]bool, len(homes))
+			for _, home := range homes {
+				c.includeHomes[home] = true
+			}
+		}
+	}
+}
+
+// WithHomeStatusDelay waits for the delay between the homestatus requests of successive homes.
+func WithHomeStatusDelay(delay time.Duration) ThermostatOption {
+	return func(c *ThermostatCollector) {
+		c.homeStatusDelay = delay
+	}
+}
+
+// WithDualUnits additionally reports the room temperature in degrees Fahrenheit.
+func WithDualUnits(enabled bool) ThermostatOption {
+	return func(c *ThermostatCollector) {
+		c.dualUnits = enabled
+	}
+}
+
+// WithCollectTimeout limits the duration of a collection. Homes which have not been collected when the timeout
+// is reached are skipped.
+func WithCollectTimeout(timeout time.Duration) ThermostatOption {
+	return func(c *ThermostatCollector) {
+		c.collectTimeout = timeout
+	}
+}
+
+// WithMaxHomes limits the number of homes collected during one scrape. If there are more homes, they are collected
+// round-robin across scrapes.
+func WithMaxHomes(maxHomes int) ThermostatOption {
+	return func(c *ThermostatCollector) {
+		c.maxHomes = maxHomes
+	}
+}
+
+// WithComfortScore additionally reports an approximated comfort score for each room.
+func WithComfortScore(enabled bool) ThermostatOption {
+	return func(c *ThermostatCollector) {
+		c.comfortScore = enabled
+	}
+}
+
+// WithMissingAsNaN reports the temperature and setpoint of rooms which do not report them as NaN instead of
+// omitting them, so that graphs show a gap instead of the last value.
+func WithMissingAsNaN(enabled bool) ThermostatOption {
+	return func(c *ThermostatCollector) {
+		c.missingAsNaN = enabled
+	}
+}
+
+// WithReadiness marks readiness as ready after the first collection, for which the status of at least one home or
+// an empty list of homes was retrieved.
+func WithReadiness(readiness *Readiness) ThermostatOption {
+	return func(c *ThermostatCollector) {
+		c.readiness = readiness
+	}
 }
 
-func NewThermostatCollector(log logrus.FieldLogger, tokenFunc func() (*oauth2.Token, error)) *ThermostatCollector {
-	return &ThermostatCollector{
-		log:       log,
-		tokenFunc: tokenFunc,
+// WithAttentionThresholds sets the limits for reporting that a module needs attention. By default the
+// DefaultAttentionThresholds are used.
+func WithAttentionThresholds(thresholds AttentionThresholds) ThermostatOption {
+	return func(c *ThermostatCollector) {
+		c.attention = thresholds
 	}
 }
 
+// WithBoilerStatusMode selects what is reported as the thermostat boiler status. The default is BoilerStatusMixed.
+func WithBoilerStatusMode(mode BoilerStatusMode) ThermostatOption {
+	return func(c *ThermostatCollector) {
+		c.boilerStatusMode = mode
+	}
+}
+
+// WithHomesDataInterval only requests homesdata, which contains the mostly static list of homes, rooms, modules and
+// schedules, once per interval. The homestatus of every home is still requested on every scrape.
+func WithHomesDataInterval(interval time.Duration) ThermostatOption {
+	return func(c *ThermostatCollector) {
+		c.homesDataInterval = interval
+	}
+}
+
+// WithStatePublisher passes the state of the homes to the publisher after every collection. It can be used
+// several times to pass the state to more than one publisher.
+func WithStatePublisher(publisher StatePublisher) ThermostatOption {
+	return func(c *ThermostatCollector) {
+		c.publishers = append(c.publishers, publisher)
+	}
+}
+
+// WithBoilerSampleInterval enables the boiler duty cycle, for which the boiler status of all homes is additionally
+// sampled once per interval by RunBoilerSampler.
+func WithBoilerSampleInterval(interval time.Duration) ThermostatOption {
+	return func(c *ThermostatCollector) {
+		c.boilerSampleInterval = interval
+	}
+}
+
+// WithHomeStatusDeviceTypes only requests the modules of these types in the homestatus requests, for example to
+// leave out the modules of other Netatmo products in the same home. An empty list requests all modules.
+func WithHomeStatusDeviceTypes(deviceTypes []string) ThermostatOption {
+	return func(c *ThermostatCollector) {
+		c.deviceTypes = deviceTypes
+	}
+}
+
+// WithRoomDebug logs the parsed status of every room on the debug log level before its metrics are created.
+func WithRoomDebug(enabled bool) ThermostatOption {
+	return func(c *ThermostatCollector) {
+		c.debugRooms = enabled
+	}
+}
+
+// WithRoomHeatingTime additionally reports the estimated time each room has been heating since local midnight. The
+// estimate is more accurate if the boiler sampler is enabled, because it also observes the rooms.
+func WithRoomHeatingTime(enabled bool) ThermostatOption {
+	return func(c *ThermostatCollector) {
+		c.roomHeatingTime = enabled
+	}
+}
+
+// WithBoilerStatusAvailable additionally reports for each home whether any of its modules reports a boiler status, so
+// that a missing boiler status can be told apart from a boiler which is off.
+func WithBoilerStatusAvailable(enabled bool) ThermostatOption {
+	return func(c *ThermostatCollector) {
+		c.boilerAvailable = enabled
+	}
+}
+
+func NewThermostatCollector(log logrus.FieldLogger, client NetatmoClient, opts ...ThermostatOption) *ThermostatCollector {
+	c := &ThermostatCollector{
+		log:              log,
+		client:           client,
+		clock:            time.Now,
+		metricPrefix:     prefix,
+		attention:        DefaultAttentionThresholds,
+		boilerStatusMode: BoilerStatusMixed,
+		homeConcurrency:  defaultHomeConcurrency,
+		boilerOn: boilerOnState{
+			since:   map[string]time.Time{},
+			seconds: map[string]float64{},
+		},
+		dutyCycle: dutyCycleState{
+			homes: map[string]*dutyCycleSamples{},
+		},
+		boilerCycles: boilerCycleState{
+			homes: map[string]*boilerCycles{},
+		},
+		underheatingState: underheatingState{
+			since: map[string]time.Time{},
+		},
+		valveSaturationState: valveSaturationState{
+			since: map[string]time.Time{},
+		},
+		roomHeatingState: roomHeatingState{
+			rooms: map[string]*roomHeating{},
+		},
+		heatingSeasonState: heatingSeasonState{
+			lastDemand: map[string]time.Time{},
+		},
+		temperatureChangeState: temperatureChangeState{
+			rooms: map[string]*temperatureReadings{},
+		},
+		setpointChangeState: setpointChangeState{
+			rooms: map[string]float64{},
+			homes: map[string]time.Time{},
+		},
+		homeRetries: homeRetryState{
+			names:   map[string]string{},
+			retries: map[string]float64{},
+		},
+		homeStatusCache: homeStatusCache{
+			entries: map[string]cachedHomeStatus{},
+			names:   map[string]string{},
+			hits:    map[string]float64{},
+		},
+		homeSuccess: homeSuccessState{
+			names: map[string]string{},
+			times: map[string]time.Time{},
+		},
+		scrapeErrors: scrapeErrorState{
+			errors: map[string]float64{},
+		},
+		setpoints: map[string]*setpointState{},
+		modes:     map[string]*modeState{},
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.descs = newThermostatDescs(c.metricPrefix)
+
+	return c
+}
+
+// Describe implements prometheus.Collector. Only the descriptors of metrics enabled by the options are sent.
 func (c *ThermostatCollector) Describe(ch chan<- *prometheus.Desc) {
-	ch <- thermostatTemperatureDesc
-	ch <- thermostatSetpointDesc
-	ch <- thermostatBoilerStatusDesc
+	ch <- c.descs.thermostatTemperature
+	if c.dualUnits {
+		ch <- c.descs.thermostatTemperatureFahrenheit
+	}
+	ch <- c.descs.roomTemperatureChange
+	ch <- c.descs.thermostatSetpoint
+	ch <- c.descs.thermostatSetpointEndTime
+	ch <- c.descs.thermostatHumidity
+	ch <- c.descs.thermostatHeatingPowerRequest
+	ch <- c.descs.thermostatAnticipating
+	ch <- c.descs.thermostatOpenWindow
+	ch <- c.descs.setpointChanges
+	if c.comfortScore {
+		ch <- c.descs.roomComfortScore
+	}
+	if c.underheatingThreshold > 0 {
+		ch <- c.descs.roomUnderheating
+	}
+	ch <- c.descs.valveSetpointUnreachable
+	if c.roomHeatingTime {
+		ch <- c.descs.roomHeatingSecondsToday
+	}
+	ch <- c.descs.roomMaxModeActive
+	ch <- c.descs.thermostatSetpointMode
+	ch <- c.descs.thermostatBoilerStatus
+	ch <- c.descs.thermostatRelayCmd
+	ch <- c.descs.boilerStatus
+	if c.boilerAvailable {
+		ch <- c.descs.boilerStatusAvailable
+	}
+	ch <- c.descs.relayFirmwareRevision
+	ch <- c.descs.moduleSetupTime
+	ch <- c.descs.moduleNeedsAttention
+	ch <- c.descs.moduleTypeCode
+	ch <- c.descs.moduleBatteryVoltage
+	ch <- c.descs.orphanedModule
+	ch <- c.descs.scheduleTimeslotSetpoint
+	ch <- c.descs.nextSetpointChange
+	ch <- c.descs.roomActiveZone
+	ch <- c.descs.roomComfortSetpoint
+	ch <- c.descs.energySavingOpportunities
+	ch <- c.descs.homeSchedules
+	ch <- c.descs.homeActiveSchedule
+	ch <- c.descs.homeAway
+	ch <- c.descs.homeModeChanges
+	ch <- c.descs.homeLastSetpointChange
+	ch <- c.descs.collectionState
+	ch <- c.descs.roomHeatingWhileAway
+	ch <- c.descs.roomInfo
+	ch <- c.descs.homeReachable
+	ch <- c.descs.thermostatModuleReachable
+	ch <- c.descs.thermostatModuleInfo
+	ch <- c.descs.thermostatModuleBatteryPercent
+	ch <- c.descs.homeUnreachableModules
+	ch <- c.descs.homeHeatDemand
+	ch <- c.descs.homeDataCompleteness
+	if c.heatingSeasonWindow > 0 {
+		ch <- c.descs.heatingActiveSeason
+	}
+	ch <- c.descs.thermostatHomesFromCache
+	ch <- c.descs.thermostatHomesDataSkipped
+	ch <- c.descs.homesDiscovered
+	ch <- c.descs.homesProcessed
+	ch <- c.descs.homeStatusConcurrency
+	ch <- c.descs.homeStatusInFlight
+	if c.homeStatusRetries > 0 {
+		ch <- c.descs.homeStatusRetries
+	}
+	if c.homeStatusCacheTTL > 0 {
+		ch <- c.descs.homeStatusCacheHits
+	}
+	ch <- c.descs.scrapeDuration
+	ch <- c.descs.scrapeErrors
+	ch <- c.descs.homeLastSuccess
+	if c.collectTimeout > 0 {
+		ch <- c.descs.scrapeTimedOut
+	}
+	ch <- c.descs.accountInfo
+	if c.boilerOnInterval > 0 {
+		ch <- c.descs.boilerOnSeconds
+	}
+	ch <- c.descs.boilerCycles
+	if c.boilerSampleInterval > 0 {
+		ch <- c.descs.boilerDutyCycle
+		ch <- c.descs.boilerSamples
+		ch <- c.descs.boilerOnSamples
+	}
 }
 
-// Collect implementa prometheus.Collector.
+// Collect implements prometheus.Collector.
 func (c *ThermostatCollector) Collect(ch chan<- prometheus.Metric) {
+	c.collect(ch, "")
+}
+
+// Home returns a collector, which only collects the status of the home with the ID, for example to debug a single
+// home of an account with many homes. The home is collected even if it is excluded or would not be selected because
+// of the maximum number of homes. Its state is not published, and the boiler duty cycle and the retries of the
+// status requests are only reported by the ThermostatCollector itself, because reporting them resets their samples.
+func (c *ThermostatCollector) Home(homeID string) prometheus.Collector {
+	return &homeCollector{
+		thermostat: c,
+		homeID:     homeID,
+	}
+}
+
+type homeCollector struct {
+	thermostat *ThermostatCollector
+	homeID     string
+}
+
+func (c *homeCollector) Describe(ch chan<- *prometheus.Desc) {
+	c.thermostat.Describe(ch)
+}
+
+func (c *homeCollector) Collect(ch chan<- prometheus.Metric) {
+	c.thermostat.collect(ch, c.homeID)
+}
+
+// collect collects the metrics of all selected homes, or only of the home with the ID onlyHome if it is not empty.
+func (c *ThermostatCollector) collect(ch chan<- prometheus.Metric, onlyHome string) {
+	start := c.clock()
+	defer func() {
+		c.scrapeErrors.collect(ch, c.descs.scrapeErrors)
+		ch <- prometheus.MustNewConstMetric(c.descs.scrapeDuration, prometheus.GaugeValue, c.clock().Sub(start).Seconds())
+	}()
+
 	ctx := context.Background()
+	if c.collectTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.collectTimeout)
+		defer cancel()
+	}
 
-	token, err := c.tokenFunc()
+	homes, fromCache, skipped, err := c.homes(ctx)
 	if err != nil {
-		c.log.Errorf("ThermostatCollector: error getting token: %v", err)
+		logAPIError(c.log, err, "ThermostatCollector: error fetching homesdata")
+		sendCollectionState(ch, c.descs.collectionState, collectionState(err))
 		return
 	}
-	if token == nil || !token.Valid() {
-		c.log.Debug("ThermostatCollector: token not available or invalid, skipping collection.")
-		return
+
+	homesFromCache := 0.0
+	if fromCache {
+		homesFromCache = 1.0
+	}
+	ch <- prometheus.MustNewConstMetric(c.descs.thermostatHomesFromCache, prometheus.GaugeValue, homesFromCache)
+
+	homesSkipped := 0.0
+	if skipped {
+		homesSkipped = float64(len(homes))
 	}
+	ch <- prometheus.MustNewConstMetric(c.descs.thermostatHomesDataSkipped, prometheus.GaugeValue, homesSkipped)
+	ch <- prometheus.MustNewConstMetric(c.descs.homesDiscovered, prometheus.GaugeValue, float64(len(homes)))
+	c.collectAccount(ch)
 
-	httpClient := oauth2.NewClient(ctx, oauth2.StaticTokenSource(token))
+	// Only full collections refresh the boiler on-time, so that collecting a single home does not delay the refresh
+	// of the other homes.
+	refreshBoilerOn := onlyHome == "" && c.boilerOnDue(c.clock())
 
-	homes, err := fetchHomes(ctx, httpClient)
-	if err != nil {
-		c.log.Errorf("ThermostatCollector: error fetching homesdata: %v", err)
-		return
+	// With a concurrency of two or more, the default, the statuses are requested before creating the metrics of the
+	// homes, which still happens one home after another. A concurrency of one requests the homes one after another,
+	// so that homeStatusDelay can keep the requests below the burst limit of the API.
+	var selected []homeData
+	if onlyHome == "" {
+		selected = c.selectHomes(homes)
+	} else {
+		selected = homeByID(homes, onlyHome)
+		if len(selected) == 0 {
+			c.log.Warnf("ThermostatCollector: home %s requested for collection is not part of homesdata", onlyHome)
+		}
+	}
+	var prefetched map[string]homeStatusResult
+	if c.homeConcurrency > 1 {
+		prefetched = c.prefetchHomeStatus(ctx, selected)
 	}
+	ch <- prometheus.MustNewConstMetric(c.descs.homeStatusConcurrency, prometheus.GaugeValue, float64(c.effectiveConcurrency(len(selected))))
+	ch <- prometheus.MustNewConstMetric(c.descs.homeStatusInFlight, prometheus.GaugeValue, float64(c.homeStatusInFlight.Load()))
+
+	states := make([]ThermostatState, 0, len(selected))
+	timedOut := false
+	var homeErr error
+	for i, home := range selected {
+		result, ok := prefetched[home.ID]
+		if !ok {
+			// Homes served from the cache do not make a request, so there is no need to wait for them.
+			if _, cached := c.cachedStatus(home.ID); i > 0 && c.homeStatusDelay > 0 && !cached {
+				select {
+				case <-ctx.Done():
+				case <-time.After(c.homeStatusDelay):
+				}
+			}
+
+			if ctx.Err() != nil {
+				c.log.Warnf("ThermostatCollector: collect timeout reached after %d of %d homes, skipping the remaining homes", i, len(selected))
+				timedOut = true
+				if homeErr == nil {
+					homeErr = ctx.Err()
+				}
+				break
+			}
 
-	for _, home := range homes.Body.Homes {
-		status, err := fetchHomeStatus(ctx, httpClient, home.ID)
+			result.status, result.err = c.homeStatus(ctx, home)
+		}
+
+		status, err := result.status, result.err
 		if err != nil {
-			c.log.Errorf("ThermostatCollector: error fetching homestatus for %s: %v", home.ID, err)
+			logAPIError(c.log, err, "ThermostatCollector: error fetching homestatus for %s", home.ID)
+			c.scrapeErrors.add(phaseHomeStatus)
+			if ctx.Err() != nil {
+				timedOut = true
+			}
+			homeErr = err
 			continue
 		}
 
-		h := status.Body.Home
+		states = append(states, c.collectHome(ctx, ch, home, status, refreshBoilerOn))
+	}
+	ch <- prometheus.MustNewConstMetric(c.descs.homesProcessed, prometheus.GaugeValue, float64(len(states)))
+
+	if c.boilerSampleInterval > 0 && onlyHome == "" {
+		c.dutyCycle.collect(ch, c.descs)
+	}
+
+	if c.collectTimeout > 0 {
+		timedOutValue := 0.0
+		if timedOut {
+			timedOutValue = 1.0
+		}
+		ch <- prometheus.MustNewConstMetric(c.descs.scrapeTimedOut, prometheus.GaugeValue, timedOutValue)
+	}
+
+	if c.homeStatusRetries > 0 && onlyHome == "" {
+		c.homeRetries.collect(ch, c.descs.homeStatusRetries)
+	}
+	if c.homeStatusCacheTTL > 0 && onlyHome == "" {
+		c.homeStatusCache.collect(ch, c.descs.homeStatusCacheHits)
+	}
+	if onlyHome == "" {
+		c.homeSuccess.collect(ch, c.descs.homeLastSuccess)
+	}
+
+	// The collection is only classified by its error, if no home could be collected at all.
+	switch {
+	case homeErr == nil:
+		c.readiness.markReady()
+		sendCollectionState(ch, c.descs.collectionState, collectionStateOK)
+	case len(states) == 0:
+		sendCollectionState(ch, c.descs.collectionState, collectionState(homeErr))
+	default:
+		c.readiness.markReady()
+		sendCollectionState(ch, c.descs.collectionState, collectionStatePartial)
+	}
+
+	if onlyHome != "" {
+		return
+	}
+
+	for _, publisher := range c.publishers {
+		publisher.Publish(states)
+	}
+}
+
+// homeScrape contains the data of a home shared by the metrics of its modules and rooms during a collection.
+type homeScrape struct {
+	id        string
+	name      string
+	thermMode string
+	sched     *schedule
+	now       time.Time
+
+	roomNames   map[string]string
+	roomTypes   map[string]string
+	roomIDs     map[string]string
+	moduleNames map[string]string
+	moduleRooms map[string]string
+	statusRooms map[string]bool
+
+	// boilerByRoom and demandByRoom contain the boiler status and the heating power request reported by the modules
+	// of each room.
+	boilerByRoom map[string]float64
+	demandByRoom map[string]float64
+}
+
+// roomName returns the name of the room, or a name created from its ID if the room has no name.
+func (s *homeScrape) roomName(id string) string {
+	if name := s.roomNames[id]; name != "" {
+		return name
+	}
+
+	return "id-" + id
+}
+
+// roomResult contains the values of a collected room which are summarized for its home.
+type roomResult struct {
+	state      RoomState
+	heating    bool
+	overridden bool
+}
+
+// collectHome sends the metrics of a home using its homestatus response and returns the state of the home.
+func (c *ThermostatCollector) collectHome(ctx context.Context, ch chan<- prometheus.Metric, home homeData, status *HomeStatusResponse, refreshBoilerOn bool) ThermostatState {
+	h := status.Body.Home
+
+	homeID := h.ID
+	if homeID == "" {
+		homeID = home.ID
+	}
+
+	homeName := h.Name
+	if homeName == "" {
+		homeName = home.Name
+	}
+
+	thermMode := h.ThermMode
+	if thermMode == "" {
+		thermMode = home.ThermMode
+	}
+
+	if thermMode != "" {
+		away := 0.0
+		if thermMode == "away" {
+			away = 1.0
+		}
+		ch <- prometheus.MustNewConstMetric(c.descs.homeAway, prometheus.GaugeValue, away, homeID, homeName)
+		ch <- prometheus.MustNewConstMetric(c.descs.homeModeChanges, prometheus.CounterValue, c.modeChanges(homeID, thermMode), homeID, homeName)
+	}
+
+	sched := activeSchedule(home.Schedules)
+	now := c.clock().In(homeLocation(home.Timezone))
 
-		homeID := h.ID
-		if homeID == "" {
-			homeID = home.ID
+	ch <- prometheus.MustNewConstMetric(c.descs.homeSchedules, prometheus.GaugeValue, float64(len(home.Schedules)), homeID, homeName)
+	if sched != nil {
+		ch <- prometheus.MustNewConstMetric(c.descs.homeActiveSchedule, prometheus.GaugeValue, 1, homeID, homeName, sched.ID, sched.Name)
+	}
+
+	if c.boilerOnInterval > 0 {
+		c.collectBoilerOn(ctx, ch, home, h.Modules, refreshBoilerOn, c.clock())
+	}
+
+	s := &homeScrape{
+		id:           homeID,
+		name:         homeName,
+		thermMode:    thermMode,
+		sched:        sched,
+		now:          now,
+		roomNames:    map[string]string{},
+		roomTypes:    map[string]string{},
+		roomIDs:      roomIDsByName(home.Rooms),
+		moduleNames:  map[string]string{},
+		moduleRooms:  map[string]string{},
+		statusRooms:  map[string]bool{},
+		boilerByRoom: map[string]float64{},
+		demandByRoom: map[string]float64{},
+	}
+	for _, room := range home.Rooms {
+		s.roomNames[room.ID] = room.Name
+		s.roomTypes[room.ID] = room.Type
+	}
+	for _, room := range h.Rooms {
+		if room.Name != "" {
+			s.roomNames[room.ID] = room.Name
 		}
+	}
 
-		homeName := h.Name
-		if homeName == "" {
-			homeName = home.Name
+	for _, module := range home.Modules {
+		s.moduleNames[module.ID] = module.Name
+		s.moduleRooms[module.ID] = module.RoomID
+		if module.SetupDate != nil {
+			ch <- prometheus.MustNewConstMetric(c.descs.moduleSetupTime, prometheus.GaugeValue, float64(*module.SetupDate), homeID, homeName, module.ID, module.Name)
 		}
+	}
 
-		boilerByRoom := map[string]float64{}
-		var homeBoiler *float64
+	for _, room := range h.Rooms {
+		if room.ID != "" {
+			s.statusRooms[room.ID] = true
+		} else if id := s.roomIDs[room.Name]; id != "" {
+			s.statusRooms[id] = true
+		}
+	}
 
-		for _, mod := range h.Modules {
-			if mod.BoilerStatus == nil {
-				continue
-			}
+	homeBoiler, homeReachable, unreachableModules := c.collectHomeModules(ch, s, h.Modules)
 
-			v := 0.0
-			if *mod.BoilerStatus {
-				v = 1.0
-			}
+	if c.boilerAvailable {
+		available := 0.0
+		if homeBoiler != nil {
+			available = 1.0
+		}
+		ch <- prometheus.MustNewConstMetric(c.descs.boilerStatusAvailable, prometheus.GaugeValue, available, homeID, homeName)
+	}
 
-			if mod.RoomID != "" {
-				boilerByRoom[mod.RoomID] = v
-			}
+	state := ThermostatState{
+		HomeID:   homeID,
+		HomeName: homeName,
+	}
+	heatDemand := 0.0
+	rooms, measuredRooms := 0.0, 0.0
+	savingOpportunities := 0.0
+	setpoints := map[string]float64{}
 
-			if homeBoiler == nil {
-				tmp := v
-				homeBoiler = &tmp
-			} else if v > *homeBoiler {
-				*homeBoiler = v
-			}
+	for _, room := range h.Rooms {
+		result := c.collectRoom(ch, s, room)
+		if result == nil {
+			continue
+		}
+
+		rooms++
+		if result.state.Temperature != nil {
+			measuredRooms++
+		}
+		if result.state.Setpoint != nil {
+			setpoints[result.state.ID] = *result.state.Setpoint
+		}
+		if result.heating {
+			heatDemand++
+		}
+		if result.overridden {
+			savingOpportunities++
 		}
+		state.Rooms = append(state.Rooms, result.state)
+	}
 
-		for _, room := range h.Rooms {
-			labels := []string{homeID, homeName, room.ID, room.Name}
+	ch <- prometheus.MustNewConstMetric(c.descs.homeHeatDemand, prometheus.GaugeValue, heatDemand, homeID, homeName)
+	if sched != nil {
+		ch <- prometheus.MustNewConstMetric(c.descs.energySavingOpportunities, prometheus.GaugeValue, savingOpportunities, homeID, homeName)
+	}
+	if rooms > 0 {
+		ch <- prometheus.MustNewConstMetric(c.descs.homeDataCompleteness, prometheus.GaugeValue, measuredRooms/rooms, homeID, homeName)
+	}
+	lastChange := c.lastSetpointChange(homeID, setpoints, c.clock())
+	ch <- prometheus.MustNewConstMetric(c.descs.homeLastSetpointChange, prometheus.GaugeValue, float64(lastChange.Unix()), homeID, homeName)
+	if c.heatingSeasonWindow > 0 {
+		ch <- prometheus.MustNewConstMetric(c.descs.heatingActiveSeason, prometheus.GaugeValue, c.heatingSeason(homeID, heatDemand, c.clock()), homeID, homeName)
+	}
+	sendOptional(ch, c.descs.homeReachable, homeReachable, homeID, homeName)
+	if homeReachable != nil {
+		ch <- prometheus.MustNewConstMetric(c.descs.homeUnreachableModules, prometheus.GaugeValue, unreachableModules, homeID, homeName)
+	}
 
-			if room.MeasuredTemperature != nil {
-				ch <- prometheus.MustNewConstMetric(
-					thermostatTemperatureDesc,
-					prometheus.GaugeValue,
-					*room.MeasuredTemperature,
-					labels...,
-				)
-			}
+	if homeBoiler != nil && c.boilerStatusMode != BoilerStatusRoom {
+		labels := []string{homeID, homeName, "", "", "home"}
+		ch <- prometheus.MustNewConstMetric(
+			c.descs.thermostatBoilerStatus,
+			prometheus.GaugeValue,
+			*homeBoiler,
+			labels...,
+		)
+		state.BoilerStatus = homeBoiler
+	}
+
+	if homeBoiler != nil {
+		cycles := c.boilerCycles.observe(homeID, *homeBoiler > 0)
+		ch <- prometheus.MustNewConstMetric(c.descs.boilerCycles, prometheus.CounterValue, cycles, homeID, homeName)
+	}
+
+	if homeBoiler != nil && c.boilerSampleInterval > 0 {
+		c.dutyCycle.add(homeID, homeName, *homeBoiler > 0)
+	}
+
+	return state
+}
 
-			if room.SetpointTemperature != nil {
-				ch <- prometheus.MustNewConstMetric(
-					thermostatSetpointDesc,
-					prometheus.GaugeValue,
-					*room.SetpointTemperature,
-					labels...,
-				)
+// collectHomeModules sends the metrics of the modules of a home and records the boiler status and heating power
+// requests of the rooms in s. It returns the boiler status and the reachability of the home, which are nil if no
+// module reports them, and the number of unreachable modules.
+func (c *ThermostatCollector) collectHomeModules(ch chan<- prometheus.Metric, s *homeScrape, modules []moduleStatus) (homeBoiler, homeReachable *float64, unreachableModules float64) {
+	for _, mod := range modules {
+		if mod.Reachable != nil {
+			if homeReachable == nil {
+				homeReachable = new(float64)
+			}
+			if *mod.Reachable {
+				*homeReachable = 1
+			} else {
+				unreachableModules++
 			}
+		}
 
-			if val, ok := boilerByRoom[room.ID]; ok {
-				ch <- prometheus.MustNewConstMetric(
-					thermostatBoilerStatusDesc,
-					prometheus.GaugeValue,
-					val,
-					labels...,
-				)
+		health := moduleHealth{
+			BatteryState: mod.BatteryState,
+			Reachable:    mod.Reachable,
+			WifiStrength: mod.WifiStrength,
+		}
+		// Modules connected using Wi-Fi, like the relay, also report an unrelated radio signal strength.
+		if mod.WifiStrength == nil {
+			health.RFStrength = mod.RFStrength
+		}
+		sendNeedsAttention(ch, c.descs.moduleNeedsAttention, c.attention, health, s.id, s.name, mod.ID, s.moduleNames[mod.ID])
+		sendModuleTypeCode(ch, c.descs.moduleTypeCode, mod.Type, s.id, s.name, mod.ID, s.moduleNames[mod.ID])
+		sendThermostatModuleInfo(ch, c.descs.thermostatModuleInfo, mod, s.id)
+		sendBatteryVoltage(ch, c.descs.moduleBatteryVoltage, mod.BatteryLevel, s.id, s.name, mod.ID, s.moduleNames[mod.ID])
+
+		moduleRoom := mod.RoomID
+		if moduleRoom == "" {
+			moduleRoom = s.moduleRooms[mod.ID]
+		}
+
+		if mod.HeatingPowerRequest != nil && moduleRoom != "" {
+			s.demandByRoom[moduleRoom] = max(s.demandByRoom[moduleRoom], *mod.HeatingPowerRequest)
+		}
+
+		if mod.Reachable != nil {
+			reachable := 0.0
+			if *mod.Reachable {
+				reachable = 1.0
 			}
+			ch <- prometheus.MustNewConstMetric(c.descs.thermostatModuleReachable, prometheus.GaugeValue, reachable, s.id, s.name, moduleRoom, mod.ID)
+		}
+		if percent, ok := batteryStatePercent[mod.BatteryState]; ok {
+			ch <- prometheus.MustNewConstMetric(c.descs.thermostatModuleBatteryPercent, prometheus.GaugeValue, percent, s.id, s.name, moduleRoom, mod.ID)
+		}
+		if isOrphaned(mod.Type, moduleRoom, s.statusRooms) {
+			c.log.Warnf("ThermostatCollector: module %s in home %s is not assigned to a known room (room_id %q)", mod.ID, s.id, moduleRoom)
+			ch <- prometheus.MustNewConstMetric(c.descs.orphanedModule, prometheus.GaugeValue, 1, s.id, s.name, mod.ID, s.moduleNames[mod.ID])
 		}
 
-		if homeBoiler != nil {
-			labels := []string{homeID, homeName, "", ""}
+		if mod.FirmwareRevision != nil && slices.Contains(relayTypes, mod.Type) {
 			ch <- prometheus.MustNewConstMetric(
-				thermostatBoilerStatusDesc,
+				c.descs.relayFirmwareRevision,
 				prometheus.GaugeValue,
-				*homeBoiler,
+				*mod.FirmwareRevision,
+				s.id, s.name, mod.ID, s.moduleNames[mod.ID],
+			)
+		}
+
+		if mod.RelayCmd != nil {
+			labels := []string{s.id, s.name, moduleRoom, s.roomName(moduleRoom)}
+			ch <- prometheus.MustNewConstMetric(
+				c.descs.thermostatRelayCmd,
+				prometheus.GaugeValue,
+				*mod.RelayCmd,
 				labels...,
 			)
 		}
+
+		if mod.BoilerStatus == nil {
+			continue
+		}
+
+		v := 0.0
+		if *mod.BoilerStatus {
+			v = 1.0
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			c.descs.boilerStatus,
+			prometheus.GaugeValue,
+			v,
+			s.id, s.name, mod.ID, s.moduleNames[mod.ID],
+		)
+
+		// A room with several modules reporting a boiler status, for example thermostats connected to
+		// different relays, reports the boiler as on if any of them does.
+		if moduleRoom != "" {
+			s.boilerByRoom[moduleRoom] = max(s.boilerByRoom[moduleRoom], v)
+		}
+
+		if homeBoiler == nil {
+			tmp := v
+			homeBoiler = &tmp
+		} else if v > *homeBoiler {
+			*homeBoiler = v
+		}
+	}
+
+	return homeBoiler, homeReachable, unreachableModules
+}
+
+// collectRoom sends the metrics of a room of a home. It returns nil if the room can not be identified.
+func (c *ThermostatCollector) collectRoom(ch chan<- prometheus.Metric, s *homeScrape, room roomStatus) *roomResult {
+	if room.ID == "" {
+		// Rooms are only identified by their ID, so a room without one can only be matched by its name.
+		room.ID = s.roomIDs[room.Name]
+	}
+	if room.ID == "" {
+		c.log.Debugf("ThermostatCollector: skipping room %q without ID in home %s", room.Name, s.id)
+		return nil
+	}
+
+	labels := []string{s.id, s.name, room.ID, s.roomName(room.ID)}
+	ch <- prometheus.MustNewConstMetric(c.descs.roomInfo, prometheus.GaugeValue, 1, append(labels, s.roomTypes[room.ID])...)
+
+	if c.debugRooms {
+		c.logRoom(s.id, room)
+	}
+
+	if room.MeasuredTemperature != nil {
+		ch <- prometheus.MustNewConstMetric(
+			c.descs.thermostatTemperature,
+			prometheus.GaugeValue,
+			*room.MeasuredTemperature,
+			labels...,
+		)
+
+		if c.dualUnits {
+			ch <- prometheus.MustNewConstMetric(
+				c.descs.thermostatTemperatureFahrenheit,
+				prometheus.GaugeValue,
+				celsiusToFahrenheit(*room.MeasuredTemperature),
+				labels...,
+			)
+		}
+
+		sendOptional(ch, c.descs.roomTemperatureChange, c.temperatureChange(room.ID, *room.MeasuredTemperature, c.clock()), labels...)
+	} else if c.missingAsNaN {
+		ch <- prometheus.MustNewConstMetric(c.descs.thermostatTemperature, prometheus.GaugeValue, math.NaN(), labels...)
+		if c.dualUnits {
+			ch <- prometheus.MustNewConstMetric(c.descs.thermostatTemperatureFahrenheit, prometheus.GaugeValue, math.NaN(), labels...)
+		}
 	}
+
+	if humidity := room.humidity(); humidity != nil {
+		ch <- prometheus.MustNewConstMetric(
+			c.descs.thermostatHumidity,
+			prometheus.GaugeValue,
+			*humidity,
+			labels...,
+		)
+	}
+
+	if room.Anticipating != nil {
+		anticipating := 0.0
+		if *room.Anticipating {
+			anticipating = 1.0
+		}
+		ch <- prometheus.MustNewConstMetric(c.descs.thermostatAnticipating, prometheus.GaugeValue, anticipating, labels...)
+	}
+
+	if room.OpenWindow != nil {
+		openWindow := 0.0
+		if *room.OpenWindow {
+			openWindow = 1.0
+		}
+		ch <- prometheus.MustNewConstMetric(c.descs.thermostatOpenWindow, prometheus.GaugeValue, openWindow, labels...)
+	}
+
+	if room.SetpointTemperature != nil {
+		ch <- prometheus.MustNewConstMetric(
+			c.descs.thermostatSetpoint,
+			prometheus.GaugeValue,
+			*room.SetpointTemperature,
+			labels...,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.descs.setpointChanges,
+			prometheus.CounterValue,
+			c.setpointChanges(room.ID, *room.SetpointTemperature),
+			labels...,
+		)
+	} else if c.missingAsNaN {
+		ch <- prometheus.MustNewConstMetric(c.descs.thermostatSetpoint, prometheus.GaugeValue, math.NaN(), labels...)
+	}
+
+	if room.SetpointMode != "" {
+		maxMode := 0.0
+		if room.SetpointMode == "max" {
+			maxMode = 1.0
+		}
+		ch <- prometheus.MustNewConstMetric(c.descs.roomMaxModeActive, prometheus.GaugeValue, maxMode, labels...)
+		sendSetpointMode(ch, c.descs.thermostatSetpointMode, room.SetpointMode, labels)
+	}
+
+	// Rooms usually report the heating power request of their valves, otherwise the highest request of the
+	// valves in the room is used.
+	demand := room.HeatingPowerRequest
+	if moduleDemand, ok := s.demandByRoom[room.ID]; ok && demand == nil {
+		demand = &moduleDemand
+	}
+	sendOptional(ch, c.descs.thermostatHeatingPowerRequest, demand, labels...)
+
+	if room.SetpointEndTime != nil && *room.SetpointEndTime > 0 {
+		ch <- prometheus.MustNewConstMetric(c.descs.thermostatSetpointEndTime, prometheus.GaugeValue, float64(*room.SetpointEndTime), labels...)
+	}
+
+	if c.comfortScore && room.MeasuredTemperature != nil && room.SetpointTemperature != nil {
+		ch <- prometheus.MustNewConstMetric(
+			c.descs.roomComfortScore,
+			prometheus.GaugeValue,
+			comfortScore(*room.MeasuredTemperature, *room.SetpointTemperature, room.humidity()),
+			labels...,
+		)
+	}
+
+	if c.underheatingThreshold > 0 && room.MeasuredTemperature != nil && room.SetpointTemperature != nil {
+		ch <- prometheus.MustNewConstMetric(
+			c.descs.roomUnderheating,
+			prometheus.GaugeValue,
+			c.underheating(room.ID, *room.MeasuredTemperature, *room.SetpointTemperature, c.clock()),
+			labels...,
+		)
+	}
+
+	if room.HeatingPowerRequest != nil && room.MeasuredTemperature != nil && room.SetpointTemperature != nil {
+		ch <- prometheus.MustNewConstMetric(
+			c.descs.valveSetpointUnreachable,
+			prometheus.GaugeValue,
+			c.setpointUnreachable(room.ID, *room.HeatingPowerRequest, *room.MeasuredTemperature, *room.SetpointTemperature, c.clock()),
+			labels...,
+		)
+	}
+
+	if c.roomHeatingTime && room.HeatingPowerRequest != nil {
+		ch <- prometheus.MustNewConstMetric(
+			c.descs.roomHeatingSecondsToday,
+			prometheus.GaugeValue,
+			c.observeRoomHeating(room.ID, *room.HeatingPowerRequest > 0, s.now),
+			labels...,
+		)
+	}
+
+	overridden := false
+	if s.sched != nil {
+		collectSchedule(ch, c.descs, s.sched, s.now, labels, room.ID)
+		overridden = s.sched.overriddenAboveComfort(room)
+	}
+
+	var roomBoiler *float64
+	switch c.boilerStatusMode {
+	case BoilerStatusMixed:
+		if val, ok := s.boilerByRoom[room.ID]; ok {
+			roomBoiler = &val
+		}
+	case BoilerStatusRoom:
+		if room.HeatingPowerRequest != nil {
+			demand := 0.0
+			if *room.HeatingPowerRequest > 0 {
+				demand = 1.0
+			}
+			roomBoiler = &demand
+		}
+	}
+	sendOptional(ch, c.descs.thermostatBoilerStatus, roomBoiler, append(labels, "room")...)
+
+	// A room calls for heat if its valves request heating power or its thermostat switched the boiler on,
+	// independent of the boiler status mode.
+	heating := (room.HeatingPowerRequest != nil && *room.HeatingPowerRequest > 0) || s.boilerByRoom[room.ID] > 0
+
+	if s.thermMode != "" {
+		heatingWhileAway := 0.0
+		if heating && s.thermMode == "away" {
+			heatingWhileAway = 1.0
+		}
+		ch <- prometheus.MustNewConstMetric(c.descs.roomHeatingWhileAway, prometheus.GaugeValue, heatingWhileAway, labels...)
+	}
+
+	return &roomResult{
+		state: RoomState{
+			ID:           room.ID,
+			Name:         s.roomName(room.ID),
+			Temperature:  room.MeasuredTemperature,
+			Setpoint:     room.SetpointTemperature,
+			BoilerStatus: roomBoiler,
+		},
+		heating:    heating,
+		overridden: overridden,
+	}
+}
+
+// logRoom logs all fields of the parsed room status. The fields are logged as JSON, so that values missing in the
+// response are shown as null instead of as pointer addresses.
+func (c *ThermostatCollector) logRoom(homeID string, room roomStatus) {
+	data, err := json.Marshal(room)
+	if err != nil {
+		c.log.Debugf("ThermostatCollector: error encoding status of room %s in home %s: %s", room.ID, homeID, err)
+		return
+	}
+
+	c.log.Debugf("ThermostatCollector: status of room %s in home %s: %s", room.ID, homeID, data)
+}
+
+// comfortScore approximates the comfort of a room, because the API does not provide the comfort indicator of the
+// app. The score starts at 100 and is reduced by 20 points per degree the temperature deviates from the setpoint
+// and, if the humidity is known, by 2 points per percent the humidity is outside of 40 to 60 percent.
+func comfortScore(temperature, setpoint float64, humidity *float64) float64 {
+	score := 100 - 20*math.Abs(temperature-setpoint)
+
+	if humidity != nil {
+		switch {
+		case *humidity < 40:
+			score -= 2 * (40 - *humidity)
+		case *humidity > 60:
+			score -= 2 * (*humidity - 60)
+		}
+	}
+
+	return math.Max(0, math.Min(100, score))
 }
 
-type homesDataResponse struct {
+// setpointChanges records the current setpoint of the room and returns the number of changes observed so far.
+func (c *ThermostatCollector) setpointChanges(roomID string, setpoint float64) float64 {
+	c.setpointsLock.Lock()
+	defer c.setpointsLock.Unlock()
+
+	state, ok := c.setpoints[roomID]
+	if !ok {
+		c.setpoints[roomID] = &setpointState{last: setpoint}
+		return 0
+	}
+
+	if setpoint != state.last {
+		state.changes++
+		state.last = setpoint
+	}
+
+	return state.changes
+}
+
+// modeChanges records the current therm_mode of the home and returns the number of changes observed so far.
+func (c *ThermostatCollector) modeChanges(homeID, mode string) float64 {
+	c.modesLock.Lock()
+	defer c.modesLock.Unlock()
+
+	state, ok := c.modes[homeID]
+	if !ok {
+		c.modes[homeID] = &modeState{last: mode}
+		return 0
+	}
+
+	if mode != state.last {
+		state.changes++
+		state.last = mode
+	}
+
+	return state.changes
+}
+
+// roomIDsByName maps the names of the rooms to their IDs. Names which are empty or used by several rooms are left
+// out, because they can not identify a room.
+func roomIDsByName(rooms []homeRoom) map[string]string {
+	ids := map[string]string{}
+	duplicates := map[string]bool{}
+	for _, room := range rooms {
+		if room.Name == "" || duplicates[room.Name] {
+			continue
+		}
+
+		if _, ok := ids[room.Name]; ok {
+			delete(ids, room.Name)
+			duplicates[room.Name] = true
+			continue
+		}
+
+		ids[room.Name] = room.ID
+	}
+
+	return ids
+}
+
+func sendThermostatModuleInfo(ch chan<- prometheus.Metric, desc *prometheus.Desc, mod moduleStatus, homeID string) {
+	firmware := ""
+	if mod.FirmwareRevision != nil {
+		firmware = strconv.FormatFloat(*mod.FirmwareRevision, 'f', -1, 64)
+	}
+
+	ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, 1, homeID, mod.ID, mod.Type, firmware)
+}
+
+// excluded returns true if the home matches the pattern of excluded homes or is not contained in the included homes.
+func (c *ThermostatCollector) excluded(home homeData) bool {
+	if c.includeHomes != nil && !c.includeHomes[home.ID] && !c.includeHomes[home.Name] {
+		return true
+	}
+
+	return c.excludeHomes != nil && c.excludeHomes.MatchString(home.Name)
+}
+
+// selectHomes returns the homes to collect during this scrape. Excluded homes and homes which are not included are
+// removed and, if the number of homes is limited, the remaining homes are selected round-robin across scrapes.
+func (c *ThermostatCollector) selectHomes(homes []homeData) []homeData {
+	var included []homeData
+	for _, home := range homes {
+		if c.excluded(home) {
+			c.log.Debugf("ThermostatCollector: skipping excluded home %s", home.ID)
+			continue
+		}
+
+		included = append(included, home)
+	}
+
+	if c.maxHomes <= 0 || len(included) <= c.maxHomes {
+		return included
+	}
+
+	c.homesLock.Lock()
+	defer c.homesLock.Unlock()
+
+	start := c.homesOffset % len(included)
+	c.homesOffset = (start + c.maxHomes) % len(included)
+
+	var selected, skipped []string
+	result := make([]homeData, 0, c.maxHomes)
+	for i := range included {
+		home := included[(start+i)%len(included)]
+		if i < c.maxHomes {
+			result = append(result, home)
+			selected = append(selected, home.ID)
+		} else {
+			skipped = append(skipped, home.ID)
+		}
+	}
+	c.log.Debugf("ThermostatCollector: collecting homes %v, skipping homes %v until next scrape", selected, skipped)
+
+	return result
+}
+
+// homeByID returns the home with the ID, or nothing if there is no such home.
+func homeByID(homes []homeData, homeID string) []homeData {
+	for _, home := range homes {
+		if home.ID == homeID {
+			return []homeData{home}
+		}
+	}
+
+	return nil
+}
+
+// homes returns the homes of the account. If homesdata fails, the homes of the last successful request are
+// returned instead and fromCache is set, so that the status of the homes can still be collected. skipped is set if
+// homesdata was not requested, because the homes data interval has not passed yet. The Netatmo API does not report
+// when the data of a home was last modified, so a fixed interval is the only way to avoid requesting unchanged data.
+func (c *ThermostatCollector) homes(ctx context.Context) (homes []homeData, fromCache, skipped bool, err error) {
+	now := c.clock()
+
+	c.homesLock.Lock()
+	cached := c.cachedHomes
+	skip := c.homesDataInterval > 0 && cached != nil && now.Sub(c.homesUpdated) < c.homesDataInterval
+	c.homesLock.Unlock()
+
+	if skip {
+		return cached, false, true, nil
+	}
+
+	// The lock is not held during the request, so that concurrent collections can share it when using the client
+	// returned by NewSharedClient.
+	result, err := c.client.HomesData(ctx)
+	if err != nil && !errors.Is(err, ErrNoToken) {
+		c.scrapeErrors.add(phaseHomesData)
+	}
+
+	c.homesLock.Lock()
+	defer c.homesLock.Unlock()
+
+	if err != nil {
+		if c.cachedHomes == nil || errors.Is(err, ErrNoToken) {
+			return nil, false, false, err
+		}
+
+		logAPIError(c.log, err, "ThermostatCollector: error fetching homesdata, using cached homes")
+		return c.cachedHomes, true, false, nil
+	}
+
+	switch {
+	case len(result.Body.Homes) == 0 && !c.warnedEmpty:
+		c.log.Warn("ThermostatCollector: homesdata returned no homes, check that the token has the read_thermostat scope and the account has a home with Netatmo Energy devices")
+		c.warnedEmpty = true
+	case len(result.Body.Homes) > 0:
+		c.warnedEmpty = false
+	}
+
+	c.cachedHomes = result.Body.Homes
+	c.cachedUser = result.Body.User
+	c.homesUpdated = now
+	return result.Body.Homes, false, false, nil
+}
+
+// HomesDataResponse contains the response of the homesdata endpoint.
+type HomesDataResponse struct {
 	Body struct {
-		Homes []struct {
-			ID   string `json:"id"`
-			Name string `json:"name"`
-		} `json:"homes"`
+		Homes []homeData `json:"homes"`
+		User  *homesUser `json:"user"`
 	} `json:"body"`
+	Error *ResponseError `json:"error"`
+}
+
+func (r *HomesDataResponse) responseError() *ResponseError {
+	return r.Error
+}
+
+type homeData struct {
+	ID        string       `json:"id"`
+	Name      string       `json:"name"`
+	Timezone  string       `json:"timezone"`
+	ThermMode string       `json:"therm_mode"`
+	Rooms     []homeRoom   `json:"rooms"`
+	Modules   []homeModule `json:"modules"`
+	Schedules []schedule   `json:"schedules"`
+}
+
+type homeRoom struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+type homeModule struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	Name string `json:"name"`
+	// RoomID is often only reported by homesdata and missing from homestatus.
+	RoomID string `json:"room_id"`
+	// Bridge contains the ID of the module connecting this module to the internet, for example the relay.
+	Bridge string `json:"bridge"`
+	// SetupDate is only included by homesdata for some modules.
+	SetupDate *int64 `json:"setup_date"`
 }
 
-type homeStatusResponse struct {
+// HomeStatusResponse contains the response of the homestatus endpoint.
+type HomeStatusResponse struct {
 	Body struct {
 		Home struct {
-			ID      string        `json:"id"`
-			Name    string        `json:"name"`
-			Rooms   []roomStatus  `json:"rooms"`
+			ID        string `json:"id"`
+			Name      string `json:"name"`
+			ThermMode string `json:"therm_mode"`
+			// Rooms and Modules can be null for a home without devices, which is decoded as a nil slice.
+			Rooms   []roomStatus   `json:"rooms"`
 			Modules []moduleStatus `json:"modules"`
 		} `json:"home"`
 	} `json:"body"`
+	Error *ResponseError `json:"error"`
+}
+
+func (r *HomeStatusResponse) responseError() *ResponseError {
+	return r.Error
 }
 
 type roomStatus struct {
-	ID                  string   `json:"id"`
-	Name                string   `json:"name"`
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// MeasuredTemperature is the temperature used by the thermostat or valves of the room. The API does not
+	// report whether it has been measured by a thermostat or estimated by a valve.
 	MeasuredTemperature *float64 `json:"therm_measured_temperature"`
 	SetpointTemperature *float64 `json:"therm_setpoint_temperature"`
+	// SetpointMode is the origin of the setpoint, for example "schedule", "manual" or "max".
+	SetpointMode string `json:"therm_setpoint_mode"`
+	// SetpointEndTime is the unix timestamp at which a temporary setpoint ends. It is missing or zero for setpoints
+	// without an end, for example the schedule or a permanent manual setpoint.
+	SetpointEndTime *int64 `json:"therm_setpoint_end_time"`
+	// RelativeHumidity and Humidity are only reported for rooms with a module measuring humidity. Depending on the
+	// module either or both of them are set.
+	RelativeHumidity *float64 `json:"therm_relative_humidity"`
+	Humidity         *float64 `json:"humidity"`
+	// HeatingPowerRequest is the heating demand of the room in percent, which is only reported for rooms with valves.
+	HeatingPowerRequest *float64 `json:"heating_power_request"`
+	// Anticipating and OpenWindow are missing for modules with firmware not supporting anticipation or open window
+	// detection.
+	Anticipating *bool `json:"anticipating"`
+	OpenWindow   *bool `json:"open_window"`
+}
+
+// humidity returns the relative humidity of the room, preferring therm_relative_humidity over humidity. It is nil if
+// the room does not report a humidity.
+func (r roomStatus) humidity() *float64 {
+	if r.RelativeHumidity != nil {
+		return r.RelativeHumidity
+	}
+
+	return r.Humidity
 }
 
 type moduleStatus struct {
 	ID           string `json:"id"`
 	Type         string `json:"type"`
 	RoomID       string `json:"room_id"`
+	Reachable    *bool  `json:"reachable,omitempty"`
 	BoilerStatus *bool  `json:"boiler_status,omitempty"`
+	// RelayCmd is only reported by the classic thermostat (NATherm1), which switches the boiler using a relay.
+	RelayCmd *float64 `json:"therm_relay_cmd,omitempty"`
+	// HeatingPowerRequest is the heating power requested by a valve in percent.
+	HeatingPowerRequest *float64 `json:"heating_power_request,omitempty"`
+	// BatteryState and BatteryLevel are only reported by battery-powered modules. BatteryState is for example
+	// "full" or "low", BatteryLevel is the voltage in millivolts.
+	BatteryState     string   `json:"battery_state,omitempty"`
+	BatteryLevel     *int     `json:"battery_level,omitempty"`
+	FirmwareRevision *float64 `json:"firmware_revision,omitempty"`
+	RFStrength       *int     `json:"rf_strength,omitempty"`
+	WifiStrength     *int     `json:"wifi_strength,omitempty"`
 }
 
-func fetchHomes(ctx context.Context, client *http.Client) (*homesDataResponse, error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://api.netatmo.com/api/homesdata", nil)
-	if err != nil {
-		return nil, fmt.Errorf("creating homesdata request: %w", err)
-	}
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("executing homesdata request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("homesdata request failed: status %s", resp.Status)
-	}
-
-	var result homesDataResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("decoding homesdata response: %w", err)
+// HomesData implements NetatmoClient.
+func (c *httpNetatmoClient) HomesData(ctx context.Context) (*HomesDataResponse, error) {
+	var result HomesDataResponse
+	if err := c.get(ctx, "homesdata", url.Values{}, &result); err != nil {
+		return nil, err
 	}
 
 	return &result, nil
 }
 
-func fetchHomeStatus(ctx context.Context, client *http.Client, homeID string) (*homeStatusResponse, error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://api.netatmo.com/api/homestatus", nil)
-	if err != nil {
-		return nil, fmt.Errorf("creating homestatus request: %w", err)
-	}
-
-	q := req.URL.Query()
-	q.Set("home_id", homeID)
-	req.URL.RawQuery = q.Encode()
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("executing homestatus request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("homestatus request failed: status %s", resp.Status)
+// HomeStatus implements NetatmoClient.
+func (c *httpNetatmoClient) HomeStatus(ctx context.Context, homeID string, deviceTypes []string) (*HomeStatusResponse, error) {
+	query := url.Values{}
+	query.Set("home_id", homeID)
+	if len(deviceTypes) > 0 {
+		query.Set("device_types", strings.Join(deviceTypes, ","))
 	}
 
-	var result homeStatusResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("decoding homestatus response: %w", err)
+	var result HomeStatusResponse
+	if err := c.get(ctx, "homestatus", query, &result); err != nil {
+		return nil, err
 	}
 
 	return &result, nil
@@ -0,0 +1,1380 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sirupsen/logrus"
+)
+
+var errNotImplemented = errors.New("not implemented")
+
+// fakeClient implements NetatmoClient using static responses.
+type fakeClient struct {
+	homesData  *HomesDataResponse
+	homesErr   error
+	homesCalls int
+	homeStatus map[string]*HomeStatusResponse
+	stations   *StationsDataResponse
+	legacy     *ThermostatsDataResponse
+	measure    map[string][]MeasureSample
+}
+
+func (c *fakeClient) HomesData(_ context.Context) (*HomesDataResponse, error) {
+	c.homesCalls++
+	if c.homesErr != nil {
+		return nil, c.homesErr
+	}
+
+	return c.homesData, nil
+}
+
+func (c *fakeClient) HomeStatus(_ context.Context, homeID string, _ []string) (*HomeStatusResponse, error) {
+	status, ok := c.homeStatus[homeID]
+	if !ok {
+		return nil, errNotImplemented
+	}
+
+	return status, nil
+}
+
+func (c *fakeClient) StationsData(_ context.Context) (*StationsDataResponse, error) {
+	if c.stations == nil {
+		return nil, errNotImplemented
+	}
+
+	return c.stations, nil
+}
+
+func (c *fakeClient) Measure(_ context.Context, params MeasureRequest) ([]MeasureSample, error) {
+	samples, ok := c.measure[params.ModuleID]
+	if !ok {
+		return nil, errNotImplemented
+	}
+
+	return samples, nil
+}
+
+func (c *fakeClient) PublicData(_ context.Context, _ BoundingBox) (*PublicDataResponse, error) {
+	return nil, errNotImplemented
+}
+
+func (c *fakeClient) SecurityHomeData(_ context.Context) (*SecurityHomeDataResponse, error) {
+	return nil, errNotImplemented
+}
+
+func (c *fakeClient) ThermostatsData(_ context.Context) (*ThermostatsDataResponse, error) {
+	if c.legacy == nil {
+		return nil, errNotImplemented
+	}
+
+	return c.legacy, nil
+}
+
+func mustDecode[T any](t *testing.T, data string) *T {
+	t.Helper()
+
+	var result T
+	if err := json.Unmarshal([]byte(data), &result); err != nil {
+		t.Fatalf("error decoding test data: %s", err)
+	}
+
+	return &result
+}
+
+func TestThermostatCollector_Collect(t *testing.T) {
+	testHomes := `{"body":{"homes":[{"id":"home","name":"Home","modules":[{"id":"relay","type":"NAPlug","name":"Relay"}]}]}}`
+	testStatus := `{"body":{"home":{"id":"home","therm_mode":"away","rooms":[
+		{"id":"room","name":"Living Room","therm_measured_temperature":20.5,"therm_setpoint_temperature":21}
+	],"modules":[
+		{"id":"relay","type":"NAPlug","boiler_status":true,"reachable":true},
+		{"id":"thermostat","type":"NATherm1","room_id":"room","therm_relay_cmd":100,"reachable":false}
+	]}}}`
+
+	tt := []struct {
+		desc        string
+		client      func(t *testing.T) *fakeClient
+		opts        []ThermostatOption
+		wantMetrics string
+	}{
+		{
+			desc: "success",
+			client: func(t *testing.T) *fakeClient {
+				return &fakeClient{
+					homesData: mustDecode[HomesDataResponse](t, testHomes),
+					homeStatus: map[string]*HomeStatusResponse{
+						"home": mustDecode[HomeStatusResponse](t, testStatus),
+					},
+				}
+			},
+			wantMetrics: `# HELP netatmo_boiler_cycles_total Netatmo Energy number of times the boiler of a home was observed switching on.
+# TYPE netatmo_boiler_cycles_total counter
+netatmo_boiler_cycles_total{home_id="home",home_name="Home"} 0
+# HELP netatmo_boiler_status Netatmo Energy boiler status (1=on, 0=off) reported by a single module. Homes with more than one boiler have one series per module.
+# TYPE netatmo_boiler_status gauge
+netatmo_boiler_status{home_id="home",home_name="Home",module_id="relay",module_name="Relay"} 1
+# HELP netatmo_collection_state Netatmo Energy outcome of the last collection. The series of the current state is set to 1, the others to 0.
+# TYPE netatmo_collection_state gauge
+netatmo_collection_state{state="auth_error"} 0
+netatmo_collection_state{state="network_error"} 0
+netatmo_collection_state{state="ok"} 1
+netatmo_collection_state{state="partial"} 0
+netatmo_collection_state{state="rate_limited"} 0
+# HELP netatmo_home_away Netatmo Energy away status of a home (1=therm_mode is "away", 0=any other mode).
+# TYPE netatmo_home_away gauge
+netatmo_home_away{home_id="home",home_name="Home"} 1
+# HELP netatmo_home_data_completeness Netatmo Energy fraction of the rooms of a home in homestatus which reported a measured temperature.
+# TYPE netatmo_home_data_completeness gauge
+netatmo_home_data_completeness{home_id="home",home_name="Home"} 1
+# HELP netatmo_home_heat_demand Netatmo Energy number of rooms of a home currently calling for heat.
+# TYPE netatmo_home_heat_demand gauge
+netatmo_home_heat_demand{home_id="home",home_name="Home"} 0
+# HELP netatmo_home_last_setpoint_change_seconds Netatmo Energy unix timestamp of the last change of the setpoint of any room of a home observed by the exporter. Before the first change the time the home was first collected is reported.
+# TYPE netatmo_home_last_setpoint_change_seconds gauge
+netatmo_home_last_setpoint_change_seconds{home_id="home",home_name="Home"} 1.7041104e+09
+# HELP netatmo_home_mode_changes_total Netatmo Energy number of changes of the therm_mode of a home observed by the exporter.
+# TYPE netatmo_home_mode_changes_total counter
+netatmo_home_mode_changes_total{home_id="home",home_name="Home"} 0
+# HELP netatmo_home_reachable Netatmo Energy reachability of a home (1=at least one module is reachable, 0=all modules are unreachable).
+# TYPE netatmo_home_reachable gauge
+netatmo_home_reachable{home_id="home",home_name="Home"} 1
+# HELP netatmo_home_schedules_total Netatmo Energy number of schedules of a home in homesdata, including schedules of other types than heating.
+# TYPE netatmo_home_schedules_total gauge
+netatmo_home_schedules_total{home_id="home",home_name="Home"} 0
+# HELP netatmo_home_unreachable_modules Netatmo Energy number of modules of a home which are not reachable.
+# TYPE netatmo_home_unreachable_modules gauge
+netatmo_home_unreachable_modules{home_id="home",home_name="Home"} 1
+# HELP netatmo_homes_processed Number of homes whose status was collected successfully during this scrape.
+# TYPE netatmo_homes_processed gauge
+netatmo_homes_processed 1
+# HELP netatmo_module_needs_attention Contains 1 if a module has a low battery, is unreachable or has a poor signal, 0 otherwise.
+# TYPE netatmo_module_needs_attention gauge
+netatmo_module_needs_attention{home_id="home",home_name="Home",module_id="relay",module_name="Relay"} 0
+netatmo_module_needs_attention{home_id="home",home_name="Home",module_id="thermostat",module_name=""} 1
+# HELP netatmo_module_type_code Contains a numeric code for the Netatmo type of a module, 0 if the type is unknown. The codes are listed in the README.
+# TYPE netatmo_module_type_code gauge
+netatmo_module_type_code{home_id="home",home_name="Home",module_id="relay",module_name="Relay"} 6
+netatmo_module_type_code{home_id="home",home_name="Home",module_id="thermostat",module_name=""} 7
+# HELP netatmo_room_heating_while_away Netatmo Energy set to 1 if a room calls for heat while its home is in away mode, 0 otherwise.
+# TYPE netatmo_room_heating_while_away gauge
+netatmo_room_heating_while_away{home_id="home",home_name="Home",room_id="room",room_name="Living Room"} 0
+# HELP netatmo_room_info Netatmo Energy room type from homesdata. The value is always 1.
+# TYPE netatmo_room_info gauge
+netatmo_room_info{home_id="home",home_name="Home",room_id="room",room_name="Living Room",room_type=""} 1
+# HELP netatmo_scrape_duration_seconds Netatmo Energy time it took to collect the thermostat metrics during this scrape.
+# TYPE netatmo_scrape_duration_seconds gauge
+netatmo_scrape_duration_seconds 0
+# HELP netatmo_scrape_errors_total Netatmo Energy number of failed requests during the collection of the thermostat metrics by phase of the collection.
+# TYPE netatmo_scrape_errors_total counter
+netatmo_scrape_errors_total{phase="homesdata"} 0
+netatmo_scrape_errors_total{phase="homestatus"} 0
+# HELP netatmo_setpoint_changes_total Netatmo Energy number of changes of the setpoint temperature of a room observed by the exporter.
+# TYPE netatmo_setpoint_changes_total counter
+netatmo_setpoint_changes_total{home_id="home",home_name="Home",room_id="room",room_name="Living Room"} 0
+# HELP netatmo_thermostat_boiler_status Netatmo Energy boiler status (1=on, 0=off). Depending on the boiler status mode per room (source="room") and/or per home (source="home").
+# TYPE netatmo_thermostat_boiler_status gauge
+netatmo_thermostat_boiler_status{home_id="home",home_name="Home",room_id="",room_name="",source="home"} 1
+# HELP netatmo_homes_discovered Number of homes returned by homesdata. Zero usually means that the token is missing the read_thermostat scope.
+# TYPE netatmo_homes_discovered gauge
+netatmo_homes_discovered 1
+# HELP netatmo_thermostat_home_status_concurrency Number of homes whose homestatus was requested at the same time during this scrape, limited by --home-status-concurrency and the number of collected homes.
+# TYPE netatmo_thermostat_home_status_concurrency gauge
+netatmo_thermostat_home_status_concurrency 1
+# HELP netatmo_thermostat_home_status_requests_in_flight Number of homestatus requests of the thermostat collector running at the end of the requests of this scrape, for example of concurrent scrapes.
+# TYPE netatmo_thermostat_home_status_requests_in_flight gauge
+netatmo_thermostat_home_status_requests_in_flight 0
+# HELP netatmo_thermostat_homes_from_cache Set to 1 if homesdata could not be fetched and the list of homes from a previous scrape was used, 0 otherwise.
+# TYPE netatmo_thermostat_homes_from_cache gauge
+netatmo_thermostat_homes_from_cache 0
+# HELP netatmo_thermostat_homesdata_skipped_homes Number of homes for which homesdata was not requested during this scrape, because the homes data interval has not passed yet.
+# TYPE netatmo_thermostat_homesdata_skipped_homes gauge
+netatmo_thermostat_homesdata_skipped_homes 0
+# HELP netatmo_thermostat_last_success_timestamp_seconds Netatmo Energy unix timestamp of the last successful homestatus request of a home. It is kept while the requests of the home fail.
+# TYPE netatmo_thermostat_last_success_timestamp_seconds gauge
+netatmo_thermostat_last_success_timestamp_seconds{home_id="home",home_name="Home"} 1.7041104e+09
+# HELP netatmo_thermostat_module_info Netatmo Energy type and firmware revision of a module from homestatus. The firmware revision is empty if the module does not report it. The value is always 1.
+# TYPE netatmo_thermostat_module_info gauge
+netatmo_thermostat_module_info{firmware_revision="",home_id="home",module_id="relay",type="NAPlug"} 1
+netatmo_thermostat_module_info{firmware_revision="",home_id="home",module_id="thermostat",type="NATherm1"} 1
+# HELP netatmo_thermostat_module_reachable Netatmo Energy reachability of a module (1=reachable, 0=unreachable).
+# TYPE netatmo_thermostat_module_reachable gauge
+netatmo_thermostat_module_reachable{home_id="home",home_name="Home",module_id="relay",room_id=""} 1
+netatmo_thermostat_module_reachable{home_id="home",home_name="Home",module_id="thermostat",room_id="room"} 0
+# HELP netatmo_thermostat_relay_cmd Netatmo Energy relay command of a classic thermostat (NATherm1) in percent (100=heating, 0=idle).
+# TYPE netatmo_thermostat_relay_cmd gauge
+netatmo_thermostat_relay_cmd{home_id="home",home_name="Home",room_id="room",room_name="Living Room"} 100
+# HELP netatmo_thermostat_setpoint Netatmo Energy target setpoint temperature in degrees Celsius.
+# TYPE netatmo_thermostat_setpoint gauge
+netatmo_thermostat_setpoint{home_id="home",home_name="Home",room_id="room",room_name="Living Room"} 21
+# HELP netatmo_thermostat_temperature Netatmo Energy measured room temperature in degrees Celsius.
+# TYPE netatmo_thermostat_temperature gauge
+netatmo_thermostat_temperature{home_id="home",home_name="Home",room_id="room",room_name="Living Room"} 20.5
+`,
+		},
+		{
+			desc: "dual units",
+			client: func(t *testing.T) *fakeClient {
+				return &fakeClient{
+					homesData: mustDecode[HomesDataResponse](t, `{"body":{"homes":[{"id":"home","name":"Home"}]}}`),
+					homeStatus: map[string]*HomeStatusResponse{
+						"home": mustDecode[HomeStatusResponse](t, `{"body":{"home":{"id":"home","rooms":[
+							{"id":"room","name":"Living Room","therm_measured_temperature":20}
+						]}}}`),
+					},
+				}
+			},
+			opts: []ThermostatOption{
+				WithDualUnits(true),
+			},
+			wantMetrics: `# HELP netatmo_collection_state Netatmo Energy outcome of the last collection. The series of the current state is set to 1, the others to 0.
+# TYPE netatmo_collection_state gauge
+netatmo_collection_state{state="auth_error"} 0
+netatmo_collection_state{state="network_error"} 0
+netatmo_collection_state{state="ok"} 1
+netatmo_collection_state{state="partial"} 0
+netatmo_collection_state{state="rate_limited"} 0
+# HELP netatmo_home_data_completeness Netatmo Energy fraction of the rooms of a home in homestatus which reported a measured temperature.
+# TYPE netatmo_home_data_completeness gauge
+netatmo_home_data_completeness{home_id="home",home_name="Home"} 1
+# HELP netatmo_home_heat_demand Netatmo Energy number of rooms of a home currently calling for heat.
+# TYPE netatmo_home_heat_demand gauge
+netatmo_home_heat_demand{home_id="home",home_name="Home"} 0
+# HELP netatmo_home_last_setpoint_change_seconds Netatmo Energy unix timestamp of the last change of the setpoint of any room of a home observed by the exporter. Before the first change the time the home was first collected is reported.
+# TYPE netatmo_home_last_setpoint_change_seconds gauge
+netatmo_home_last_setpoint_change_seconds{home_id="home",home_name="Home"} 1.7041104e+09
+# HELP netatmo_home_schedules_total Netatmo Energy number of schedules of a home in homesdata, including schedules of other types than heating.
+# TYPE netatmo_home_schedules_total gauge
+netatmo_home_schedules_total{home_id="home",home_name="Home"} 0
+# HELP netatmo_homes_discovered Number of homes returned by homesdata. Zero usually means that the token is missing the read_thermostat scope.
+# TYPE netatmo_homes_discovered gauge
+netatmo_homes_discovered 1
+# HELP netatmo_homes_processed Number of homes whose status was collected successfully during this scrape.
+# TYPE netatmo_homes_processed gauge
+netatmo_homes_processed 1
+# HELP netatmo_room_info Netatmo Energy room type from homesdata. The value is always 1.
+# TYPE netatmo_room_info gauge
+netatmo_room_info{home_id="home",home_name="Home",room_id="room",room_name="Living Room",room_type=""} 1
+# HELP netatmo_scrape_duration_seconds Netatmo Energy time it took to collect the thermostat metrics during this scrape.
+# TYPE netatmo_scrape_duration_seconds gauge
+netatmo_scrape_duration_seconds 0
+# HELP netatmo_scrape_errors_total Netatmo Energy number of failed requests during the collection of the thermostat metrics by phase of the collection.
+# TYPE netatmo_scrape_errors_total counter
+netatmo_scrape_errors_total{phase="homesdata"} 0
+netatmo_scrape_errors_total{phase="homestatus"} 0
+# HELP netatmo_thermostat_home_status_concurrency Number of homes whose homestatus was requested at the same time during this scrape, limited by --home-status-concurrency and the number of collected homes.
+# TYPE netatmo_thermostat_home_status_concurrency gauge
+netatmo_thermostat_home_status_concurrency 1
+# HELP netatmo_thermostat_home_status_requests_in_flight Number of homestatus requests of the thermostat collector running at the end of the requests of this scrape, for example of concurrent scrapes.
+# TYPE netatmo_thermostat_home_status_requests_in_flight gauge
+netatmo_thermostat_home_status_requests_in_flight 0
+# HELP netatmo_thermostat_homes_from_cache Set to 1 if homesdata could not be fetched and the list of homes from a previous scrape was used, 0 otherwise.
+# TYPE netatmo_thermostat_homes_from_cache gauge
+netatmo_thermostat_homes_from_cache 0
+# HELP netatmo_thermostat_homesdata_skipped_homes Number of homes for which homesdata was not requested during this scrape, because the homes data interval has not passed yet.
+# TYPE netatmo_thermostat_homesdata_skipped_homes gauge
+netatmo_thermostat_homesdata_skipped_homes 0
+# HELP netatmo_thermostat_last_success_timestamp_seconds Netatmo Energy unix timestamp of the last successful homestatus request of a home. It is kept while the requests of the home fail.
+# TYPE netatmo_thermostat_last_success_timestamp_seconds gauge
+netatmo_thermostat_last_success_timestamp_seconds{home_id="home",home_name="Home"} 1.7041104e+09
+# HELP netatmo_thermostat_temperature Netatmo Energy measured room temperature in degrees Celsius.
+# TYPE netatmo_thermostat_temperature gauge
+netatmo_thermostat_temperature{home_id="home",home_name="Home",room_id="room",room_name="Living Room"} 20
+# HELP netatmo_thermostat_temperature_fahrenheit Netatmo Energy measured room temperature in degrees Fahrenheit.
+# TYPE netatmo_thermostat_temperature_fahrenheit gauge
+netatmo_thermostat_temperature_fahrenheit{home_id="home",home_name="Home",room_id="room",room_name="Living Room"} 68
+`,
+		},
+		{
+			desc: "max mode",
+			client: func(t *testing.T) *fakeClient {
+				return &fakeClient{
+					homesData: mustDecode[HomesDataResponse](t, `{"body":{"homes":[{"id":"home","name":"Home"}]}}`),
+					homeStatus: map[string]*HomeStatusResponse{
+						"home": mustDecode[HomeStatusResponse](t, `{"body":{"home":{"id":"home","rooms":[
+							{"id":"room","name":"Bathroom","therm_setpoint_temperature":30,"therm_setpoint_mode":"max"}
+						]}}}`),
+					},
+				}
+			},
+			wantMetrics: `# HELP netatmo_collection_state Netatmo Energy outcome of the last collection. The series of the current state is set to 1, the others to 0.
+# TYPE netatmo_collection_state gauge
+netatmo_collection_state{state="auth_error"} 0
+netatmo_collection_state{state="network_error"} 0
+netatmo_collection_state{state="ok"} 1
+netatmo_collection_state{state="partial"} 0
+netatmo_collection_state{state="rate_limited"} 0
+# HELP netatmo_home_data_completeness Netatmo Energy fraction of the rooms of a home in homestatus which reported a measured temperature.
+# TYPE netatmo_home_data_completeness gauge
+netatmo_home_data_completeness{home_id="home",home_name="Home"} 0
+# HELP netatmo_home_heat_demand Netatmo Energy number of rooms of a home currently calling for heat.
+# TYPE netatmo_home_heat_demand gauge
+netatmo_home_heat_demand{home_id="home",home_name="Home"} 0
+# HELP netatmo_home_last_setpoint_change_seconds Netatmo Energy unix timestamp of the last change of the setpoint of any room of a home observed by the exporter. Before the first change the time the home was first collected is reported.
+# TYPE netatmo_home_last_setpoint_change_seconds gauge
+netatmo_home_last_setpoint_change_seconds{home_id="home",home_name="Home"} 1.7041104e+09
+# HELP netatmo_home_schedules_total Netatmo Energy number of schedules of a home in homesdata, including schedules of other types than heating.
+# TYPE netatmo_home_schedules_total gauge
+netatmo_home_schedules_total{home_id="home",home_name="Home"} 0
+# HELP netatmo_homes_discovered Number of homes returned by homesdata. Zero usually means that the token is missing the read_thermostat scope.
+# TYPE netatmo_homes_discovered gauge
+netatmo_homes_discovered 1
+# HELP netatmo_homes_processed Number of homes whose status was collected successfully during this scrape.
+# TYPE netatmo_homes_processed gauge
+netatmo_homes_processed 1
+# HELP netatmo_room_info Netatmo Energy room type from homesdata. The value is always 1.
+# TYPE netatmo_room_info gauge
+netatmo_room_info{home_id="home",home_name="Home",room_id="room",room_name="Bathroom",room_type=""} 1
+# HELP netatmo_room_max_mode_active Netatmo Energy max mode of a room (1=setpoint mode is "max", 0=any other mode). The setpoint contains the maximum temperature while max mode is active.
+# TYPE netatmo_room_max_mode_active gauge
+netatmo_room_max_mode_active{home_id="home",home_name="Home",room_id="room",room_name="Bathroom"} 1
+# HELP netatmo_scrape_duration_seconds Netatmo Energy time it took to collect the thermostat metrics during this scrape.
+# TYPE netatmo_scrape_duration_seconds gauge
+netatmo_scrape_duration_seconds 0
+# HELP netatmo_scrape_errors_total Netatmo Energy number of failed requests during the collection of the thermostat metrics by phase of the collection.
+# TYPE netatmo_scrape_errors_total counter
+netatmo_scrape_errors_total{phase="homesdata"} 0
+netatmo_scrape_errors_total{phase="homestatus"} 0
+# HELP netatmo_setpoint_changes_total Netatmo Energy number of changes of the setpoint temperature of a room observed by the exporter.
+# TYPE netatmo_setpoint_changes_total counter
+netatmo_setpoint_changes_total{home_id="home",home_name="Home",room_id="room",room_name="Bathroom"} 0
+# HELP netatmo_thermostat_home_status_concurrency Number of homes whose homestatus was requested at the same time during this scrape, limited by --home-status-concurrency and the number of collected homes.
+# TYPE netatmo_thermostat_home_status_concurrency gauge
+netatmo_thermostat_home_status_concurrency 1
+# HELP netatmo_thermostat_home_status_requests_in_flight Number of homestatus requests of the thermostat collector running at the end of the requests of this scrape, for example of concurrent scrapes.
+# TYPE netatmo_thermostat_home_status_requests_in_flight gauge
+netatmo_thermostat_home_status_requests_in_flight 0
+# HELP netatmo_thermostat_homes_from_cache Set to 1 if homesdata could not be fetched and the list of homes from a previous scrape was used, 0 otherwise.
+# TYPE netatmo_thermostat_homes_from_cache gauge
+netatmo_thermostat_homes_from_cache 0
+# HELP netatmo_thermostat_homesdata_skipped_homes Number of homes for which homesdata was not requested during this scrape, because the homes data interval has not passed yet.
+# TYPE netatmo_thermostat_homesdata_skipped_homes gauge
+netatmo_thermostat_homesdata_skipped_homes 0
+# HELP netatmo_thermostat_last_success_timestamp_seconds Netatmo Energy unix timestamp of the last successful homestatus request of a home. It is kept while the requests of the home fail.
+# TYPE netatmo_thermostat_last_success_timestamp_seconds gauge
+netatmo_thermostat_last_success_timestamp_seconds{home_id="home",home_name="Home"} 1.7041104e+09
+# HELP netatmo_thermostat_setpoint Netatmo Energy target setpoint temperature in degrees Celsius.
+# TYPE netatmo_thermostat_setpoint gauge
+netatmo_thermostat_setpoint{home_id="home",home_name="Home",room_id="room",room_name="Bathroom"} 30
+# HELP netatmo_thermostat_setpoint_mode Netatmo Energy setpoint mode of a room. The series of the current mode is set to 1, the others to 0.
+# TYPE netatmo_thermostat_setpoint_mode gauge
+netatmo_thermostat_setpoint_mode{home_id="home",home_name="Home",mode="away",room_id="room",room_name="Bathroom"} 0
+netatmo_thermostat_setpoint_mode{home_id="home",home_name="Home",mode="hg",room_id="room",room_name="Bathroom"} 0
+netatmo_thermostat_setpoint_mode{home_id="home",home_name="Home",mode="home",room_id="room",room_name="Bathroom"} 0
+netatmo_thermostat_setpoint_mode{home_id="home",home_name="Home",mode="manual",room_id="room",room_name="Bathroom"} 0
+netatmo_thermostat_setpoint_mode{home_id="home",home_name="Home",mode="max",room_id="room",room_name="Bathroom"} 1
+netatmo_thermostat_setpoint_mode{home_id="home",home_name="Home",mode="off",room_id="room",room_name="Bathroom"} 0
+netatmo_thermostat_setpoint_mode{home_id="home",home_name="Home",mode="schedule",room_id="room",room_name="Bathroom"} 0
+`,
+		},
+		{
+			desc: "collect timeout",
+			client: func(t *testing.T) *fakeClient {
+				return &fakeClient{
+					homesData: mustDecode[HomesDataResponse](t, `{"body":{"homes":[{"id":"home-a","name":"A"},{"id":"home-b","name":"B"}]}}`),
+					homeStatus: map[string]*HomeStatusResponse{
+						"home-a": mustDecode[HomeStatusResponse](t, `{"body":{"home":{"id":"home-a","rooms":[{"id":"room","therm_measured_temperature":20}]}}}`),
+						"home-b": mustDecode[HomeStatusResponse](t, `{"body":{"home":{"id":"home-b","rooms":[{"id":"room","therm_measured_temperature":18}]}}}`),
+					},
+				}
+			},
+			opts: []ThermostatOption{
+				WithCollectTimeout(20 * time.Millisecond),
+				WithHomeStatusDelay(time.Minute),
+			},
+			wantMetrics: `# HELP netatmo_collection_state Netatmo Energy outcome of the last collection. The series of the current state is set to 1, the others to 0.
+# TYPE netatmo_collection_state gauge
+netatmo_collection_state{state="auth_error"} 0
+netatmo_collection_state{state="network_error"} 0
+netatmo_collection_state{state="ok"} 0
+netatmo_collection_state{state="partial"} 1
+netatmo_collection_state{state="rate_limited"} 0
+# HELP netatmo_home_data_completeness Netatmo Energy fraction of the rooms of a home in homestatus which reported a measured temperature.
+# TYPE netatmo_home_data_completeness gauge
+netatmo_home_data_completeness{home_id="home-a",home_name="A"} 1
+# HELP netatmo_home_heat_demand Netatmo Energy number of rooms of a home currently calling for heat.
+# TYPE netatmo_home_heat_demand gauge
+netatmo_home_heat_demand{home_id="home-a",home_name="A"} 0
+# HELP netatmo_home_last_setpoint_change_seconds Netatmo Energy unix timestamp of the last change of the setpoint of any room of a home observed by the exporter. Before the first change the time the home was first collected is reported.
+# TYPE netatmo_home_last_setpoint_change_seconds gauge
+netatmo_home_last_setpoint_change_seconds{home_id="home-a",home_name="A"} 1.7041104e+09
+# HELP netatmo_home_schedules_total Netatmo Energy number of schedules of a home in homesdata, including schedules of other types than heating.
+# TYPE netatmo_home_schedules_total gauge
+netatmo_home_schedules_total{home_id="home-a",home_name="A"} 0
+# HELP netatmo_homes_discovered Number of homes returned by homesdata. Zero usually means that the token is missing the read_thermostat scope.
+# TYPE netatmo_homes_discovered gauge
+netatmo_homes_discovered 2
+# HELP netatmo_homes_processed Number of homes whose status was collected successfully during this scrape.
+# TYPE netatmo_homes_processed gauge
+netatmo_homes_processed 1
+# HELP netatmo_room_info Netatmo Energy room type from homesdata. The value is always 1.
+# TYPE netatmo_room_info gauge
+netatmo_room_info{home_id="home-a",home_name="A",room_id="room",room_name="id-room",room_type=""} 1
+# HELP netatmo_scrape_duration_seconds Netatmo Energy time it took to collect the thermostat metrics during this scrape.
+# TYPE netatmo_scrape_duration_seconds gauge
+netatmo_scrape_duration_seconds 0
+# HELP netatmo_scrape_errors_total Netatmo Energy number of failed requests during the collection of the thermostat metrics by phase of the collection.
+# TYPE netatmo_scrape_errors_total counter
+netatmo_scrape_errors_total{phase="homesdata"} 0
+netatmo_scrape_errors_total{phase="homestatus"} 0
+# HELP netatmo_scrape_timed_out Set to 1 if the collect timeout was reached before the status of all homes was collected, 0 otherwise. The metrics of the homes collected before the timeout are still reported.
+# TYPE netatmo_scrape_timed_out gauge
+netatmo_scrape_timed_out 1
+# HELP netatmo_thermostat_home_status_concurrency Number of homes whose homestatus was requested at the same time during this scrape, limited by --home-status-concurrency and the number of collected homes.
+# TYPE netatmo_thermostat_home_status_concurrency gauge
+netatmo_thermostat_home_status_concurrency 2
+# HELP netatmo_thermostat_home_status_requests_in_flight Number of homestatus requests of the thermostat collector running at the end of the requests of this scrape, for example of concurrent scrapes.
+# TYPE netatmo_thermostat_home_status_requests_in_flight gauge
+netatmo_thermostat_home_status_requests_in_flight 0
+# HELP netatmo_thermostat_homes_from_cache Set to 1 if homesdata could not be fetched and the list of homes from a previous scrape was used, 0 otherwise.
+# TYPE netatmo_thermostat_homes_from_cache gauge
+netatmo_thermostat_homes_from_cache 0
+# HELP netatmo_thermostat_homesdata_skipped_homes Number of homes for which homesdata was not requested during this scrape, because the homes data interval has not passed yet.
+# TYPE netatmo_thermostat_homesdata_skipped_homes gauge
+netatmo_thermostat_homesdata_skipped_homes 0
+# HELP netatmo_thermostat_last_success_timestamp_seconds Netatmo Energy unix timestamp of the last successful homestatus request of a home. It is kept while the requests of the home fail.
+# TYPE netatmo_thermostat_last_success_timestamp_seconds gauge
+netatmo_thermostat_last_success_timestamp_seconds{home_id="home-a",home_name="A"} 1.7041104e+09
+# HELP netatmo_thermostat_temperature Netatmo Energy measured room temperature in degrees Celsius.
+# TYPE netatmo_thermostat_temperature gauge
+netatmo_thermostat_temperature{home_id="home-a",home_name="A",room_id="room",room_name="id-room"} 20
+`,
+		},
+		{
+			desc: "excluded home",
+			client: func(t *testing.T) *fakeClient {
+				return &fakeClient{
+					homesData: mustDecode[HomesDataResponse](t, testHomes),
+				}
+			},
+			opts: []ThermostatOption{
+				WithExcludedHomes(regexp.MustCompile("^Ho")),
+			},
+			wantMetrics: `# HELP netatmo_collection_state Netatmo Energy outcome of the last collection. The series of the current state is set to 1, the others to 0.
+# TYPE netatmo_collection_state gauge
+netatmo_collection_state{state="auth_error"} 0
+netatmo_collection_state{state="network_error"} 0
+netatmo_collection_state{state="ok"} 1
+netatmo_collection_state{state="partial"} 0
+netatmo_collection_state{state="rate_limited"} 0
+# HELP netatmo_homes_discovered Number of homes returned by homesdata. Zero usually means that the token is missing the read_thermostat scope.
+# TYPE netatmo_homes_discovered gauge
+netatmo_homes_discovered 1
+# HELP netatmo_homes_processed Number of homes whose status was collected successfully during this scrape.
+# TYPE netatmo_homes_processed gauge
+netatmo_homes_processed 0
+# HELP netatmo_scrape_duration_seconds Netatmo Energy time it took to collect the thermostat metrics during this scrape.
+# TYPE netatmo_scrape_duration_seconds gauge
+netatmo_scrape_duration_seconds 0
+# HELP netatmo_scrape_errors_total Netatmo Energy number of failed requests during the collection of the thermostat metrics by phase of the collection.
+# TYPE netatmo_scrape_errors_total counter
+netatmo_scrape_errors_total{phase="homesdata"} 0
+netatmo_scrape_errors_total{phase="homestatus"} 0
+# HELP netatmo_thermostat_home_status_concurrency Number of homes whose homestatus was requested at the same time during this scrape, limited by --home-status-concurrency and the number of collected homes.
+# TYPE netatmo_thermostat_home_status_concurrency gauge
+netatmo_thermostat_home_status_concurrency 0
+# HELP netatmo_thermostat_home_status_requests_in_flight Number of homestatus requests of the thermostat collector running at the end of the requests of this scrape, for example of concurrent scrapes.
+# TYPE netatmo_thermostat_home_status_requests_in_flight gauge
+netatmo_thermostat_home_status_requests_in_flight 0
+# HELP netatmo_thermostat_homes_from_cache Set to 1 if homesdata could not be fetched and the list of homes from a previous scrape was used, 0 otherwise.
+# TYPE netatmo_thermostat_homes_from_cache gauge
+netatmo_thermostat_homes_from_cache 0
+# HELP netatmo_thermostat_homesdata_skipped_homes Number of homes for which homesdata was not requested during this scrape, because the homes data interval has not passed yet.
+# TYPE netatmo_thermostat_homesdata_skipped_homes gauge
+netatmo_thermostat_homesdata_skipped_homes 0
+`,
+		},
+		{
+			desc: "no homes",
+			client: func(t *testing.T) *fakeClient {
+				return &fakeClient{
+					homesData: mustDecode[HomesDataResponse](t, `{"body":{"homes":[]}}`),
+				}
+			},
+			wantMetrics: `# HELP netatmo_collection_state Netatmo Energy outcome of the last collection. The series of the current state is set to 1, the others to 0.
+# TYPE netatmo_collection_state gauge
+netatmo_collection_state{state="auth_error"} 0
+netatmo_collection_state{state="network_error"} 0
+netatmo_collection_state{state="ok"} 1
+netatmo_collection_state{state="partial"} 0
+netatmo_collection_state{state="rate_limited"} 0
+# HELP netatmo_homes_discovered Number of homes returned by homesdata. Zero usually means that the token is missing the read_thermostat scope.
+# TYPE netatmo_homes_discovered gauge
+netatmo_homes_discovered 0
+# HELP netatmo_homes_processed Number of homes whose status was collected successfully during this scrape.
+# TYPE netatmo_homes_processed gauge
+netatmo_homes_processed 0
+# HELP netatmo_scrape_duration_seconds Netatmo Energy time it took to collect the thermostat metrics during this scrape.
+# TYPE netatmo_scrape_duration_seconds gauge
+netatmo_scrape_duration_seconds 0
+# HELP netatmo_scrape_errors_total Netatmo Energy number of failed requests during the collection of the thermostat metrics by phase of the collection.
+# TYPE netatmo_scrape_errors_total counter
+netatmo_scrape_errors_total{phase="homesdata"} 0
+netatmo_scrape_errors_total{phase="homestatus"} 0
+# HELP netatmo_thermostat_home_status_concurrency Number of homes whose homestatus was requested at the same time during this scrape, limited by --home-status-concurrency and the number of collected homes.
+# TYPE netatmo_thermostat_home_status_concurrency gauge
+netatmo_thermostat_home_status_concurrency 0
+# HELP netatmo_thermostat_home_status_requests_in_flight Number of homestatus requests of the thermostat collector running at the end of the requests of this scrape, for example of concurrent scrapes.
+# TYPE netatmo_thermostat_home_status_requests_in_flight gauge
+netatmo_thermostat_home_status_requests_in_flight 0
+# HELP netatmo_thermostat_homes_from_cache Set to 1 if homesdata could not be fetched and the list of homes from a previous scrape was used, 0 otherwise.
+# TYPE netatmo_thermostat_homes_from_cache gauge
+netatmo_thermostat_homes_from_cache 0
+# HELP netatmo_thermostat_homesdata_skipped_homes Number of homes for which homesdata was not requested during this scrape, because the homes data interval has not passed yet.
+# TYPE netatmo_thermostat_homesdata_skipped_homes gauge
+netatmo_thermostat_homesdata_skipped_homes 0
+`,
+		},
+		{
+			desc: "no token",
+			client: func(_ *testing.T) *fakeClient {
+				return &fakeClient{
+					homesErr: ErrNoToken,
+				}
+			},
+			wantMetrics: `# HELP netatmo_collection_state Netatmo Energy outcome of the last collection. The series of the current state is set to 1, the others to 0.
+# TYPE netatmo_collection_state gauge
+netatmo_collection_state{state="auth_error"} 1
+netatmo_collection_state{state="network_error"} 0
+netatmo_collection_state{state="ok"} 0
+netatmo_collection_state{state="partial"} 0
+netatmo_collection_state{state="rate_limited"} 0
+# HELP netatmo_scrape_duration_seconds Netatmo Energy time it took to collect the thermostat metrics during this scrape.
+# TYPE netatmo_scrape_duration_seconds gauge
+netatmo_scrape_duration_seconds 0
+# HELP netatmo_scrape_errors_total Netatmo Energy number of failed requests during the collection of the thermostat metrics by phase of the collection.
+# TYPE netatmo_scrape_errors_total counter
+netatmo_scrape_errors_total{phase="homesdata"} 0
+netatmo_scrape_errors_total{phase="homestatus"} 0
+`,
+		},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			c := NewThermostatCollector(logrus.New(), tc.client(t), tc.opts...)
+			c.clock = func() time.Time {
+				return time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+			}
+
+			if err := testutil.CollectAndCompare(c, strings.NewReader(tc.wantMetrics)); err != nil {
+				t.Errorf("metrics differ: %s", err)
+			}
+		})
+	}
+}
+
+func TestThermostatCollector_BoilerStatusMode(t *testing.T) {
+	testHomes := `{"body":{"homes":[{"id":"home","name":"Home"}]}}`
+	testStatus := `{"body":{"home":{"id":"home","rooms":[
+		{"id":"living","name":"Living Room","heating_power_request":40},
+		{"id":"bedroom","name":"Bedroom","heating_power_request":0},
+		{"id":"hall","name":"Hall"}
+	],"modules":[
+		{"id":"relay","type":"NAPlug","boiler_status":true},
+		{"id":"thermostat","type":"NATherm1","room_id":"hall","boiler_status":false}
+	]}}}`
+
+	tt := []struct {
+		mode        BoilerStatusMode
+		wantMetrics string
+	}{
+		{
+			mode: BoilerStatusMixed,
+			wantMetrics: `# HELP netatmo_thermostat_boiler_status Netatmo Energy boiler status (1=on, 0=off). Depending on the boiler status mode per room (source="room") and/or per home (source="home").
+# TYPE netatmo_thermostat_boiler_status gauge
+netatmo_thermostat_boiler_status{home_id="home",home_name="Home",room_id="",room_name="",source="home"} 1
+netatmo_thermostat_boiler_status{home_id="home",home_name="Home",room_id="hall",room_name="Hall",source="room"} 0
+`,
+		},
+		{
+			mode: BoilerStatusRoom,
+			wantMetrics: `# HELP netatmo_thermostat_boiler_status Netatmo Energy boiler status (1=on, 0=off). Depending on the boiler status mode per room (source="room") and/or per home (source="home").
+# TYPE netatmo_thermostat_boiler_status gauge
+netatmo_thermostat_boiler_status{home_id="home",home_name="Home",room_id="bedroom",room_name="Bedroom",source="room"} 0
+netatmo_thermostat_boiler_status{home_id="home",home_name="Home",room_id="living",room_name="Living Room",source="room"} 1
+`,
+		},
+		{
+			mode: BoilerStatusBoiler,
+			wantMetrics: `# HELP netatmo_thermostat_boiler_status Netatmo Energy boiler status (1=on, 0=off). Depending on the boiler status mode per room (source="room") and/or per home (source="home").
+# TYPE netatmo_thermostat_boiler_status gauge
+netatmo_thermostat_boiler_status{home_id="home",home_name="Home",room_id="",room_name="",source="home"} 1
+`,
+		},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(string(tc.mode), func(t *testing.T) {
+			t.Parallel()
+
+			client := &fakeClient{
+				homesData: mustDecode[HomesDataResponse](t, testHomes),
+				homeStatus: map[string]*HomeStatusResponse{
+					"home": mustDecode[HomeStatusResponse](t, testStatus),
+				},
+			}
+			c := NewThermostatCollector(logrus.New(), client, WithBoilerStatusMode(tc.mode))
+
+			if err := testutil.CollectAndCompare(c, strings.NewReader(tc.wantMetrics), "netatmo_thermostat_boiler_status"); err != nil {
+				t.Errorf("metrics differ: %s", err)
+			}
+		})
+	}
+}
+
+func TestThermostatCollector_MultipleBoilers(t *testing.T) {
+	client := &fakeClient{
+		homesData: mustDecode[HomesDataResponse](t, `{"body":{"homes":[{"id":"home","name":"Home"}]}}`),
+		homeStatus: map[string]*HomeStatusResponse{
+			"home": mustDecode[HomeStatusResponse](t, `{"body":{"home":{"id":"home","rooms":[
+				{"id":"living","name":"Living Room"}
+			],"modules":[
+				{"id":"relay-1","type":"NAPlug"},
+				{"id":"relay-2","type":"NAPlug"},
+				{"id":"thermostat-1","type":"NATherm1","bridge":"relay-1","room_id":"living","boiler_status":true},
+				{"id":"thermostat-2","type":"NATherm1","bridge":"relay-2","room_id":"living","boiler_status":false}
+			]}}}`),
+		},
+	}
+	c := NewThermostatCollector(logrus.New(), client)
+
+	want := `# HELP netatmo_boiler_status Netatmo Energy boiler status (1=on, 0=off) reported by a single module. Homes with more than one boiler have one series per module.
+# TYPE netatmo_boiler_status gauge
+netatmo_boiler_status{home_id="home",home_name="Home",module_id="thermostat-1",module_name=""} 1
+netatmo_boiler_status{home_id="home",home_name="Home",module_id="thermostat-2",module_name=""} 0
+# HELP netatmo_thermostat_boiler_status Netatmo Energy boiler status (1=on, 0=off). Depending on the boiler status mode per room (source="room") and/or per home (source="home").
+# TYPE netatmo_thermostat_boiler_status gauge
+netatmo_thermostat_boiler_status{home_id="home",home_name="Home",room_id="",room_name="",source="home"} 1
+netatmo_thermostat_boiler_status{home_id="home",home_name="Home",room_id="living",room_name="Living Room",source="room"} 1
+`
+	if err := testutil.CollectAndCompare(c, strings.NewReader(want), "netatmo_boiler_status", "netatmo_thermostat_boiler_status"); err != nil {
+		t.Errorf("metrics differ: %s", err)
+	}
+}
+
+func TestThermostatCollector_Describe(t *testing.T) {
+	tt := []struct {
+		desc     string
+		opts     []ThermostatOption
+		wantDesc []string
+		skipDesc []string
+	}{
+		{
+			desc:     "defaults",
+			wantDesc: []string{"netatmo_thermostat_temperature", "netatmo_homes_discovered"},
+			skipDesc: []string{"netatmo_thermostat_temperature_fahrenheit", "netatmo_room_comfort_score", "netatmo_boiler_on_seconds_total", "netatmo_boiler_duty_cycle_ratio"},
+		},
+		{
+			desc: "all enabled",
+			opts: []ThermostatOption{
+				WithDualUnits(true),
+				WithComfortScore(true),
+				WithBoilerOnInterval(time.Hour),
+				WithBoilerSampleInterval(time.Minute),
+			},
+			wantDesc: []string{"netatmo_thermostat_temperature_fahrenheit", "netatmo_room_comfort_score", "netatmo_boiler_on_seconds_total", "netatmo_boiler_duty_cycle_ratio"},
+		},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			c := NewThermostatCollector(logrus.New(), nil, tc.opts...)
+			names := describedNames(c)
+
+			for _, name := range tc.wantDesc {
+				if !names[name] {
+					t.Errorf("descriptor %s not described", name)
+				}
+			}
+
+			for _, name := range tc.skipDesc {
+				if names[name] {
+					t.Errorf("descriptor %s described but not enabled", name)
+				}
+			}
+		})
+	}
+}
+
+// describedNames returns the fully-qualified names of all descriptors sent by the Describe method of c.
+func describedNames(c prometheus.Collector) map[string]bool {
+	ch := make(chan *prometheus.Desc)
+	go func() {
+		c.Describe(ch)
+		close(ch)
+	}()
+
+	names := map[string]bool{}
+	for desc := range ch {
+		def, _ := definition(desc)
+		names[def.fqName()] = true
+	}
+
+	return names
+}
+
+func TestThermostatCollector_RemovedDevice(t *testing.T) {
+	client := &fakeClient{
+		homesData: mustDecode[HomesDataResponse](t, `{"body":{"homes":[{"id":"home","name":"Home","modules":[
+			{"id":"relay","type":"NAPlug","name":"Relay"},
+			{"id":"valve","type":"NRV","name":"Valve","bridge":"relay"}
+		]}]}}`),
+		homeStatus: map[string]*HomeStatusResponse{
+			"home": mustDecode[HomeStatusResponse](t, `{"body":{"home":{"id":"home","modules":[
+				{"id":"relay","type":"NAPlug","boiler_status":false},
+				{"id":"valve","type":"NRV","boiler_status":true}
+			]}}}`),
+		},
+	}
+	c := NewThermostatCollector(logrus.New(), client)
+
+	wantBefore := `# HELP netatmo_boiler_status Netatmo Energy boiler status (1=on, 0=off) reported by a single module. Homes with more than one boiler have one series per module.
+# TYPE netatmo_boiler_status gauge
+netatmo_boiler_status{home_id="home",home_name="Home",module_id="relay",module_name="Relay"} 0
+netatmo_boiler_status{home_id="home",home_name="Home",module_id="valve",module_name="Valve"} 1
+`
+	if err := testutil.CollectAndCompare(c, strings.NewReader(wantBefore), "netatmo_boiler_status"); err != nil {
+		t.Errorf("metrics before removal differ: %s", err)
+	}
+
+	// The device vanishes from homestatus, while homesdata fails and the outdated list of modules is used.
+	client.homesErr = errors.New("test error")
+	client.homeStatus["home"] = mustDecode[HomeStatusResponse](t, `{"body":{"home":{"id":"home","modules":[
+		{"id":"relay","type":"NAPlug","boiler_status":false}
+	]}}}`)
+
+	wantAfter := `# HELP netatmo_boiler_status Netatmo Energy boiler status (1=on, 0=off) reported by a single module. Homes with more than one boiler have one series per module.
+# TYPE netatmo_boiler_status gauge
+netatmo_boiler_status{home_id="home",home_name="Home",module_id="relay",module_name="Relay"} 0
+# HELP netatmo_thermostat_boiler_status Netatmo Energy boiler status (1=on, 0=off). Depending on the boiler status mode per room (source="room") and/or per home (source="home").
+# TYPE netatmo_thermostat_boiler_status gauge
+netatmo_thermostat_boiler_status{home_id="home",home_name="Home",room_id="",room_name="",source="home"} 0
+`
+	if err := testutil.CollectAndCompare(c, strings.NewReader(wantAfter), "netatmo_boiler_status", "netatmo_thermostat_boiler_status"); err != nil {
+		t.Errorf("metrics after removal differ: %s", err)
+	}
+}
+
+func TestThermostatCollector_HomesDataInterval(t *testing.T) {
+	client := &fakeClient{
+		homesData: mustDecode[HomesDataResponse](t, `{"body":{"homes":[{"id":"home-a","name":"A"},{"id":"home-b","name":"B"}]}}`),
+	}
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	c := NewThermostatCollector(logrus.New(), client, WithHomesDataInterval(time.Hour))
+	c.clock = func() time.Time {
+		return now
+	}
+
+	tt := []struct {
+		desc        string
+		advance     time.Duration
+		wantCalls   int
+		wantSkipped string
+	}{
+		{
+			desc:        "first scrape",
+			wantCalls:   1,
+			wantSkipped: "0",
+		},
+		{
+			desc:        "within interval",
+			advance:     30 * time.Minute,
+			wantCalls:   1,
+			wantSkipped: "2",
+		},
+		{
+			desc:        "interval passed",
+			advance:     30 * time.Minute,
+			wantCalls:   2,
+			wantSkipped: "0",
+		},
+	}
+
+	for _, tc := range tt {
+		now = now.Add(tc.advance)
+
+		want := `# HELP netatmo_thermostat_homesdata_skipped_homes Number of homes for which homesdata was not requested during this scrape, because the homes data interval has not passed yet.
+# TYPE netatmo_thermostat_homesdata_skipped_homes gauge
+netatmo_thermostat_homesdata_skipped_homes ` + tc.wantSkipped + "\n"
+		if err := testutil.CollectAndCompare(c, strings.NewReader(want), "netatmo_thermostat_homesdata_skipped_homes"); err != nil {
+			t.Errorf("%s: metrics differ: %s", tc.desc, err)
+		}
+
+		if client.homesCalls != tc.wantCalls {
+			t.Errorf("%s: got %d homesdata requests, want %d", tc.desc, client.homesCalls, tc.wantCalls)
+		}
+	}
+}
+
+// fakePublisher implements StatePublisher by keeping the last published state.
+type fakePublisher struct {
+	homes []ThermostatState
+}
+
+func (p *fakePublisher) Publish(homes []ThermostatState) {
+	p.homes = homes
+}
+
+func TestThermostatCollector_StatePublisher(t *testing.T) {
+	client := &fakeClient{
+		homesData: mustDecode[HomesDataResponse](t, `{"body":{"homes":[{"id":"home","name":"Home"}]}}`),
+		homeStatus: map[string]*HomeStatusResponse{
+			"home": mustDecode[HomeStatusResponse](t, `{"body":{"home":{"id":"home","rooms":[
+				{"id":"living","name":"Living Room","therm_measured_temperature":20.5,"therm_setpoint_temperature":21},
+				{"id":"bath"}
+			],"modules":[
+				{"id":"relay","type":"NAPlug","boiler_status":true},
+				{"id":"thermostat","type":"NATherm1","room_id":"living","boiler_status":false}
+			]}}}`),
+		},
+	}
+	publisher := &fakePublisher{}
+	c := NewThermostatCollector(logrus.New(), client, WithStatePublisher(publisher))
+
+	testutil.CollectAndCount(c)
+
+	floatPtr := func(f float64) *float64 {
+		return &f
+	}
+	want := []ThermostatState{
+		{
+			HomeID:       "home",
+			HomeName:     "Home",
+			BoilerStatus: floatPtr(1),
+			Rooms: []RoomState{
+				{
+					ID:           "living",
+					Name:         "Living Room",
+					Temperature:  floatPtr(20.5),
+					Setpoint:     floatPtr(21),
+					BoilerStatus: floatPtr(0),
+				},
+				{
+					ID:   "bath",
+					Name: "id-bath",
+				},
+			},
+		},
+	}
+	if diff := cmp.Diff(want, publisher.homes); diff != "" {
+		t.Errorf("published state differs: -want +got\n%s", diff)
+	}
+}
+
+func TestThermostatCollector_Home(t *testing.T) {
+	client := &fakeClient{
+		homesData: mustDecode[HomesDataResponse](t, `{"body":{"homes":[{"id":"house","name":"House"},{"id":"cabin","name":"Cabin"}]}}`),
+		homeStatus: map[string]*HomeStatusResponse{
+			"house": mustDecode[HomeStatusResponse](t, `{"body":{"home":{"id":"house","therm_mode":"schedule"}}}`),
+			"cabin": mustDecode[HomeStatusResponse](t, `{"body":{"home":{"id":"cabin","therm_mode":"away"}}}`),
+		},
+	}
+	publisher := &fakePublisher{}
+	c := NewThermostatCollector(logrus.New(), client, WithMaxHomes(1), WithStatePublisher(publisher))
+
+	want := `# HELP netatmo_home_away Netatmo Energy away status of a home (1=therm_mode is "away", 0=any other mode).
+# TYPE netatmo_home_away gauge
+netatmo_home_away{home_id="cabin",home_name="Cabin"} 1
+# HELP netatmo_homes_discovered Number of homes returned by homesdata. Zero usually means that the token is missing the read_thermostat scope.
+# TYPE netatmo_homes_discovered gauge
+netatmo_homes_discovered 2
+# HELP netatmo_homes_processed Number of homes whose status was collected successfully during this scrape.
+# TYPE netatmo_homes_processed gauge
+netatmo_homes_processed 1
+`
+	if err := testutil.CollectAndCompare(c.Home("cabin"), strings.NewReader(want), "netatmo_home_away", "netatmo_homes_discovered", "netatmo_homes_processed"); err != nil {
+		t.Errorf("metrics differ: %s", err)
+	}
+
+	if publisher.homes != nil {
+		t.Errorf("got published state %v, want none", publisher.homes)
+	}
+
+	// Collecting a single home does not change the homes selected by the next scrape.
+	if got := c.selectHomes(client.homesData.Body.Homes); got[0].ID != "house" {
+		t.Errorf("got selected home %q, want %q", got[0].ID, "house")
+	}
+}
+
+func TestThermostatCollector_SelectHomes(t *testing.T) {
+	homes := []homeData{
+		{ID: "home1", Name: "Home 1"},
+		{ID: "demo", Name: "Demo"},
+		{ID: "home2", Name: "Home 2"},
+		{ID: "home3", Name: "Home 3"},
+	}
+
+	c := NewThermostatCollector(logrus.New(), nil, WithMaxHomes(2), WithExcludedHomes(regexp.MustCompile("^Demo$")))
+
+	wantScrapes := [][]string{
+		{"home1", "home2"},
+		{"home3", "home1"},
+		{"home2", "home3"},
+	}
+	for i, want := range wantScrapes {
+		var got []string
+		for _, home := range c.selectHomes(homes) {
+			got = append(got, home.ID)
+		}
+
+		if diff := cmp.Diff(got, want); diff != "" {
+			t.Errorf("scrape %d: homes differ: -got+want\n%s", i, diff)
+		}
+	}
+}
+
+func TestThermostatCollector_IncludedHomes(t *testing.T) {
+	homes := []homeData{
+		{ID: "home1", Name: "Home 1"},
+		{ID: "home2", Name: "Home 2"},
+		{ID: "home3", Name: "Home 3"},
+		{ID: "demo", Name: "Demo"},
+	}
+
+	tt := []struct {
+		desc string
+		opts []ThermostatOption
+		want []string
+	}{
+		{
+			desc: "empty",
+			opts: []ThermostatOption{WithIncludedHomes(nil)},
+			want: []string{"home1", "home2", "home3", "demo"},
+		},
+		{
+			desc: "by id and name",
+			opts: []ThermostatOption{WithIncludedHomes([]string{"home1", "Home 3"})},
+			want: []string{"home1", "home3"},
+		},
+		{
+			desc: "excluded",
+			opts: []ThermostatOption{
+				WithIncludedHomes([]string{"home1", "demo"}),
+				WithExcludedHomes(regexp.MustCompile("^Demo$")),
+			},
+			want: []string{"home1"},
+		},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			c := NewThermostatCollector(logrus.New(), nil, tc.opts...)
+
+			var got []string
+			for _, home := range c.selectHomes(homes) {
+				got = append(got, home.ID)
+			}
+
+			if diff := cmp.Diff(got, tc.want); diff != "" {
+				t.Errorf("homes differ: -got+want\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestThermostatCollector_SetpointChanges(t *testing.T) {
+	c := NewThermostatCollector(logrus.New(), nil)
+
+	setpoints := []float64{20, 20, 21, 21, 17, 20}
+	wantChanges := []float64{0, 0, 1, 1, 2, 3}
+	for i, setpoint := range setpoints {
+		got := c.setpointChanges("room", setpoint)
+		if got != wantChanges[i] {
+			t.Errorf("setpoint %d: got %f changes, want %f", i, got, wantChanges[i])
+		}
+	}
+
+	if got := c.setpointChanges("other", 20); got != 0 {
+		t.Errorf("got %f changes for other room, want 0", got)
+	}
+}
+
+func TestThermostatCollector_ModeChanges(t *testing.T) {
+	c := NewThermostatCollector(logrus.New(), nil)
+
+	modes := []string{"schedule", "schedule", "away", "away", "hg", "schedule"}
+	wantChanges := []float64{0, 0, 1, 1, 2, 3}
+	for i, mode := range modes {
+		got := c.modeChanges("home", mode)
+		if got != wantChanges[i] {
+			t.Errorf("mode %d: got %f changes, want %f", i, got, wantChanges[i])
+		}
+	}
+
+	if got := c.modeChanges("other", "away"); got != 0 {
+		t.Errorf("got %f changes for other home, want 0", got)
+	}
+}
+
+func TestThermostatCollector_HeatingWhileAway(t *testing.T) {
+	tt := []struct {
+		desc         string
+		thermMode    string
+		powerRequest float64
+		want         float64
+	}{
+		{
+			desc:         "away and heating",
+			thermMode:    "away",
+			powerRequest: 40,
+			want:         1,
+		},
+		{
+			desc:         "away and idle",
+			thermMode:    "away",
+			powerRequest: 0,
+			want:         0,
+		},
+		{
+			desc:         "schedule and heating",
+			thermMode:    "schedule",
+			powerRequest: 40,
+			want:         0,
+		},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			client := &fakeClient{
+				homesData: mustDecode[HomesDataResponse](t, `{"body":{"homes":[{"id":"home","name":"Home","rooms":[{"id":"room","name":"Bedroom"}]}]}}`),
+				homeStatus: map[string]*HomeStatusResponse{
+					"home": mustDecode[HomeStatusResponse](t, fmt.Sprintf(`{"body":{"home":{"id":"home","therm_mode":%q,"rooms":[
+						{"id":"room","heating_power_request":%v}
+					]}}}`, tc.thermMode, tc.powerRequest)),
+				},
+			}
+			c := NewThermostatCollector(logrus.New(), client)
+
+			want := fmt.Sprintf(`# HELP netatmo_room_heating_while_away Netatmo Energy set to 1 if a room calls for heat while its home is in away mode, 0 otherwise.
+# TYPE netatmo_room_heating_while_away gauge
+netatmo_room_heating_while_away{home_id="home",home_name="Home",room_id="room",room_name="Bedroom"} %v
+`, tc.want)
+			if err := testutil.CollectAndCompare(c, strings.NewReader(want), "netatmo_room_heating_while_away"); err != nil {
+				t.Errorf("metrics differ: %s", err)
+			}
+		})
+	}
+}
+
+func TestThermostatCollector_MissingAsNaN(t *testing.T) {
+	client := &fakeClient{
+		homesData: mustDecode[HomesDataResponse](t, `{"body":{"homes":[{"id":"home","name":"Home"}]}}`),
+		homeStatus: map[string]*HomeStatusResponse{
+			"home": mustDecode[HomeStatusResponse](t, `{"body":{"home":{"id":"home","rooms":[
+				{"id":"living","name":"Living Room","therm_measured_temperature":20.5,"therm_setpoint_temperature":21},
+				{"id":"bedroom","name":"Bedroom"}
+			]}}}`),
+		},
+	}
+	c := NewThermostatCollector(logrus.New(), client, WithMissingAsNaN(true))
+
+	want := `# HELP netatmo_thermostat_setpoint Netatmo Energy target setpoint temperature in degrees Celsius.
+# TYPE netatmo_thermostat_setpoint gauge
+netatmo_thermostat_setpoint{home_id="home",home_name="Home",room_id="bedroom",room_name="Bedroom"} NaN
+netatmo_thermostat_setpoint{home_id="home",home_name="Home",room_id="living",room_name="Living Room"} 21
+# HELP netatmo_thermostat_temperature Netatmo Energy measured room temperature in degrees Celsius.
+# TYPE netatmo_thermostat_temperature gauge
+netatmo_thermostat_temperature{home_id="home",home_name="Home",room_id="bedroom",room_name="Bedroom"} NaN
+netatmo_thermostat_temperature{home_id="home",home_name="Home",room_id="living",room_name="Living Room"} 20.5
+`
+	if err := testutil.CollectAndCompare(c, strings.NewReader(want), "netatmo_thermostat_setpoint", "netatmo_thermostat_temperature"); err != nil {
+		t.Errorf("metrics differ: %s", err)
+	}
+}
+
+func TestThermostatCollector_Humidity(t *testing.T) {
+	client := &fakeClient{
+		homesData: mustDecode[HomesDataResponse](t, `{"body":{"homes":[{"id":"home","name":"Home"}]}}`),
+		homeStatus: map[string]*HomeStatusResponse{
+			"home": mustDecode[HomeStatusResponse](t, `{"body":{"home":{"id":"home","rooms":[
+				{"id":"living","name":"Living Room","therm_relative_humidity":45,"humidity":44},
+				{"id":"bedroom","name":"Bedroom","humidity":52},
+				{"id":"kitchen","name":"Kitchen","therm_measured_temperature":20.5}
+			]}}}`),
+		},
+	}
+	c := NewThermostatCollector(logrus.New(), client)
+
+	want := `# HELP netatmo_thermostat_humidity Netatmo Energy measured relative humidity of a room in percent.
+# TYPE netatmo_thermostat_humidity gauge
+netatmo_thermostat_humidity{home_id="home",home_name="Home",room_id="bedroom",room_name="Bedroom"} 52
+netatmo_thermostat_humidity{home_id="home",home_name="Home",room_id="living",room_name="Living Room"} 45
+`
+	if err := testutil.CollectAndCompare(c, strings.NewReader(want), "netatmo_thermostat_humidity"); err != nil {
+		t.Errorf("metrics differ: %s", err)
+	}
+}
+
+func TestThermostatCollector_HeatingPowerRequest(t *testing.T) {
+	client := &fakeClient{
+		homesData: mustDecode[HomesDataResponse](t, `{"body":{"homes":[{"id":"home","name":"Home","modules":[
+			{"id":"valve-bedroom-2","type":"NRV","room_id":"bedroom"}
+		]}]}}`),
+		homeStatus: map[string]*HomeStatusResponse{
+			"home": mustDecode[HomeStatusResponse](t, `{"body":{"home":{"id":"home","rooms":[
+				{"id":"living","name":"Living Room","heating_power_request":40},
+				{"id":"bedroom","name":"Bedroom"},
+				{"id":"kitchen","name":"Kitchen","therm_measured_temperature":20.5}
+			],"modules":[
+				{"id":"valve-living","type":"NRV","room_id":"living","heating_power_request":100},
+				{"id":"valve-bedroom-1","type":"NRV","room_id":"bedroom","heating_power_request":20},
+				{"id":"valve-bedroom-2","type":"NRV","heating_power_request":60}
+			]}}}`),
+		},
+	}
+	c := NewThermostatCollector(logrus.New(), client)
+
+	want := `# HELP netatmo_thermostat_heating_power_request Netatmo Energy heating power requested by the valves of a room in percent.
+# TYPE netatmo_thermostat_heating_power_request gauge
+netatmo_thermostat_heating_power_request{home_id="home",home_name="Home",room_id="bedroom",room_name="Bedroom"} 60
+netatmo_thermostat_heating_power_request{home_id="home",home_name="Home",room_id="living",room_name="Living Room"} 40
+`
+	if err := testutil.CollectAndCompare(c, strings.NewReader(want), "netatmo_thermostat_heating_power_request"); err != nil {
+		t.Errorf("metrics differ: %s", err)
+	}
+}
+
+func TestThermostatCollector_AnticipatingOpenWindow(t *testing.T) {
+	client := &fakeClient{
+		homesData: mustDecode[HomesDataResponse](t, `{"body":{"homes":[{"id":"home","name":"Home"}]}}`),
+		homeStatus: map[string]*HomeStatusResponse{
+			"home": mustDecode[HomeStatusResponse](t, `{"body":{"home":{"id":"home","rooms":[
+				{"id":"living","name":"Living Room","anticipating":true,"open_window":false},
+				{"id":"bedroom","name":"Bedroom","anticipating":false,"open_window":true},
+				{"id":"kitchen","name":"Kitchen","therm_measured_temperature":20.5}
+			]}}}`),
+		},
+	}
+	c := NewThermostatCollector(logrus.New(), client)
+
+	want := `# HELP netatmo_thermostat_anticipating Netatmo Energy anticipation of a room (1=heating ahead of the next change of the schedule, 0=not anticipating).
+# TYPE netatmo_thermostat_anticipating gauge
+netatmo_thermostat_anticipating{home_id="home",home_name="Home",room_id="bedroom",room_name="Bedroom"} 0
+netatmo_thermostat_anticipating{home_id="home",home_name="Home",room_id="living",room_name="Living Room"} 1
+# HELP netatmo_thermostat_open_window Netatmo Energy open window detection of a room (1=heating paused because of an open window, 0=no open window detected).
+# TYPE netatmo_thermostat_open_window gauge
+netatmo_thermostat_open_window{home_id="home",home_name="Home",room_id="bedroom",room_name="Bedroom"} 1
+netatmo_thermostat_open_window{home_id="home",home_name="Home",room_id="living",room_name="Living Room"} 0
+`
+	if err := testutil.CollectAndCompare(c, strings.NewReader(want), "netatmo_thermostat_anticipating", "netatmo_thermostat_open_window"); err != nil {
+		t.Errorf("metrics differ: %s", err)
+	}
+}
+
+func TestThermostatCollector_SetpointEndTime(t *testing.T) {
+	client := &fakeClient{
+		homesData: mustDecode[HomesDataResponse](t, `{"body":{"homes":[{"id":"home","name":"Home"}]}}`),
+		homeStatus: map[string]*HomeStatusResponse{
+			"home": mustDecode[HomeStatusResponse](t, `{"body":{"home":{"id":"home","rooms":[
+				{"id":"living","name":"Living Room","therm_setpoint_mode":"manual","therm_setpoint_end_time":1704117600},
+				{"id":"bedroom","name":"Bedroom","therm_setpoint_mode":"manual","therm_setpoint_end_time":0},
+				{"id":"kitchen","name":"Kitchen","therm_setpoint_mode":"schedule"}
+			]}}}`),
+		},
+	}
+	c := NewThermostatCollector(logrus.New(), client)
+
+	want := `# HELP netatmo_thermostat_setpoint_end_time_seconds Netatmo Energy unix timestamp at which a temporary setpoint of a room, for example in manual mode, ends and the room returns to the schedule.
+# TYPE netatmo_thermostat_setpoint_end_time_seconds gauge
+netatmo_thermostat_setpoint_end_time_seconds{home_id="home",home_name="Home",room_id="living",room_name="Living Room"} 1.7041176e+09
+`
+	if err := testutil.CollectAndCompare(c, strings.NewReader(want), "netatmo_thermostat_setpoint_end_time_seconds"); err != nil {
+		t.Errorf("metrics differ: %s", err)
+	}
+}
+
+func TestThermostatCollector_BoilerStatusAvailable(t *testing.T) {
+	client := &fakeClient{
+		homesData: mustDecode[HomesDataResponse](t, `{"body":{"homes":[{"id":"relay","name":"Relay"},{"id":"valves","name":"Valves"}]}}`),
+		homeStatus: map[string]*HomeStatusResponse{
+			"relay": mustDecode[HomeStatusResponse](t, `{"body":{"home":{"id":"relay","modules":[
+				{"id":"boiler","type":"OTH","boiler_status":false}
+			]}}}`),
+			"valves": mustDecode[HomeStatusResponse](t, `{"body":{"home":{"id":"valves","modules":[
+				{"id":"valve","type":"NRV","heating_power_request":50}
+			]}}}`),
+		},
+	}
+	c := NewThermostatCollector(logrus.New(), client, WithBoilerStatusAvailable(true))
+
+	want := `# HELP netatmo_boiler_status_available Netatmo Energy set to 1 if at least one module of a home reports a boiler status, 0 if the home does not report one at all.
+# TYPE netatmo_boiler_status_available gauge
+netatmo_boiler_status_available{home_id="relay",home_name="Relay"} 1
+netatmo_boiler_status_available{home_id="valves",home_name="Valves"} 0
+`
+	if err := testutil.CollectAndCompare(c, strings.NewReader(want), "netatmo_boiler_status_available"); err != nil {
+		t.Errorf("metrics differ: %s", err)
+	}
+}
+
+func TestComfortScore(t *testing.T) {
+	humidity := func(h float64) *float64 {
+		return &h
+	}
+
+	tt := []struct {
+		desc        string
+		temperature float64
+		setpoint    float64
+		humidity    *float64
+		wantScore   float64
+	}{
+		{
+			desc:        "perfect",
+			temperature: 21,
+			setpoint:    21,
+			humidity:    humidity(50),
+			wantScore:   100,
+		},
+		{
+			desc:        "no humidity",
+			temperature: 20.5,
+			setpoint:    21,
+			wantScore:   90,
+		},
+		{
+			desc:        "too dry",
+			temperature: 22,
+			setpoint:    21,
+			humidity:    humidity(30),
+			wantScore:   60,
+		},
+		{
+			desc:        "too humid",
+			temperature: 21,
+			setpoint:    21,
+			humidity:    humidity(70),
+			wantScore:   80,
+		},
+		{
+			desc:        "minimum",
+			temperature: 14,
+			setpoint:    21,
+			wantScore:   0,
+		},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			got := comfortScore(tc.temperature, tc.setpoint, tc.humidity)
+			if got != tc.wantScore {
+				t.Errorf("got score %f, want %f", got, tc.wantScore)
+			}
+		})
+	}
+}
+
+func TestRoomIDsByName(t *testing.T) {
+	rooms := []homeRoom{
+		{ID: "1", Name: "Bedroom"},
+		{ID: "2", Name: ""},
+		{ID: "3", Name: "Office"},
+		{ID: "4", Name: "Office"},
+		{ID: "5", Name: "Office"},
+	}
+
+	want := map[string]string{
+		"Bedroom": "1",
+	}
+
+	got := roomIDsByName(rooms)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("room IDs differ: -want +got\n%s", diff)
+	}
+}
+
+func TestThermostatCollector_IDsNotEmpty(t *testing.T) {
+	client := &fakeClient{
+		homesData: mustDecode[HomesDataResponse](t, `{"body":{"homes":[{"id":"home","name":"Home","therm_mode":"schedule",
+			"rooms":[{"id":"living","name":"Living Room"},{"id":"bedroom","name":"Bedroom"}],
+			"modules":[{"id":"relay","type":"NAPlug","name":"Relay"},{"id":"valve","type":"NRV","name":"Valve"}]
+		}]}}`),
+		homeStatus: map[string]*HomeStatusResponse{
+			"home": mustDecode[HomeStatusResponse](t, `{"body":{"home":{
+				"rooms":[
+					{"id":"living","therm_measured_temperature":20,"therm_setpoint_temperature":21},
+					{"name":"Bedroom","therm_measured_temperature":18,"therm_setpoint_temperature":19},
+					{"therm_measured_temperature":17}
+				],
+				"modules":[
+					{"id":"relay","type":"NAPlug","reachable":true},
+					{"id":"valve","type":"NRV","room_id":"living","boiler_status":true,"battery_state":"full"}
+				]
+			}}}`),
+		},
+	}
+	c := NewThermostatCollector(logrus.New(), client)
+
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(c)
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("error gathering metrics: %s", err)
+	}
+
+	rooms := map[string]bool{}
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				switch label.GetName() {
+				case "home_id", "module_id":
+				case "room_id":
+					if label.GetValue() != "" {
+						rooms[label.GetValue()] = true
+						continue
+					}
+
+					// The boiler status of the whole home is reported without a room.
+					if family.GetName() == "netatmo_thermostat_boiler_status" {
+						continue
+					}
+
+					// Modules which are not part of a room, like the relay, are reported without a room.
+					if strings.HasPrefix(family.GetName(), "netatmo_thermostat_module_") {
+						continue
+					}
+				default:
+					continue
+				}
+
+				if label.GetValue() == "" {
+					t.Errorf("metric %s has an empty %s label: %s", family.GetName(), label.GetName(), metric)
+				}
+			}
+		}
+	}
+
+	wantRooms := map[string]bool{
+		"living":  true,
+		"bedroom": true,
+	}
+	if diff := cmp.Diff(wantRooms, rooms); diff != "" {
+		t.Errorf("rooms differ: -want +got\n%s", diff)
+	}
+}
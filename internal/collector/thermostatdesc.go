@@ -0,0 +1,492 @@
+package collector
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// thermostatDescs contains the descriptors of the metrics of a ThermostatCollector, which are created with the metric
+// prefix of the collector.
+type thermostatDescs struct {
+	thermostatTemperature           *prometheus.Desc
+	thermostatTemperatureFahrenheit *prometheus.Desc
+	roomTemperatureChange           *prometheus.Desc
+	thermostatSetpoint              *prometheus.Desc
+	thermostatSetpointEndTime       *prometheus.Desc
+	thermostatHumidity              *prometheus.Desc
+	thermostatHeatingPowerRequest   *prometheus.Desc
+	thermostatAnticipating          *prometheus.Desc
+	thermostatOpenWindow            *prometheus.Desc
+	setpointChanges                 *prometheus.Desc
+	roomComfortScore                *prometheus.Desc
+	roomUnderheating                *prometheus.Desc
+	valveSetpointUnreachable        *prometheus.Desc
+	roomHeatingSecondsToday         *prometheus.Desc
+	roomMaxModeActive               *prometheus.Desc
+	thermostatSetpointMode          *prometheus.Desc
+	thermostatBoilerStatus          *prometheus.Desc
+	thermostatRelayCmd              *prometheus.Desc
+	boilerStatus                    *prometheus.Desc
+	boilerStatusAvailable           *prometheus.Desc
+	relayFirmwareRevision           *prometheus.Desc
+	moduleSetupTime                 *prometheus.Desc
+	moduleNeedsAttention            *prometheus.Desc
+	moduleTypeCode                  *prometheus.Desc
+	moduleBatteryVoltage            *prometheus.Desc
+	orphanedModule                  *prometheus.Desc
+	scheduleTimeslotSetpoint        *prometheus.Desc
+	nextSetpointChange              *prometheus.Desc
+	roomActiveZone                  *prometheus.Desc
+	roomComfortSetpoint             *prometheus.Desc
+	energySavingOpportunities       *prometheus.Desc
+	homeSchedules                   *prometheus.Desc
+	homeActiveSchedule              *prometheus.Desc
+	homeAway                        *prometheus.Desc
+	homeModeChanges                 *prometheus.Desc
+	homeLastSetpointChange          *prometheus.Desc
+	collectionState                 *prometheus.Desc
+	roomHeatingWhileAway            *prometheus.Desc
+	roomInfo                        *prometheus.Desc
+	homeReachable                   *prometheus.Desc
+	thermostatModuleReachable       *prometheus.Desc
+	thermostatModuleInfo            *prometheus.Desc
+	thermostatModuleBatteryPercent  *prometheus.Desc
+	homeUnreachableModules          *prometheus.Desc
+	homeHeatDemand                  *prometheus.Desc
+	homeDataCompleteness            *prometheus.Desc
+	heatingActiveSeason             *prometheus.Desc
+	thermostatHomesFromCache        *prometheus.Desc
+	thermostatHomesDataSkipped      *prometheus.Desc
+	homesDiscovered                 *prometheus.Desc
+	homesProcessed                  *prometheus.Desc
+	homeStatusConcurrency           *prometheus.Desc
+	homeStatusInFlight              *prometheus.Desc
+	homeStatusRetries               *prometheus.Desc
+	homeStatusCacheHits             *prometheus.Desc
+	scrapeDuration                  *prometheus.Desc
+	scrapeErrors                    *prometheus.Desc
+	homeLastSuccess                 *prometheus.Desc
+	scrapeTimedOut                  *prometheus.Desc
+	accountInfo                     *prometheus.Desc
+	boilerOnSeconds                 *prometheus.Desc
+	boilerCycles                    *prometheus.Desc
+	boilerDutyCycle                 *prometheus.Desc
+	boilerSamples                   *prometheus.Desc
+	boilerOnSamples                 *prometheus.Desc
+}
+
+// WithMetricPrefix creates the descriptors of the collector with metricPrefix instead of "netatmo_" at the start of
+// the metric names, for example "company_netatmo_", so that the collector can be used next to another Netatmo
+// exporter. An empty prefix keeps "netatmo_".
+func WithMetricPrefix(metricPrefix string) ThermostatOption {
+	return func(c *ThermostatCollector) {
+		if metricPrefix != "" {
+			c.metricPrefix = metricPrefix
+		}
+	}
+}
+
+// newThermostatDescs creates the descriptors of the thermostat metrics with metricPrefix at the start of the names.
+func newThermostatDescs(metricPrefix string) *thermostatDescs {
+	desc := func(name, help string, labels []string) *prometheus.Desc {
+		return newPrefixedDesc(metricPrefix, name, help, labels)
+	}
+	measurement := func(name, help string, labels []string) *prometheus.Desc {
+		return defineDesc(descDefinition{
+			prefix:      metricPrefix,
+			name:        name,
+			help:        help,
+			labels:      labels,
+			measurement: true,
+		})
+	}
+
+	return &thermostatDescs{
+		thermostatTemperature: measurement(
+			"thermostat_temperature",
+			"Netatmo Energy measured room temperature in degrees Celsius.",
+			thermostatLabels,
+		),
+
+		thermostatTemperatureFahrenheit: measurement(
+			"thermostat_temperature_fahrenheit",
+			"Netatmo Energy measured room temperature in degrees Fahrenheit.",
+			thermostatLabels,
+		),
+
+		roomTemperatureChange: measurement(
+			"room_temperature_change_per_hour",
+			"Netatmo Energy rate of change of the room temperature in degrees Celsius per hour between the two most recent readings of the exporter.",
+			thermostatLabels,
+		),
+
+		thermostatSetpoint: measurement(
+			"thermostat_setpoint",
+			"Netatmo Energy target setpoint temperature in degrees Celsius.",
+			thermostatLabels,
+		),
+
+		thermostatSetpointEndTime: desc(
+			"thermostat_setpoint_end_time_seconds",
+			"Netatmo Energy unix timestamp at which a temporary setpoint of a room, for example in manual mode, ends and the room returns to the schedule.",
+			thermostatLabels,
+		),
+
+		thermostatHumidity: measurement(
+			"thermostat_humidity",
+			"Netatmo Energy measured relative humidity of a room in percent.",
+			thermostatLabels,
+		),
+
+		thermostatHeatingPowerRequest: desc(
+			"thermostat_heating_power_request",
+			"Netatmo Energy heating power requested by the valves of a room in percent.",
+			thermostatLabels,
+		),
+
+		thermostatAnticipating: desc(
+			"thermostat_anticipating",
+			"Netatmo Energy anticipation of a room (1=heating ahead of the next change of the schedule, 0=not anticipating).",
+			thermostatLabels,
+		),
+
+		thermostatOpenWindow: desc(
+			"thermostat_open_window",
+			"Netatmo Energy open window detection of a room (1=heating paused because of an open window, 0=no open window detected).",
+			thermostatLabels,
+		),
+
+		setpointChanges: desc(
+			"setpoint_changes_total",
+			"Netatmo Energy number of changes of the setpoint temperature of a room observed by the exporter.",
+			thermostatLabels,
+		),
+
+		roomComfortScore: desc(
+			"room_comfort_score",
+			"Netatmo Energy comfort score of a room from 0 to 100 approximated by the exporter from the deviation of the temperature from the setpoint and the humidity.",
+			thermostatLabels,
+		),
+
+		roomUnderheating: desc(
+			"room_underheating",
+			"Netatmo Energy underheating of a room (1=the temperature has been below the setpoint by more than the threshold for longer than the configured duration, 0=otherwise).",
+			thermostatLabels,
+		),
+
+		valveSetpointUnreachable: desc(
+			"valve_setpoint_unreachable",
+			"Netatmo Energy set to 1 if the valves of a room have requested full heating power while the room stayed below its setpoint for longer than the underheating duration, 0 otherwise.",
+			thermostatLabels,
+		),
+
+		roomHeatingSecondsToday: desc(
+			"room_heating_seconds_today",
+			"Netatmo Energy estimated seconds the valves of a room have requested heating power since local midnight, integrated from the observed heating power requests.",
+			thermostatLabels,
+		),
+
+		roomMaxModeActive: desc(
+			"room_max_mode_active",
+			"Netatmo Energy max mode of a room (1=setpoint mode is \"max\", 0=any other mode). The setpoint contains the maximum temperature while max mode is active.",
+			thermostatLabels,
+		),
+
+		thermostatSetpointMode: desc(
+			"thermostat_setpoint_mode",
+			"Netatmo Energy setpoint mode of a room. The series of the current mode is set to 1, the others to 0.",
+			append(thermostatLabels[:len(thermostatLabels):len(thermostatLabels)], "mode"),
+		),
+
+		thermostatBoilerStatus: desc(
+			"thermostat_boiler_status",
+			"Netatmo Energy boiler status (1=on, 0=off). Depending on the boiler status mode per room (source=\"room\") and/or per home (source=\"home\").",
+			[]string{"home_id", "home_name", "room_id", "room_name", "source"},
+		),
+
+		thermostatRelayCmd: desc(
+			"thermostat_relay_cmd",
+			"Netatmo Energy relay command of a classic thermostat (NATherm1) in percent (100=heating, 0=idle).",
+			thermostatLabels,
+		),
+
+		boilerStatus: desc(
+			"boiler_status",
+			"Netatmo Energy boiler status (1=on, 0=off) reported by a single module. Homes with more than one boiler have one series per module.",
+			[]string{"home_id", "home_name", "module_id", "module_name"},
+		),
+
+		boilerStatusAvailable: desc(
+			"boiler_status_available",
+			"Netatmo Energy set to 1 if at least one module of a home reports a boiler status, 0 if the home does not report one at all.",
+			[]string{"home_id", "home_name"},
+		),
+
+		relayFirmwareRevision: desc(
+			"relay_firmware_revision",
+			"Netatmo Energy firmware revision of a relay, which connects all other modules of a home.",
+			[]string{"home_id", "home_name", "module_id", "module_name"},
+		),
+
+		moduleSetupTime: desc(
+			"module_setup_time_seconds",
+			"Netatmo Energy time a module was set up as a Unix timestamp, as reported by homesdata.",
+			[]string{"home_id", "home_name", "module_id", "module_name"},
+		),
+
+		moduleNeedsAttention: desc(
+			"module_needs_attention",
+			"Contains 1 if a module has a low battery, is unreachable or has a poor signal, 0 otherwise.",
+			[]string{"home_id", "home_name", "module_id", "module_name"},
+		),
+
+		moduleTypeCode: desc(
+			"module_type_code",
+			"Contains a numeric code for the Netatmo type of a module, 0 if the type is unknown. The codes are listed in the README.",
+			[]string{"home_id", "home_name", "module_id", "module_name"},
+		),
+
+		moduleBatteryVoltage: measurement(
+			"module_battery_voltage",
+			"Contains the battery voltage of a battery-powered module in volts.",
+			[]string{"home_id", "home_name", "module_id", "module_name"},
+		),
+
+		orphanedModule: desc(
+			"orphaned_module",
+			"Netatmo Energy marker for a thermostat or valve which is not assigned to any room of its home. The room metrics do not contain its data.",
+			[]string{"home_id", "home_name", "module_id", "module_name"},
+		),
+
+		scheduleTimeslotSetpoint: measurement(
+			"schedule_timeslot_setpoint",
+			"Netatmo Energy setpoint temperature in degrees Celsius of a timeslot of the active schedule for the current day. The timeslot is identified by its start in minutes since Monday 00:00.",
+			append(thermostatLabels[:len(thermostatLabels):len(thermostatLabels)], "minute_of_week"),
+		),
+
+		nextSetpointChange: desc(
+			"next_setpoint_change_seconds",
+			"Netatmo Energy unix timestamp of the next change of the setpoint temperature in the active schedule.",
+			thermostatLabels,
+		),
+
+		roomActiveZone: desc(
+			"room_active_zone",
+			"Netatmo Energy zone of the active schedule currently applied to a room. The value is always 1.",
+			append(thermostatLabels[:len(thermostatLabels):len(thermostatLabels)], "zone_id", "zone_name", "zone_type"),
+		),
+
+		roomComfortSetpoint: measurement(
+			"room_comfort_setpoint",
+			"Netatmo Energy setpoint temperature in degrees Celsius configured for a room in the comfort zone of the active schedule.",
+			thermostatLabels,
+		),
+
+		energySavingOpportunities: desc(
+			"energy_saving_opportunities",
+			"Netatmo Energy number of rooms of a home whose setpoint has been overridden manually or by the max mode to a temperature above their comfort temperature in the active schedule.",
+			[]string{"home_id", "home_name"},
+		),
+
+		homeSchedules: desc(
+			"home_schedules_total",
+			"Netatmo Energy number of schedules of a home in homesdata, including schedules of other types than heating.",
+			[]string{"home_id", "home_name"},
+		),
+
+		homeActiveSchedule: desc(
+			"home_active_schedule_info",
+			"Netatmo Energy active heating schedule of a home. The value is always 1.",
+			[]string{"home_id", "home_name", "schedule_id", "schedule_name"},
+		),
+
+		homeAway: desc(
+			"home_away",
+			"Netatmo Energy away status of a home (1=therm_mode is \"away\", 0=any other mode).",
+			[]string{"home_id", "home_name"},
+		),
+
+		homeModeChanges: desc(
+			"home_mode_changes_total",
+			"Netatmo Energy number of changes of the therm_mode of a home observed by the exporter.",
+			[]string{"home_id", "home_name"},
+		),
+
+		homeLastSetpointChange: desc(
+			"home_last_setpoint_change_seconds",
+			"Netatmo Energy unix timestamp of the last change of the setpoint of any room of a home observed by the exporter. Before the first change the time the home was first collected is reported.",
+			[]string{"home_id", "home_name"},
+		),
+
+		collectionState: desc(
+			"collection_state",
+			"Netatmo Energy outcome of the last collection. The series of the current state is set to 1, the others to 0.",
+			[]string{"state"},
+		),
+
+		roomHeatingWhileAway: desc(
+			"room_heating_while_away",
+			"Netatmo Energy set to 1 if a room calls for heat while its home is in away mode, 0 otherwise.",
+			thermostatLabels,
+		),
+
+		roomInfo: desc(
+			"room_info",
+			"Netatmo Energy room type from homesdata. The value is always 1.",
+			append(thermostatLabels[:len(thermostatLabels):len(thermostatLabels)], "room_type"),
+		),
+
+		homeReachable: desc(
+			"home_reachable",
+			"Netatmo Energy reachability of a home (1=at least one module is reachable, 0=all modules are unreachable).",
+			[]string{"home_id", "home_name"},
+		),
+
+		thermostatModuleReachable: desc(
+			"thermostat_module_reachable",
+			"Netatmo Energy reachability of a module (1=reachable, 0=unreachable).",
+			thermostatModuleLabels,
+		),
+
+		thermostatModuleInfo: desc(
+			"thermostat_module_info",
+			"Netatmo Energy type and firmware revision of a module from homestatus. The firmware revision is empty if the module does not report it. The value is always 1.",
+			[]string{"home_id", "module_id", "type", "firmware_revision"},
+		),
+
+		thermostatModuleBatteryPercent: desc(
+			"thermostat_module_battery_percent",
+			"Netatmo Energy approximate battery level of a battery-powered module in percent, derived from its battery state.",
+			thermostatModuleLabels,
+		),
+
+		homeUnreachableModules: desc(
+			"home_unreachable_modules",
+			"Netatmo Energy number of modules of a home which are not reachable.",
+			[]string{"home_id", "home_name"},
+		),
+
+		homeHeatDemand: desc(
+			"home_heat_demand",
+			"Netatmo Energy number of rooms of a home currently calling for heat.",
+			[]string{"home_id", "home_name"},
+		),
+
+		homeDataCompleteness: desc(
+			"home_data_completeness",
+			"Netatmo Energy fraction of the rooms of a home in homestatus which reported a measured temperature.",
+			[]string{"home_id", "home_name"},
+		),
+
+		heatingActiveSeason: desc(
+			"heating_active_season",
+			"Netatmo Energy heating season of a home (1=a room called for heat within the configured window, 0=otherwise).",
+			[]string{"home_id", "home_name"},
+		),
+
+		thermostatHomesFromCache: desc(
+			"thermostat_homes_from_cache",
+			"Set to 1 if homesdata could not be fetched and the list of homes from a previous scrape was used, 0 otherwise.",
+			nil,
+		),
+
+		thermostatHomesDataSkipped: desc(
+			"thermostat_homesdata_skipped_homes",
+			"Number of homes for which homesdata was not requested during this scrape, because the homes data interval has not passed yet.",
+			nil,
+		),
+
+		homesDiscovered: desc(
+			"homes_discovered",
+			"Number of homes returned by homesdata. Zero usually means that the token is missing the read_thermostat scope.",
+			nil,
+		),
+
+		homesProcessed: desc(
+			"homes_processed",
+			"Number of homes whose status was collected successfully during this scrape.",
+			nil,
+		),
+
+		homeStatusConcurrency: desc(
+			"thermostat_home_status_concurrency",
+			"Number of homes whose homestatus was requested at the same time during this scrape, limited by --home-status-concurrency and the number of collected homes.",
+			nil,
+		),
+
+		homeStatusInFlight: desc(
+			"thermostat_home_status_requests_in_flight",
+			"Number of homestatus requests of the thermostat collector running at the end of the requests of this scrape, for example of concurrent scrapes.",
+			nil,
+		),
+
+		homeStatusRetries: desc(
+			"home_status_retries_total",
+			"Netatmo Energy number of homestatus requests of a home retried by the collector after a server error.",
+			[]string{"home_id", "home_name"},
+		),
+
+		homeStatusCacheHits: desc(
+			"thermostat_cache_hits_total",
+			"Netatmo Energy number of collections which used a cached homestatus response of a home instead of requesting it.",
+			[]string{"home_id", "home_name"},
+		),
+
+		scrapeDuration: desc(
+			"scrape_duration_seconds",
+			"Netatmo Energy time it took to collect the thermostat metrics during this scrape.",
+			nil,
+		),
+
+		scrapeErrors: desc(
+			"scrape_errors_total",
+			"Netatmo Energy number of failed requests during the collection of the thermostat metrics by phase of the collection.",
+			[]string{"phase"},
+		),
+
+		homeLastSuccess: desc(
+			"thermostat_last_success_timestamp_seconds",
+			"Netatmo Energy unix timestamp of the last successful homestatus request of a home. It is kept while the requests of the home fail.",
+			[]string{"home_id", "home_name"},
+		),
+
+		scrapeTimedOut: desc(
+			"scrape_timed_out",
+			"Set to 1 if the collect timeout was reached before the status of all homes was collected, 0 otherwise. The metrics of the homes collected before the timeout are still reported.",
+			nil,
+		),
+
+		accountInfo: desc(
+			"account_info",
+			"Contains the unit settings of the Netatmo account from homesdata. The numeric value is used for unknown settings.",
+			[]string{"unit_system", "unit_wind", "unit_pressure", "feel_like_algorithm"},
+		),
+
+		boilerOnSeconds: desc(
+			"boiler_on_seconds_total",
+			"Netatmo Energy total time the boiler was switched on by a thermostat in seconds, starting at the day the exporter was started.",
+			boilerOnLabels,
+		),
+
+		boilerCycles: desc(
+			"boiler_cycles_total",
+			"Netatmo Energy number of times the boiler of a home was observed switching on.",
+			[]string{"home_id", "home_name"},
+		),
+
+		boilerDutyCycle: desc(
+			"boiler_duty_cycle_ratio",
+			"Netatmo Energy fraction of the boiler status samples since the last scrape during which the boiler of a home was on. Only correct with a single Prometheus server scraping the exporter, otherwise use the rates of the sample counters.",
+			[]string{"home_id", "home_name"},
+		),
+
+		boilerSamples: desc(
+			"boiler_status_samples_total",
+			"Netatmo Energy number of boiler status samples of a home taken by the exporter.",
+			[]string{"home_id", "home_name"},
+		),
+
+		boilerOnSamples: desc(
+			"boiler_status_on_samples_total",
+			"Netatmo Energy number of boiler status samples of a home taken by the exporter during which the boiler was on.",
+			[]string{"home_id", "home_name"},
+		),
+	}
+}
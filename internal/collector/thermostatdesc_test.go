@@ -0,0 +1,56 @@
+package collector
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sirupsen/logrus"
+)
+
+func TestThermostatCollector_MetricPrefix(t *testing.T) {
+	client := &fakeClient{
+		homesData: mustDecode[HomesDataResponse](t, `{"body":{"homes":[{"id":"home","name":"Home","rooms":[{"id":"living","name":"Living Room"}]}]}}`),
+		homeStatus: map[string]*HomeStatusResponse{
+			"home": mustDecode[HomeStatusResponse](t, `{"body":{"home":{"id":"home","rooms":[
+				{"id":"living","therm_measured_temperature":20.5,"therm_setpoint_temperature":21}
+			],"modules":[
+				{"id":"valve","type":"NRV","room_id":"living","reachable":true}
+			]}}}`),
+		},
+	}
+	thermostat := NewThermostatCollector(logrus.New(), client, WithMetricPrefix("company_netatmo_"))
+
+	for name := range describedNames(thermostat) {
+		if !strings.HasPrefix(name, "company_netatmo_") {
+			t.Errorf("descriptor %s described without prefix", name)
+		}
+	}
+
+	// The collector is registered next to one with the default prefix, like when two exporters share a registry.
+	// The strategy and the filter keep working with the prefix. The collectors are gathered concurrently, so each
+	// one gets its own copy of the fake client.
+	defaultClient := *client
+	naming := NewMetricNaming(NamingEnergyPrefix, nil)
+	filter := NewMetricFilter([]string{"thermostat_temperature", "module_needs_attention"}, nil, "company_netatmo_")
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(
+		naming.Wrap(Filter(thermostat, filter)),
+		Filter(NewThermostatCollector(logrus.New(), &defaultClient), NewMetricFilter([]string{"thermostat_temperature"}, nil, "")),
+	)
+
+	want := `# HELP company_netatmo_energy_thermostat_temperature Netatmo Energy measured room temperature in degrees Celsius.
+# TYPE company_netatmo_energy_thermostat_temperature gauge
+company_netatmo_energy_thermostat_temperature{home_id="home",home_name="Home",room_id="living",room_name="Living Room"} 20.5
+# HELP company_netatmo_module_needs_attention Contains 1 if a module has a low battery, is unreachable or has a poor signal, 0 otherwise.
+# TYPE company_netatmo_module_needs_attention gauge
+company_netatmo_module_needs_attention{home_id="home",home_name="Home",module_id="valve",module_name=""} 0
+# HELP netatmo_thermostat_temperature Netatmo Energy measured room temperature in degrees Celsius.
+# TYPE netatmo_thermostat_temperature gauge
+netatmo_thermostat_temperature{home_id="home",home_name="Home",room_id="living",room_name="Living Room"} 20.5
+`
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(want)); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,47 @@
+package collector
+
+import (
+	"sync"
+	"time"
+)
+
+// underheatingState contains the time since when each room has been below its setpoint by more than the threshold.
+type underheatingState struct {
+	sync.Mutex
+	since map[string]time.Time
+}
+
+// WithUnderheating reports rooms as underheating, which have been more than threshold degrees below their setpoint
+// for at least duration. The duration should be long enough to not report rooms which are still heating up after
+// the setpoint was raised. A threshold of zero disables the metric.
+func WithUnderheating(threshold float64, duration time.Duration) ThermostatOption {
+	return func(c *ThermostatCollector) {
+		c.underheatingThreshold = threshold
+		c.underheatingDuration = duration
+	}
+}
+
+// underheating returns 1 if the room has been below its setpoint by more than the threshold for at least the
+// underheating duration. The time the room went below is kept across scrapes and reset as soon as the room is
+// warm enough again.
+func (c *ThermostatCollector) underheating(roomID string, temperature, setpoint float64, now time.Time) float64 {
+	c.underheatingState.Lock()
+	defer c.underheatingState.Unlock()
+
+	if temperature >= setpoint-c.underheatingThreshold {
+		delete(c.underheatingState.since, roomID)
+		return 0
+	}
+
+	since, ok := c.underheatingState.since[roomID]
+	if !ok {
+		since = now
+		c.underheatingState.since[roomID] = since
+	}
+
+	if now.Sub(since) < c.underheatingDuration {
+		return 0
+	}
+
+	return 1
+}
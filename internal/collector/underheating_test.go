@@ -0,0 +1,75 @@
+package collector
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sirupsen/logrus"
+)
+
+func TestThermostatCollector_Underheating(t *testing.T) {
+	client := &fakeClient{
+		homesData: mustDecode[HomesDataResponse](t, `{"body":{"homes":[{"id":"home","name":"Home","rooms":[{"id":"room","name":"Bedroom"}]}]}}`),
+	}
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	c := NewThermostatCollector(logrus.New(), client, WithUnderheating(1.5, time.Hour))
+	c.clock = func() time.Time {
+		return now
+	}
+
+	tt := []struct {
+		desc        string
+		advance     time.Duration
+		temperature float64
+		want        string
+	}{
+		{
+			desc:        "below setpoint",
+			temperature: 18,
+			want:        "0",
+		},
+		{
+			desc:        "below setpoint within duration",
+			advance:     30 * time.Minute,
+			temperature: 18,
+			want:        "0",
+		},
+		{
+			desc:        "below setpoint for duration",
+			advance:     30 * time.Minute,
+			temperature: 18,
+			want:        "1",
+		},
+		{
+			desc:        "within threshold",
+			advance:     10 * time.Minute,
+			temperature: 20,
+			want:        "0",
+		},
+		{
+			desc:        "below setpoint again",
+			advance:     10 * time.Minute,
+			temperature: 18,
+			want:        "0",
+		},
+	}
+
+	for _, tc := range tt {
+		now = now.Add(tc.advance)
+		client.homeStatus = map[string]*HomeStatusResponse{
+			"home": mustDecode[HomeStatusResponse](t, fmt.Sprintf(`{"body":{"home":{"id":"home","rooms":[
+				{"id":"room","therm_measured_temperature":%v,"therm_setpoint_temperature":21}
+			]}}}`, tc.temperature)),
+		}
+
+		want := `# HELP netatmo_room_underheating Netatmo Energy underheating of a room (1=the temperature has been below the setpoint by more than the threshold for longer than the configured duration, 0=otherwise).
+# TYPE netatmo_room_underheating gauge
+netatmo_room_underheating{home_id="home",home_name="Home",room_id="room",room_name="Bedroom"} ` + tc.want + "\n"
+		if err := testutil.CollectAndCompare(c, strings.NewReader(want), "netatmo_room_underheating"); err != nil {
+			t.Errorf("%s: metrics differ: %s", tc.desc, err)
+		}
+	}
+}
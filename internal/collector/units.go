@@ -0,0 +1,25 @@
+package collector
+
+// Conversions used for the additional imperial metrics enabled by the dual units option.
+
+func celsiusToFahrenheit(celsius float64) float64 {
+	return celsius*9/5 + 32
+}
+
+func kphToMph(kph float64) float64 {
+	return kph / 1.609344
+}
+
+func mmToInches(mm float64) float64 {
+	return mm / 25.4
+}
+
+// convertOptional applies convert to value, keeping a missing value missing.
+func convertOptional(value *float64, convert func(float64) float64) *float64 {
+	if value == nil {
+		return nil
+	}
+
+	converted := convert(*value)
+	return &converted
+}
@@ -0,0 +1,64 @@
+package collector
+
+import (
+	"math"
+	"testing"
+)
+
+func TestUnitConversions(t *testing.T) {
+	tt := []struct {
+		desc    string
+		convert func(float64) float64
+		value   float64
+		want    float64
+	}{
+		{
+			desc:    "freezing point",
+			convert: celsiusToFahrenheit,
+			value:   0,
+			want:    32,
+		},
+		{
+			desc:    "negative temperature",
+			convert: celsiusToFahrenheit,
+			value:   -40,
+			want:    -40,
+		},
+		{
+			desc:    "wind strength",
+			convert: kphToMph,
+			value:   16.09344,
+			want:    10,
+		},
+		{
+			desc:    "rain amount",
+			convert: mmToInches,
+			value:   12.7,
+			want:    0.5,
+		},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			got := tc.convert(tc.value)
+			if math.Abs(got-tc.want) > 1e-9 {
+				t.Errorf("got %f, want %f", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestConvertOptional(t *testing.T) {
+	if got := convertOptional(nil, celsiusToFahrenheit); got != nil {
+		t.Errorf("got %f, want nil", *got)
+	}
+
+	value := 100.0
+	got := convertOptional(&value, celsiusToFahrenheit)
+	if got == nil || *got != 212 {
+		t.Errorf("got %v, want 212", got)
+	}
+}
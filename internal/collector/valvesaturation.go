@@ -0,0 +1,42 @@
+package collector
+
+import (
+	"sync"
+	"time"
+)
+
+// fullHeatingPower is the heating power request of valves which are fully open.
+const fullHeatingPower = 100
+
+// valveSaturationState contains the time since when the valves of each room have been fully open without the room
+// reaching its setpoint.
+type valveSaturationState struct {
+	sync.Mutex
+	since map[string]time.Time
+}
+
+// setpointUnreachable returns 1 if the valves of the room have requested full heating power while the room was
+// below its setpoint for at least the underheating duration. The Netatmo API does not report whether a valve can
+// reach its setpoint, so this is derived from the heating power request. The time is kept across scrapes and reset
+// as soon as the valves close a bit or the room reaches its setpoint.
+func (c *ThermostatCollector) setpointUnreachable(roomID string, powerRequest, temperature, setpoint float64, now time.Time) float64 {
+	c.valveSaturationState.Lock()
+	defer c.valveSaturationState.Unlock()
+
+	if powerRequest < fullHeatingPower || temperature >= setpoint {
+		delete(c.valveSaturationState.since, roomID)
+		return 0
+	}
+
+	since, ok := c.valveSaturationState.since[roomID]
+	if !ok {
+		since = now
+		c.valveSaturationState.since[roomID] = since
+	}
+
+	if now.Sub(since) < c.underheatingDuration {
+		return 0
+	}
+
+	return 1
+}
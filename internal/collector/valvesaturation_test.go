@@ -0,0 +1,88 @@
+package collector
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sirupsen/logrus"
+)
+
+func TestThermostatCollector_ValveSetpointUnreachable(t *testing.T) {
+	client := &fakeClient{
+		homesData: mustDecode[HomesDataResponse](t, `{"body":{"homes":[{"id":"home","name":"Home","rooms":[{"id":"room","name":"Bedroom"}]}]}}`),
+	}
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	c := NewThermostatCollector(logrus.New(), client, WithUnderheating(1.5, time.Hour))
+	c.clock = func() time.Time {
+		return now
+	}
+
+	tt := []struct {
+		desc         string
+		advance      time.Duration
+		powerRequest int
+		temperature  float64
+		want         string
+	}{
+		{
+			desc:         "fully open",
+			powerRequest: 100,
+			temperature:  20.5,
+			want:         "0",
+		},
+		{
+			desc:         "fully open within duration",
+			advance:      30 * time.Minute,
+			powerRequest: 100,
+			temperature:  20.5,
+			want:         "0",
+		},
+		{
+			desc:         "fully open for duration",
+			advance:      30 * time.Minute,
+			powerRequest: 100,
+			temperature:  20.5,
+			want:         "1",
+		},
+		{
+			desc:         "partially open",
+			advance:      10 * time.Minute,
+			powerRequest: 60,
+			temperature:  20.5,
+			want:         "0",
+		},
+		{
+			desc:         "fully open again",
+			advance:      10 * time.Minute,
+			powerRequest: 100,
+			temperature:  20.5,
+			want:         "0",
+		},
+		{
+			desc:         "setpoint reached",
+			advance:      time.Hour,
+			powerRequest: 100,
+			temperature:  21,
+			want:         "0",
+		},
+	}
+
+	for _, tc := range tt {
+		now = now.Add(tc.advance)
+		client.homeStatus = map[string]*HomeStatusResponse{
+			"home": mustDecode[HomeStatusResponse](t, fmt.Sprintf(`{"body":{"home":{"id":"home","rooms":[
+				{"id":"room","therm_measured_temperature":%v,"therm_setpoint_temperature":21,"heating_power_request":%d}
+			]}}}`, tc.temperature, tc.powerRequest)),
+		}
+
+		want := `# HELP netatmo_valve_setpoint_unreachable Netatmo Energy set to 1 if the valves of a room have requested full heating power while the room stayed below its setpoint for longer than the underheating duration, 0 otherwise.
+# TYPE netatmo_valve_setpoint_unreachable gauge
+netatmo_valve_setpoint_unreachable{home_id="home",home_name="Home",room_id="room",room_name="Bedroom"} ` + tc.want + "\n"
+		if err := testutil.CollectAndCompare(c, strings.NewReader(want), "netatmo_valve_setpoint_unreachable"); err != nil {
+			t.Errorf("%s: metrics differ: %s", tc.desc, err)
+		}
+	}
+}
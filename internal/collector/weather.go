@@ -0,0 +1,610 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/url"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	weatherLabels = []string{"station_id", "module_id", "module_name"}
+
+	weatherModuleInfoDesc = newDesc(
+		prefix+"weather_module_info",
+		"Netatmo Weather module information. The bridge contains the ID of the main module a linked module connects through and is empty for main modules.",
+		append(weatherLabels, "type", "bridge"),
+	)
+
+	weatherMinTemperatureDesc = newMeasurementDesc(
+		prefix+"weather_min_temperature",
+		"Netatmo Weather minimum temperature of the current day in degrees Celsius.",
+		weatherLabels,
+	)
+
+	weatherMaxTemperatureDesc = newMeasurementDesc(
+		prefix+"weather_max_temperature",
+		"Netatmo Weather maximum temperature of the current day in degrees Celsius.",
+		weatherLabels,
+	)
+
+	weatherMinTemperatureFahrenheitDesc = newMeasurementDesc(
+		prefix+"weather_min_temperature_fahrenheit",
+		"Netatmo Weather minimum temperature of the current day in degrees Fahrenheit.",
+		weatherLabels,
+	)
+
+	weatherMaxTemperatureFahrenheitDesc = newMeasurementDesc(
+		prefix+"weather_max_temperature_fahrenheit",
+		"Netatmo Weather maximum temperature of the current day in degrees Fahrenheit.",
+		weatherLabels,
+	)
+
+	weatherMinTemperatureTimeDesc = newDesc(
+		prefix+"weather_min_temperature_time_seconds",
+		"Netatmo Weather unix timestamp when the minimum temperature of the current day was measured.",
+		weatherLabels,
+	)
+
+	weatherMaxTemperatureTimeDesc = newDesc(
+		prefix+"weather_max_temperature_time_seconds",
+		"Netatmo Weather unix timestamp when the maximum temperature of the current day was measured.",
+		weatherLabels,
+	)
+
+	indoorMinTemperatureTimeDesc = newDesc(
+		prefix+"indoor_min_temp_time_seconds",
+		"Netatmo Weather unix timestamp when an indoor module measured the minimum temperature of the current day, from the live station data.",
+		weatherLabels,
+	)
+
+	indoorMaxTemperatureTimeDesc = newDesc(
+		prefix+"indoor_max_temp_time_seconds",
+		"Netatmo Weather unix timestamp when an indoor module measured the maximum temperature of the current day, from the live station data.",
+		weatherLabels,
+	)
+
+	weatherCO2CalibratingDesc = newDesc(
+		prefix+"co2_calibrating",
+		"Netatmo Weather CO2 calibration status of an indoor module (1=calibrating, 0=normal). CO2 readings are unreliable during calibration.",
+		weatherLabels,
+	)
+
+	weatherRainAccumulatedDesc = newDesc(
+		prefix+"rain_accumulated_mm_total",
+		"Netatmo Weather rain amount in millimeters accumulated by the exporter from the daily rain sum of a rain gauge. Unlike the daily sum, this does not reset at midnight.",
+		weatherLabels,
+	)
+
+	weatherRainAccumulatedInchesDesc = newDesc(
+		prefix+"rain_accumulated_inches_total",
+		"Netatmo Weather rain amount in inches accumulated by the exporter from the daily rain sum of a rain gauge. Unlike the daily sum, this does not reset at midnight.",
+		weatherLabels,
+	)
+
+	weatherDewpointDesc = newMeasurementDesc(
+		prefix+"dewpoint_celsius",
+		"Netatmo Weather dew point in degrees Celsius calculated from the temperature and humidity of a module.",
+		weatherLabels,
+	)
+
+	weatherDewpointFahrenheitDesc = newMeasurementDesc(
+		prefix+"dewpoint_fahrenheit",
+		"Netatmo Weather dew point in degrees Fahrenheit calculated from the temperature and humidity of a module.",
+		weatherLabels,
+	)
+
+	weatherHumidexDesc = newMeasurementDesc(
+		prefix+"humidex",
+		"Netatmo Weather humidex calculated from the temperature and humidity of a module.",
+		weatherLabels,
+	)
+
+	weatherTemperatureTrendDesc = newDesc(
+		prefix+"temperature_trend",
+		"Netatmo Weather temperature trend of a module. The series of the current trend is set to 1, the others to 0.",
+		append(weatherLabels, "trend"),
+	)
+
+	weatherModuleRFStatusDesc = newDesc(
+		prefix+"weather_module_rf_status",
+		"Netatmo Weather radio signal status of a linked module (90: low, 80: medium, 70: high, 60: full).",
+		weatherLabels,
+	)
+
+	weatherModuleRFQualityDesc = newDesc(
+		prefix+"weather_module_rf_quality",
+		"Netatmo Weather radio signal quality of a linked module derived from the signal status (0: low, 1: medium, 2: high, 3: full).",
+		weatherLabels,
+	)
+
+	weatherStationUpDesc = newDesc(
+		prefix+"weather_station_up",
+		"Netatmo Weather set to 1 if the data of a station in stationsdata could be decoded, 0 otherwise. The other stations are still reported if a station can not be decoded.",
+		[]string{"station_id", "station_name"},
+	)
+
+	weatherStationReachableDesc = newDesc(
+		prefix+"weather_station_reachable",
+		"Netatmo Weather reachability of the main module of a station (1=reachable, 0=not reachable or no data stored for a while). All linked modules go stale if it is not reachable.",
+		[]string{"station_id", "station_name"},
+	)
+
+	// temperatureTrends contains the values of temp_trend reported by the Netatmo API.
+	temperatureTrends = []string{"up", "down", "stable"}
+
+	extremesTypes = []string{"min_temp", "max_temp", "date_min_temp", "date_max_temp"}
+
+	// indoorModuleTypes contains the types of the weather modules placed indoors.
+	indoorModuleTypes = []string{"NAMain", "NAModule4"}
+)
+
+// WeatherCollector is a Prometheus collector for Netatmo Weather data, which is not provided by the NetatmoCollector.
+type WeatherCollector struct {
+	log              logrus.FieldLogger
+	client           NetatmoClient
+	extremesInterval time.Duration
+	dualUnits        bool
+	attention        AttentionThresholds
+	humidex          bool
+	clock            func() time.Time
+
+	extremesLock    sync.Mutex
+	extremesUpdated time.Time
+	extremes        map[string]dailyExtremes
+
+	rainLock sync.Mutex
+	rain     map[string]*rainCounter
+}
+
+// rainCounter turns the daily rain sum of a rain gauge into a counter. The first value is only used as the
+// starting point, because the rain before the exporter was started is unknown.
+type rainCounter struct {
+	last  float64
+	total float64
+}
+
+// observe adds the increase of the daily sum to the total. A decreasing sum means that the daily sum has been
+// reset, so the whole new value is rain since the reset.
+func (r *rainCounter) observe(sum float64) {
+	if sum >= r.last {
+		r.total += sum - r.last
+	} else {
+		r.total += sum
+	}
+
+	r.last = sum
+}
+
+// dewpoint calculates the dew point in degrees Celsius from the temperature and relative humidity using the
+// Magnus formula.
+func dewpoint(temperature, humidity float64) float64 {
+	const a, b = 17.62, 243.12
+
+	gamma := math.Log(humidity/100) + a*temperature/(b+temperature)
+	return b * gamma / (a - gamma)
+}
+
+// humidex calculates the humidex of Environment Canada from the temperature and dew point in degrees Celsius.
+func humidex(temperature, dewpoint float64) float64 {
+	vapourPressure := 6.11 * math.Exp(5417.7530*(1/273.16-1/(273.15+dewpoint)))
+	return temperature + 0.5555*(vapourPressure-10)
+}
+
+// dailyExtremes contains the minimum and maximum temperature of the current day for a module.
+type dailyExtremes struct {
+	MinTemperature *float64
+	MaxTemperature *float64
+	MinTime        *float64
+	MaxTime        *float64
+}
+
+// NewWeatherCollector creates a new WeatherCollector. The daily temperature extremes are only retrieved
+// once per extremesInterval to conserve API requests. An interval of zero disables the extremes. If dualUnits is
+// set, the temperatures and rain amounts are additionally reported in imperial units. The attention thresholds are
+// used for reporting which modules need attention. If humidex is set, the humidex is reported next to the dew point.
+func NewWeatherCollector(log logrus.FieldLogger, client NetatmoClient, extremesInterval time.Duration, dualUnits bool, attention AttentionThresholds, humidex bool) *WeatherCollector {
+	return &WeatherCollector{
+		log:              log,
+		client:           client,
+		extremesInterval: extremesInterval,
+		dualUnits:        dualUnits,
+		attention:        attention,
+		humidex:          humidex,
+		clock:            time.Now,
+		extremes:         map[string]dailyExtremes{},
+		rain:             map[string]*rainCounter{},
+	}
+}
+
+// Describe implements prometheus.Collector. Only the descriptors of metrics enabled by the configuration are sent.
+func (c *WeatherCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- weatherModuleInfoDesc
+	if c.extremesInterval > 0 {
+		ch <- weatherMinTemperatureDesc
+		ch <- weatherMaxTemperatureDesc
+		ch <- weatherMinTemperatureTimeDesc
+		ch <- weatherMaxTemperatureTimeDesc
+		if c.dualUnits {
+			ch <- weatherMinTemperatureFahrenheitDesc
+			ch <- weatherMaxTemperatureFahrenheitDesc
+		}
+	}
+	ch <- indoorMinTemperatureTimeDesc
+	ch <- indoorMaxTemperatureTimeDesc
+	ch <- weatherCO2CalibratingDesc
+	ch <- weatherTemperatureTrendDesc
+	ch <- weatherModuleRFStatusDesc
+	ch <- weatherModuleRFQualityDesc
+	ch <- weatherStationUpDesc
+	ch <- weatherStationReachableDesc
+	ch <- weatherModuleNeedsAttentionDesc
+	ch <- weatherModuleTypeCodeDesc
+	ch <- weatherModuleBatteryVoltageDesc
+	ch <- weatherModuleBatteryLowDesc
+	ch <- weatherDewpointDesc
+	ch <- weatherRainAccumulatedDesc
+	if c.dualUnits {
+		ch <- weatherDewpointFahrenheitDesc
+		ch <- weatherRainAccumulatedInchesDesc
+	}
+	if c.humidex {
+		ch <- weatherHumidexDesc
+	}
+}
+
+// Collect implements prometheus.Collector.
+func (c *WeatherCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx := context.Background()
+
+	stations, err := c.client.StationsData(ctx)
+	if err != nil {
+		logAPIError(c.log, err, "WeatherCollector: error fetching stationsdata")
+		return
+	}
+
+	c.collectRain(ch, stations)
+
+	c.extremesLock.Lock()
+	defer c.extremesLock.Unlock()
+
+	now := c.clock()
+	if c.extremesInterval > 0 && now.Sub(c.extremesUpdated) >= c.extremesInterval {
+		c.refreshExtremes(ctx, stations)
+		c.extremesUpdated = now
+	}
+
+	for _, station := range stations.Body.Devices {
+		if station.decodeErr != nil {
+			c.log.Warnf("WeatherCollector: skipping station %q which could not be decoded: %s", station.ID, station.decodeErr)
+			ch <- prometheus.MustNewConstMetric(weatherStationUpDesc, prometheus.GaugeValue, 0, station.ID, station.StationName)
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(weatherStationUpDesc, prometheus.GaugeValue, 1, station.ID, station.StationName)
+
+		if reachable, ok := station.reachable(now); ok {
+			ch <- prometheus.MustNewConstMetric(weatherStationReachableDesc, prometheus.GaugeValue, reachable, station.ID, station.StationName)
+		}
+
+		for _, module := range station.allModules() {
+			labels := []string{station.ID, module.ID, module.name()}
+
+			ch <- prometheus.MustNewConstMetric(weatherModuleInfoDesc, prometheus.GaugeValue, 1, append(labels, module.Type, station.bridge(module))...)
+
+			sendNeedsAttention(ch, weatherModuleNeedsAttentionDesc, c.attention, moduleHealth{
+				BatteryPercent: module.BatteryPercent,
+				Reachable:      module.Reachable,
+				RFStrength:     module.RFStatus,
+				WifiStrength:   module.WifiStatus,
+			}, station.HomeID, station.HomeName, module.ID, module.name())
+			sendModuleTypeCode(ch, weatherModuleTypeCodeDesc, module.Type, station.HomeID, station.HomeName, module.ID, module.name())
+			sendBatteryVoltage(ch, weatherModuleBatteryVoltageDesc, module.BatteryVP, station.HomeID, station.HomeName, module.ID, module.name())
+			if module.BatteryVP != nil {
+				if low, ok := weatherBatteryLow(module.Type, *module.BatteryVP); ok {
+					ch <- prometheus.MustNewConstMetric(weatherModuleBatteryLowDesc, prometheus.GaugeValue, low, labels...)
+				}
+			}
+
+			if module.RFStatus != nil {
+				ch <- prometheus.MustNewConstMetric(weatherModuleRFStatusDesc, prometheus.GaugeValue, float64(*module.RFStatus), labels...)
+				ch <- prometheus.MustNewConstMetric(weatherModuleRFQualityDesc, prometheus.GaugeValue, rfQuality(*module.RFStatus), labels...)
+			}
+
+			if data := module.DashboardData; data.Temperature != nil && data.Humidity != nil && *data.Humidity > 0 {
+				dewpoint := dewpoint(*data.Temperature, *data.Humidity)
+				ch <- prometheus.MustNewConstMetric(weatherDewpointDesc, prometheus.GaugeValue, dewpoint, labels...)
+				if c.dualUnits {
+					ch <- prometheus.MustNewConstMetric(weatherDewpointFahrenheitDesc, prometheus.GaugeValue, celsiusToFahrenheit(dewpoint), labels...)
+				}
+
+				if c.humidex {
+					ch <- prometheus.MustNewConstMetric(weatherHumidexDesc, prometheus.GaugeValue, humidex(*data.Temperature, dewpoint), labels...)
+				}
+			}
+
+			if trend := module.DashboardData.TempTrend; trend != "" {
+				for _, t := range temperatureTrends {
+					value := 0.0
+					if t == trend {
+						value = 1.0
+					}
+					ch <- prometheus.MustNewConstMetric(weatherTemperatureTrendDesc, prometheus.GaugeValue, value, append(labels, t)...)
+				}
+			}
+
+			if slices.Contains(indoorModuleTypes, module.Type) {
+				sendOptional(ch, indoorMinTemperatureTimeDesc, module.DashboardData.DateMinTemp, labels...)
+				sendOptional(ch, indoorMaxTemperatureTimeDesc, module.DashboardData.DateMaxTemp, labels...)
+			}
+
+			if module.CO2Calibrating != nil {
+				calibrating := 0.0
+				if *module.CO2Calibrating {
+					calibrating = 1.0
+				}
+				ch <- prometheus.MustNewConstMetric(weatherCO2CalibratingDesc, prometheus.GaugeValue, calibrating, labels...)
+			}
+
+			extremes, ok := c.extremes[module.ID]
+			if !ok {
+				continue
+			}
+
+			sendOptional(ch, weatherMinTemperatureDesc, extremes.MinTemperature, labels...)
+			sendOptional(ch, weatherMaxTemperatureDesc, extremes.MaxTemperature, labels...)
+			if c.dualUnits {
+				sendOptional(ch, weatherMinTemperatureFahrenheitDesc, convertOptional(extremes.MinTemperature, celsiusToFahrenheit), labels...)
+				sendOptional(ch, weatherMaxTemperatureFahrenheitDesc, convertOptional(extremes.MaxTemperature, celsiusToFahrenheit), labels...)
+			}
+			sendOptional(ch, weatherMinTemperatureTimeDesc, extremes.MinTime, labels...)
+			sendOptional(ch, weatherMaxTemperatureTimeDesc, extremes.MaxTime, labels...)
+		}
+	}
+}
+
+func (c *WeatherCollector) collectRain(ch chan<- prometheus.Metric, stations *StationsDataResponse) {
+	c.rainLock.Lock()
+	defer c.rainLock.Unlock()
+
+	for _, station := range stations.Body.Devices {
+		for _, module := range station.allModules() {
+			sum := module.DashboardData.SumRain24
+			if sum == nil {
+				continue
+			}
+
+			counter, ok := c.rain[module.ID]
+			if !ok {
+				counter = &rainCounter{last: *sum}
+				c.rain[module.ID] = counter
+			}
+			counter.observe(*sum)
+
+			ch <- prometheus.MustNewConstMetric(
+				weatherRainAccumulatedDesc,
+				prometheus.CounterValue,
+				counter.total,
+				station.ID, module.ID, module.name(),
+			)
+
+			if c.dualUnits {
+				ch <- prometheus.MustNewConstMetric(
+					weatherRainAccumulatedInchesDesc,
+					prometheus.CounterValue,
+					mmToInches(counter.total),
+					station.ID, module.ID, module.name(),
+				)
+			}
+		}
+	}
+}
+
+// refreshExtremes replaces the cached extremes with fresh data from getmeasure. Modules which can not be
+// refreshed are dropped from the cache, so that no outdated values are reported for them.
+func (c *WeatherCollector) refreshExtremes(ctx context.Context, stations *StationsDataResponse) {
+	extremes := map[string]dailyExtremes{}
+	for _, station := range stations.Body.Devices {
+		for _, module := range station.allModules() {
+			if !slices.Contains(module.DataType, "Temperature") {
+				continue
+			}
+
+			params := MeasureRequest{
+				DeviceID: station.ID,
+				Scale:    "1day",
+				Types:    extremesTypes,
+				DateEnd:  "last",
+			}
+			if module.ID != station.ID {
+				params.ModuleID = module.ID
+			}
+
+			samples, err := c.client.Measure(ctx, params)
+			if err != nil {
+				logAPIError(c.log, err, "WeatherCollector: error fetching temperature extremes for %s", module.ID)
+				continue
+			}
+
+			if len(samples) == 0 {
+				continue
+			}
+
+			values := samples[len(samples)-1].Values
+			if len(values) != len(extremesTypes) {
+				c.log.Debugf("WeatherCollector: unexpected number of values for %s: %d", module.ID, len(values))
+				continue
+			}
+
+			extremes[module.ID] = dailyExtremes{
+				MinTemperature: values[0],
+				MaxTemperature: values[1],
+				MinTime:        values[2],
+				MaxTime:        values[3],
+			}
+		}
+	}
+
+	c.extremes = extremes
+}
+
+func sendOptional(ch chan<- prometheus.Metric, desc *prometheus.Desc, value *float64, labels ...string) {
+	if value == nil {
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, *value, labels...)
+}
+
+// StationsDataResponse contains the response of the getstationsdata endpoint.
+type StationsDataResponse struct {
+	Body struct {
+		Devices []stationDevice `json:"devices"`
+	} `json:"body"`
+}
+
+type stationModule struct {
+	ID         string   `json:"_id"`
+	Type       string   `json:"type"`
+	ModuleName string   `json:"module_name"`
+	DataType   []string `json:"data_type"`
+	// Bridge is only reported by linked modules.
+	Bridge string `json:"bridge"`
+	// CO2Calibrating is only reported by modules measuring CO2.
+	CO2Calibrating *bool `json:"co2_calibrating"`
+	// BatteryPercent, BatteryVP and RFStatus are only reported by the linked modules, WifiStatus only by the main
+	// module. BatteryVP is the battery voltage in millivolts.
+	BatteryPercent *int  `json:"battery_percent"`
+	BatteryVP      *int  `json:"battery_vp"`
+	RFStatus       *int  `json:"rf_status"`
+	WifiStatus     *int  `json:"wifi_status"`
+	Reachable      *bool `json:"reachable"`
+	DashboardData  struct {
+		// SumRain24 is the rain amount since midnight in the timezone of the station.
+		SumRain24   *float64 `json:"sum_rain_24"`
+		Temperature *float64 `json:"Temperature"`
+		Humidity    *float64 `json:"Humidity"`
+		// TempTrend is only reported by modules measuring the temperature, for example "up", "down" or "stable".
+		TempTrend string `json:"temp_trend"`
+		// DateMinTemp and DateMaxTemp are the unix timestamps of the extremes of the current day.
+		DateMinTemp *float64 `json:"date_min_temp"`
+		DateMaxTemp *float64 `json:"date_max_temp"`
+	} `json:"dashboard_data"`
+}
+
+func (m stationModule) name() string {
+	if m.ModuleName == "" {
+		return "id-" + m.ID
+	}
+
+	return m.ModuleName
+}
+
+type stationDevice struct {
+	stationModule
+	StationName string          `json:"station_name"`
+	HomeID      string          `json:"home_id"`
+	HomeName    string          `json:"home_name"`
+	Modules     []stationModule `json:"modules"`
+	// LastStatusStore is the unix timestamp of the last data stored by the station.
+	LastStatusStore *int64 `json:"last_status_store"`
+
+	// decodeErr is set if the station could not be decoded. Only the ID and name are set in this case.
+	decodeErr error
+}
+
+// UnmarshalJSON decodes a station. A station which can not be decoded does not return an error, so that the other
+// stations of the response are still decoded. Its error is kept in decodeErr instead.
+func (d *stationDevice) UnmarshalJSON(data []byte) error {
+	// station has the fields of stationDevice without this method.
+	type station stationDevice
+	var decoded station
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		var name struct {
+			ID          string `json:"_id"`
+			StationName string `json:"station_name"`
+		}
+		// The ID and name are only used for reporting the error, so they are left empty if they are malformed too.
+		_ = json.Unmarshal(data, &name)
+
+		*d = stationDevice{
+			StationName: name.StationName,
+			decodeErr:   err,
+		}
+		d.ID = name.ID
+		return nil
+	}
+
+	*d = stationDevice(decoded)
+	return nil
+}
+
+// stationStaleDuration is the time after which a station without stored data is reported as not reachable. The
+// stations normally store their data every ten minutes.
+const stationStaleDuration = 30 * time.Minute
+
+// reachable returns 1 if the main module of the station is reachable and has stored data recently, 0 otherwise. It
+// returns false if the station reports neither its reachability nor the time of the last stored data.
+func (d stationDevice) reachable(now time.Time) (float64, bool) {
+	if d.Reachable == nil && d.LastStatusStore == nil {
+		return 0, false
+	}
+
+	if d.Reachable != nil && !*d.Reachable {
+		return 0, true
+	}
+
+	if d.LastStatusStore != nil && now.Sub(time.Unix(*d.LastStatusStore, 0)) > stationStaleDuration {
+		return 0, true
+	}
+
+	return 1, true
+}
+
+// bridge returns the ID of the main module the module connects through. Linked modules without a bridge are
+// assumed to connect through the main module of the station.
+func (d stationDevice) bridge(module stationModule) string {
+	switch {
+	case module.ID == d.ID:
+		return ""
+	case module.Bridge != "":
+		return module.Bridge
+	default:
+		return d.ID
+	}
+}
+
+// allModules returns the main module of the station followed by the linked modules.
+func (d stationDevice) allModules() []stationModule {
+	return append([]stationModule{d.stationModule}, d.Modules...)
+}
+
+// StationsData implements NetatmoClient.
+func (c *httpNetatmoClient) StationsData(ctx context.Context) (*StationsDataResponse, error) {
+	var result StationsDataResponse
+	if err := c.get(ctx, "getstationsdata", url.Values{}, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// rfQuality converts the radio signal status of a weather module to the quality bands documented by Netatmo. Lower
+// values are better, values between the documented ones belong to the band of the next lower value.
+func rfQuality(status int) float64 {
+	switch {
+	case status >= 90:
+		return 0
+	case status >= 80:
+		return 1
+	case status >= 70:
+		return 2
+	default:
+		return 3
+	}
+}
@@ -0,0 +1,386 @@
+package collector
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sirupsen/logrus"
+)
+
+func TestRainCounter(t *testing.T) {
+	tt := []struct {
+		desc      string
+		sums      []float64
+		wantTotal float64
+	}{
+		{
+			desc:      "first value",
+			sums:      []float64{3.5},
+			wantTotal: 0,
+		},
+		{
+			desc:      "increasing",
+			sums:      []float64{1, 1, 2.5, 4},
+			wantTotal: 3,
+		},
+		{
+			desc:      "daily reset",
+			sums:      []float64{2, 5, 0, 1.5},
+			wantTotal: 4.5,
+		},
+		{
+			desc:      "reset with rain",
+			sums:      []float64{5, 2},
+			wantTotal: 2,
+		},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			counter := &rainCounter{last: tc.sums[0]}
+			for _, sum := range tc.sums {
+				counter.observe(sum)
+			}
+
+			if counter.total != tc.wantTotal {
+				t.Errorf("got total %f, want %f", counter.total, tc.wantTotal)
+			}
+		})
+	}
+}
+
+func TestDewpointHumidex(t *testing.T) {
+	tt := []struct {
+		desc         string
+		temperature  float64
+		humidity     float64
+		wantDewpoint float64
+		wantHumidex  float64
+	}{
+		{
+			desc:         "saturated",
+			temperature:  20,
+			humidity:     100,
+			wantDewpoint: 20,
+			wantHumidex:  27.6,
+		},
+		{
+			desc:         "indoor",
+			temperature:  21,
+			humidity:     50,
+			wantDewpoint: 10.2,
+			wantHumidex:  22.4,
+		},
+		{
+			desc:         "hot and humid",
+			temperature:  30,
+			humidity:     70,
+			wantDewpoint: 23.9,
+			wantHumidex:  41.2,
+		},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			gotDewpoint := dewpoint(tc.temperature, tc.humidity)
+			if math.Abs(gotDewpoint-tc.wantDewpoint) > 0.1 {
+				t.Errorf("got dew point %f, want %f", gotDewpoint, tc.wantDewpoint)
+			}
+
+			gotHumidex := humidex(tc.temperature, gotDewpoint)
+			if math.Abs(gotHumidex-tc.wantHumidex) > 0.1 {
+				t.Errorf("got humidex %f, want %f", gotHumidex, tc.wantHumidex)
+			}
+		})
+	}
+}
+
+func TestStationBridge(t *testing.T) {
+	station := mustDecode[StationsDataResponse](t, `{"body":{"devices":[{
+		"_id":"70:ee:50:00:00:01",
+		"modules":[
+			{"_id":"02:00:00:00:00:01","bridge":"70:ee:50:00:00:02"},
+			{"_id":"05:00:00:00:00:01"}
+		]
+	}]}}`).Body.Devices[0]
+
+	tt := []struct {
+		module     stationModule
+		wantBridge string
+	}{
+		{
+			module:     station.stationModule,
+			wantBridge: "",
+		},
+		{
+			module:     station.Modules[0],
+			wantBridge: "70:ee:50:00:00:02",
+		},
+		{
+			module:     station.Modules[1],
+			wantBridge: "70:ee:50:00:00:01",
+		},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.module.ID, func(t *testing.T) {
+			t.Parallel()
+
+			if got := station.bridge(tc.module); got != tc.wantBridge {
+				t.Errorf("got bridge %q, want %q", got, tc.wantBridge)
+			}
+		})
+	}
+}
+
+func TestWeatherCollector_TemperatureTrend(t *testing.T) {
+	client := &fakeClient{
+		stations: mustDecode[StationsDataResponse](t, `{"body":{"devices":[{
+			"_id":"70:ee:50:00:00:01",
+			"module_name":"Indoor",
+			"dashboard_data":{"temp_trend":"up"},
+			"modules":[
+				{"_id":"02:00:00:00:00:01","module_name":"Outdoor","dashboard_data":{"temp_trend":"stable"}},
+				{"_id":"05:00:00:00:00:01","module_name":"Rain","dashboard_data":{}}
+			]
+		}]}}`),
+	}
+	c := NewWeatherCollector(logrus.New(), client, 0, false, DefaultAttentionThresholds, false)
+
+	want := `# HELP netatmo_temperature_trend Netatmo Weather temperature trend of a module. The series of the current trend is set to 1, the others to 0.
+# TYPE netatmo_temperature_trend gauge
+netatmo_temperature_trend{module_id="02:00:00:00:00:01",module_name="Outdoor",station_id="70:ee:50:00:00:01",trend="down"} 0
+netatmo_temperature_trend{module_id="02:00:00:00:00:01",module_name="Outdoor",station_id="70:ee:50:00:00:01",trend="stable"} 1
+netatmo_temperature_trend{module_id="02:00:00:00:00:01",module_name="Outdoor",station_id="70:ee:50:00:00:01",trend="up"} 0
+netatmo_temperature_trend{module_id="70:ee:50:00:00:01",module_name="Indoor",station_id="70:ee:50:00:00:01",trend="down"} 0
+netatmo_temperature_trend{module_id="70:ee:50:00:00:01",module_name="Indoor",station_id="70:ee:50:00:00:01",trend="stable"} 0
+netatmo_temperature_trend{module_id="70:ee:50:00:00:01",module_name="Indoor",station_id="70:ee:50:00:00:01",trend="up"} 1
+`
+	if err := testutil.CollectAndCompare(c, strings.NewReader(want), "netatmo_temperature_trend"); err != nil {
+		t.Errorf("metrics differ: %s", err)
+	}
+}
+
+func TestRFQuality(t *testing.T) {
+	tt := []struct {
+		status int
+		want   float64
+	}{
+		{status: 95, want: 0},
+		{status: 90, want: 0},
+		{status: 85, want: 1},
+		{status: 80, want: 1},
+		{status: 70, want: 2},
+		{status: 69, want: 3},
+		{status: 60, want: 3},
+		{status: 50, want: 3},
+	}
+
+	for _, tc := range tt {
+		if got := rfQuality(tc.status); got != tc.want {
+			t.Errorf("rfQuality(%d) = %v, want %v", tc.status, got, tc.want)
+		}
+	}
+}
+
+func TestWeatherCollector_RFStatus(t *testing.T) {
+	client := &fakeClient{
+		stations: mustDecode[StationsDataResponse](t, `{"body":{"devices":[{
+			"_id":"70:ee:50:00:00:01",
+			"module_name":"Indoor",
+			"wifi_status":50,
+			"modules":[
+				{"_id":"02:00:00:00:00:01","module_name":"Outdoor","rf_status":85},
+				{"_id":"05:00:00:00:00:01","module_name":"Rain","rf_status":60}
+			]
+		}]}}`),
+	}
+	c := NewWeatherCollector(logrus.New(), client, 0, false, DefaultAttentionThresholds, false)
+
+	want := `# HELP netatmo_weather_module_rf_quality Netatmo Weather radio signal quality of a linked module derived from the signal status (0: low, 1: medium, 2: high, 3: full).
+# TYPE netatmo_weather_module_rf_quality gauge
+netatmo_weather_module_rf_quality{module_id="02:00:00:00:00:01",module_name="Outdoor",station_id="70:ee:50:00:00:01"} 1
+netatmo_weather_module_rf_quality{module_id="05:00:00:00:00:01",module_name="Rain",station_id="70:ee:50:00:00:01"} 3
+# HELP netatmo_weather_module_rf_status Netatmo Weather radio signal status of a linked module (90: low, 80: medium, 70: high, 60: full).
+# TYPE netatmo_weather_module_rf_status gauge
+netatmo_weather_module_rf_status{module_id="02:00:00:00:00:01",module_name="Outdoor",station_id="70:ee:50:00:00:01"} 85
+netatmo_weather_module_rf_status{module_id="05:00:00:00:00:01",module_name="Rain",station_id="70:ee:50:00:00:01"} 60
+`
+	if err := testutil.CollectAndCompare(c, strings.NewReader(want), "netatmo_weather_module_rf_status", "netatmo_weather_module_rf_quality"); err != nil {
+		t.Errorf("metrics differ: %s", err)
+	}
+}
+
+func TestWeatherCollector_IndoorTemperatureTimes(t *testing.T) {
+	client := &fakeClient{
+		stations: mustDecode[StationsDataResponse](t, `{"body":{"devices":[{
+			"_id":"70:ee:50:00:00:01",
+			"type":"NAMain",
+			"module_name":"Indoor",
+			"dashboard_data":{"Temperature":21.5,"date_min_temp":1700000000,"date_max_temp":1700030000},
+			"modules":[
+				{"_id":"02:00:00:00:00:01","type":"NAModule1","module_name":"Outdoor","dashboard_data":{"Temperature":5.2,"date_min_temp":1700001000,"date_max_temp":1700031000}},
+				{"_id":"03:00:00:00:00:01","type":"NAModule4","module_name":"Bedroom","dashboard_data":{"Temperature":19.1,"date_max_temp":1700032000}}
+			]
+		}]}}`),
+	}
+	c := NewWeatherCollector(logrus.New(), client, 0, false, DefaultAttentionThresholds, false)
+
+	want := `# HELP netatmo_indoor_max_temp_time_seconds Netatmo Weather unix timestamp when an indoor module measured the maximum temperature of the current day, from the live station data.
+# TYPE netatmo_indoor_max_temp_time_seconds gauge
+netatmo_indoor_max_temp_time_seconds{module_id="03:00:00:00:00:01",module_name="Bedroom",station_id="70:ee:50:00:00:01"} 1.700032e+09
+netatmo_indoor_max_temp_time_seconds{module_id="70:ee:50:00:00:01",module_name="Indoor",station_id="70:ee:50:00:00:01"} 1.70003e+09
+# HELP netatmo_indoor_min_temp_time_seconds Netatmo Weather unix timestamp when an indoor module measured the minimum temperature of the current day, from the live station data.
+# TYPE netatmo_indoor_min_temp_time_seconds gauge
+netatmo_indoor_min_temp_time_seconds{module_id="70:ee:50:00:00:01",module_name="Indoor",station_id="70:ee:50:00:00:01"} 1.7e+09
+`
+	if err := testutil.CollectAndCompare(c, strings.NewReader(want), "netatmo_indoor_min_temp_time_seconds", "netatmo_indoor_max_temp_time_seconds"); err != nil {
+		t.Errorf("metrics differ: %s", err)
+	}
+}
+
+func TestWeatherCollector_BatteryVoltage(t *testing.T) {
+	client := &fakeClient{
+		stations: mustDecode[StationsDataResponse](t, `{"body":{"devices":[{
+			"_id":"70:ee:50:00:00:01",
+			"home_id":"home",
+			"home_name":"Home",
+			"module_name":"Indoor",
+			"modules":[
+				{"_id":"02:00:00:00:00:01","module_name":"Outdoor","battery_percent":62,"battery_vp":5164}
+			]
+		}]}}`),
+	}
+	c := NewWeatherCollector(logrus.New(), client, 0, false, DefaultAttentionThresholds, false)
+
+	want := `# HELP netatmo_weather_module_battery_voltage Netatmo Weather battery voltage of a battery-powered module in volts.
+# TYPE netatmo_weather_module_battery_voltage gauge
+netatmo_weather_module_battery_voltage{home_id="home",home_name="Home",module_id="02:00:00:00:00:01",module_name="Outdoor"} 5.164
+`
+	if err := testutil.CollectAndCompare(c, strings.NewReader(want), "netatmo_weather_module_battery_voltage"); err != nil {
+		t.Errorf("metrics differ: %s", err)
+	}
+}
+
+func TestWeatherCollector_RegisterWithThermostat(t *testing.T) {
+	registry := prometheus.NewPedanticRegistry()
+	if err := registry.Register(NewThermostatCollector(logrus.New(), nil)); err != nil {
+		t.Fatalf("can not register thermostat collector: %s", err)
+	}
+
+	if err := registry.Register(NewWeatherCollector(logrus.New(), nil, 0, false, DefaultAttentionThresholds, false)); err != nil {
+		t.Errorf("can not register weather collector next to the thermostat collector: %s", err)
+	}
+}
+
+func TestWeatherCollector_BatteryLow(t *testing.T) {
+	client := &fakeClient{
+		stations: mustDecode[StationsDataResponse](t, `{"body":{"devices":[{
+			"_id":"70:ee:50:00:00:01",
+			"type":"NAMain",
+			"module_name":"Indoor",
+			"modules":[
+				{"_id":"02:00:00:00:00:01","type":"NAModule1","module_name":"Outdoor","battery_vp":4400},
+				{"_id":"06:00:00:00:00:01","type":"NAModule2","module_name":"Wind","battery_vp":4800},
+				{"_id":"03:00:00:00:00:01","type":"NAModule4","module_name":"Bedroom","battery_vp":4900},
+				{"_id":"09:00:00:00:00:01","type":"NAModule9","module_name":"Unknown","battery_vp":4000}
+			]
+		}]}}`),
+	}
+	c := NewWeatherCollector(logrus.New(), client, 0, false, DefaultAttentionThresholds, false)
+
+	want := `# HELP netatmo_weather_module_battery_low Netatmo Weather set to 1 if the battery voltage of a linked module is below the low level documented by Netatmo for its type, 0 otherwise.
+# TYPE netatmo_weather_module_battery_low gauge
+netatmo_weather_module_battery_low{module_id="02:00:00:00:00:01",module_name="Outdoor",station_id="70:ee:50:00:00:01"} 1
+netatmo_weather_module_battery_low{module_id="03:00:00:00:00:01",module_name="Bedroom",station_id="70:ee:50:00:00:01"} 1
+netatmo_weather_module_battery_low{module_id="06:00:00:00:00:01",module_name="Wind",station_id="70:ee:50:00:00:01"} 0
+`
+	if err := testutil.CollectAndCompare(c, strings.NewReader(want), "netatmo_weather_module_battery_low"); err != nil {
+		t.Errorf("metrics differ: %s", err)
+	}
+}
+
+func TestWeatherCollector_StationReachable(t *testing.T) {
+	client := &fakeClient{
+		stations: mustDecode[StationsDataResponse](t, `{"body":{"devices":[
+			{"_id":"70:ee:50:00:00:01","station_name":"Home","reachable":true,"last_status_store":1704110100},
+			{"_id":"70:ee:50:00:00:02","station_name":"Cabin","reachable":false,"last_status_store":1704110100},
+			{"_id":"70:ee:50:00:00:03","station_name":"Office","reachable":true,"last_status_store":1704106800},
+			{"_id":"70:ee:50:00:00:04","station_name":"Garden"}
+		]}}`),
+	}
+	c := NewWeatherCollector(logrus.New(), client, 0, false, DefaultAttentionThresholds, false)
+	c.clock = func() time.Time {
+		return time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	}
+
+	want := `# HELP netatmo_weather_station_reachable Netatmo Weather reachability of the main module of a station (1=reachable, 0=not reachable or no data stored for a while). All linked modules go stale if it is not reachable.
+# TYPE netatmo_weather_station_reachable gauge
+netatmo_weather_station_reachable{station_id="70:ee:50:00:00:01",station_name="Home"} 1
+netatmo_weather_station_reachable{station_id="70:ee:50:00:00:02",station_name="Cabin"} 0
+netatmo_weather_station_reachable{station_id="70:ee:50:00:00:03",station_name="Office"} 0
+`
+	if err := testutil.CollectAndCompare(c, strings.NewReader(want), "netatmo_weather_station_reachable"); err != nil {
+		t.Errorf("metrics differ: %s", err)
+	}
+}
+
+func TestWeatherCollector_StationUp(t *testing.T) {
+	client := &fakeClient{
+		stations: mustDecode[StationsDataResponse](t, `{"body":{"devices":[
+			{"_id":"70:ee:50:00:00:01","station_name":"Home","module_name":"Indoor","rf_status":85},
+			{"_id":"70:ee:50:00:00:02","station_name":"Cabin","wifi_status":"strong","modules":[
+				{"_id":"02:00:00:00:00:02","module_name":"Outdoor","rf_status":60}
+			]}
+		]}}`),
+	}
+	c := NewWeatherCollector(logrus.New(), client, 0, false, DefaultAttentionThresholds, false)
+
+	want := `# HELP netatmo_weather_module_rf_status Netatmo Weather radio signal status of a linked module (90: low, 80: medium, 70: high, 60: full).
+# TYPE netatmo_weather_module_rf_status gauge
+netatmo_weather_module_rf_status{module_id="70:ee:50:00:00:01",module_name="Indoor",station_id="70:ee:50:00:00:01"} 85
+# HELP netatmo_weather_station_up Netatmo Weather set to 1 if the data of a station in stationsdata could be decoded, 0 otherwise. The other stations are still reported if a station can not be decoded.
+# TYPE netatmo_weather_station_up gauge
+netatmo_weather_station_up{station_id="70:ee:50:00:00:01",station_name="Home"} 1
+netatmo_weather_station_up{station_id="70:ee:50:00:00:02",station_name="Cabin"} 0
+`
+	if err := testutil.CollectAndCompare(c, strings.NewReader(want), "netatmo_weather_station_up", "netatmo_weather_module_rf_status"); err != nil {
+		t.Errorf("metrics differ: %s", err)
+	}
+}
+
+func TestWeatherCollector_CO2Calibrating(t *testing.T) {
+	client := &fakeClient{
+		stations: mustDecode[StationsDataResponse](t, `{"body":{"devices":[{
+			"_id":"70:ee:50:00:00:01",
+			"type":"NAMain",
+			"module_name":"Indoor",
+			"co2_calibrating":true,
+			"modules":[
+				{"_id":"03:00:00:00:00:01","type":"NAModule4","module_name":"Bedroom","co2_calibrating":false},
+				{"_id":"02:00:00:00:00:01","type":"NAModule1","module_name":"Outdoor"}
+			]
+		}]}}`),
+	}
+	c := NewWeatherCollector(logrus.New(), client, 0, false, DefaultAttentionThresholds, false)
+
+	want := `# HELP netatmo_co2_calibrating Netatmo Weather CO2 calibration status of an indoor module (1=calibrating, 0=normal). CO2 readings are unreliable during calibration.
+# TYPE netatmo_co2_calibrating gauge
+netatmo_co2_calibrating{module_id="03:00:00:00:00:01",module_name="Bedroom",station_id="70:ee:50:00:00:01"} 0
+netatmo_co2_calibrating{module_id="70:ee:50:00:00:01",module_name="Indoor",station_id="70:ee:50:00:00:01"} 1
+`
+	if err := testutil.CollectAndCompare(c, strings.NewReader(want), "netatmo_co2_calibrating"); err != nil {
+		t.Errorf("metrics differ: %s", err)
+	}
+}
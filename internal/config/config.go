@@ -4,6 +4,11 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/exzz/netatmo-api-go"
@@ -12,28 +17,178 @@ import (
 )
 
 const (
-	envVarListenAddress       = "NETATMO_EXPORTER_ADDR"
-	envVarExternalURL         = "NETATMO_EXPORTER_EXTERNAL_URL"
-	envVarTokenFile           = "NETATMO_EXPORTER_TOKEN_FILE"
-	envVarDebugHandlers       = "DEBUG_HANDLERS"
-	envVarLogLevel            = "NETATMO_LOG_LEVEL"
-	envVarRefreshInterval     = "NETATMO_REFRESH_INTERVAL"
-	envVarStaleDuration       = "NETATMO_AGE_STALE"
-	envVarNetatmoClientID     = "NETATMO_CLIENT_ID"
-	envVarNetatmoClientSecret = "NETATMO_CLIENT_SECRET"
-
-	flagListenAddress       = "addr"
-	flagExternalURL         = "external-url"
-	flagTokenFile           = "token-file"
-	flagDebugHandlers       = "debug-handlers"
-	flagLogLevel            = "log-level"
-	flagRefreshInterval     = "refresh-interval"
-	flagStaleDuration       = "age-stale"
-	flagNetatmoClientID     = "client-id"
-	flagNetatmoClientSecret = "client-secret"
+	envVarListenAddress        = "NETATMO_EXPORTER_ADDR"
+	envVarExternalURL          = "NETATMO_EXPORTER_EXTERNAL_URL"
+	envVarTokenFile            = "NETATMO_EXPORTER_TOKEN_FILE"
+	envVarDebugHandlers        = "DEBUG_HANDLERS"
+	envVarDebugRooms           = "NETATMO_DEBUG_ROOMS"
+	envVarRuntimeMetrics       = "NETATMO_RUNTIME_METRICS"
+	envVarPushGateway          = "NETATMO_PUSH_GATEWAY"
+	envVarPushJob              = "NETATMO_PUSH_JOB"
+	envVarMQTTBroker           = "NETATMO_MQTT_BROKER"
+	envVarMQTTTopicPrefix      = "NETATMO_MQTT_TOPIC_PREFIX"
+	envVarMQTTUsername         = "NETATMO_MQTT_USERNAME"
+	envVarMQTTPassword         = "NETATMO_MQTT_PASSWORD"
+	envVarLogLevel             = "NETATMO_LOG_LEVEL"
+	envVarRefreshInterval      = "NETATMO_REFRESH_INTERVAL"
+	envVarStaleDuration        = "NETATMO_AGE_STALE"
+	envVarNetatmoClientID      = "NETATMO_CLIENT_ID"
+	envVarNetatmoClientSecret  = "NETATMO_CLIENT_SECRET"
+	envVarWeatherCollector     = "NETATMO_WEATHER_COLLECTOR"
+	envVarWeatherExtremes      = "NETATMO_WEATHER_EXTREMES_INTERVAL"
+	envVarWeatherHumidex       = "NETATMO_WEATHER_HUMIDEX"
+	envVarWeatherInterval      = "NETATMO_WEATHER_INTERVAL"
+	envVarEnableMetrics        = "NETATMO_ENABLE_METRICS"
+	envVarDisableMetrics       = "NETATMO_DISABLE_METRICS"
+	envVarPublicDataArea       = "NETATMO_PUBLIC_DATA_AREA"
+	envVarPrecision            = "NETATMO_PRECISION"
+	envVarMinimalLabels        = "NETATMO_MINIMAL_LABELS"
+	envVarMetricNaming         = "NETATMO_METRIC_NAMING"
+	envVarMetricNames          = "NETATMO_METRIC_NAMES"
+	envVarMetricPrefix         = "NETATMO_METRIC_PREFIX"
+	envVarInstanceName         = "NETATMO_INSTANCE_NAME"
+	envVarReplayDir            = "NETATMO_REPLAY_DIR"
+	envVarFailUntilReady       = "NETATMO_FAIL_UNTIL_READY"
+	envVarBoilerOnInterval     = "NETATMO_BOILER_ON_INTERVAL"
+	envVarBoilerSampleInterval = "NETATMO_BOILER_SAMPLE_INTERVAL"
+	envVarExcludeHomes         = "NETATMO_EXCLUDE_HOMES"
+	envVarIncludeHomes         = "NETATMO_INCLUDE_HOMES"
+	envVarHomeStatusDelay      = "NETATMO_HOME_STATUS_DELAY"
+	envVarHomeStatusRetries    = "NETATMO_HOME_STATUS_RETRIES"
+	envVarHomeRetryDelay       = "NETATMO_HOME_STATUS_RETRY_DELAY"
+	envVarHomeStatusCache      = "NETATMO_HOME_STATUS_CACHE_TTL"
+	envVarHomeConcurrency      = "NETATMO_HOME_STATUS_CONCURRENCY"
+	envVarDeviceTypes          = "NETATMO_HOME_STATUS_DEVICE_TYPES"
+	envVarHomesDataInterval    = "NETATMO_HOMES_DATA_INTERVAL"
+	envVarThermostatInterval   = "NETATMO_THERMOSTAT_INTERVAL"
+	envVarDualUnits            = "NETATMO_DUAL_UNITS"
+	envVarCameraCollector      = "NETATMO_CAMERA_COLLECTOR"
+	envVarLegacyThermostat     = "NETATMO_LEGACY_THERMOSTAT_COLLECTOR"
+	envVarRequestTimeout       = "NETATMO_REQUEST_TIMEOUT"
+	envVarCollectTimeout       = "NETATMO_COLLECT_TIMEOUT"
+	envVarAPIRetries           = "NETATMO_API_RETRIES"
+	envVarAPIRetryDelay        = "NETATMO_API_RETRY_DELAY"
+	envVarAPIRetryMaxDelay     = "NETATMO_API_RETRY_MAX_DELAY"
+	envVarAPIURL               = "NETATMO_API_URL"
+	envVarOAuthAuthURL         = "NETATMO_OAUTH_AUTH_URL"
+	envVarOAuthTokenURL        = "NETATMO_OAUTH_TOKEN_URL"
+	envVarAPIMaxIdleConns      = "NETATMO_API_MAX_IDLE_CONNS"
+	envVarAPIMaxIdlePerHost    = "NETATMO_API_MAX_IDLE_CONNS_PER_HOST"
+	envVarAPIIdleConnTimeout   = "NETATMO_API_IDLE_CONN_TIMEOUT"
+	envVarAPILatencyPerHome    = "NETATMO_API_LATENCY_PER_HOME"
+	envVarMaxHomes             = "NETATMO_MAX_HOMES"
+	envVarRoomComfortScore     = "NETATMO_ROOM_COMFORT_SCORE"
+	envVarMissingAsNaN         = "NETATMO_MISSING_AS_NAN"
+	envVarRoomHeatingTime      = "NETATMO_ROOM_HEATING_TIME"
+	envVarUnderheatThreshold   = "NETATMO_UNDERHEATING_THRESHOLD"
+	envVarUnderheatDuration    = "NETATMO_UNDERHEATING_DURATION"
+	envVarHeatingSeason        = "NETATMO_HEATING_SEASON_WINDOW"
+	envVarBoilerStatusMode     = "NETATMO_BOILER_STATUS_MODE"
+	envVarBoilerAvailable      = "NETATMO_BOILER_STATUS_AVAILABLE"
+	envVarAttentionBattery     = "NETATMO_ATTENTION_BATTERY_PERCENT"
+	envVarAttentionRF          = "NETATMO_ATTENTION_RF_STRENGTH"
+	envVarAttentionWifi        = "NETATMO_ATTENTION_WIFI_STRENGTH"
+
+	flagListenAddress        = "addr"
+	flagExternalURL          = "external-url"
+	flagTokenFile            = "token-file"
+	flagDebugHandlers        = "debug-handlers"
+	flagDebugRooms           = "debug-rooms"
+	flagRuntimeMetrics       = "runtime-metrics"
+	flagPushGateway          = "push-gateway"
+	flagPushJob              = "push-job"
+	flagMQTTBroker           = "mqtt-broker"
+	flagMQTTTopicPrefix      = "mqtt-topic-prefix"
+	flagMQTTUsername         = "mqtt-username"
+	flagMQTTPassword         = "mqtt-password"
+	flagLogLevel             = "log-level"
+	flagRefreshInterval      = "refresh-interval"
+	flagStaleDuration        = "age-stale"
+	flagNetatmoClientID      = "client-id"
+	flagNetatmoClientSecret  = "client-secret"
+	flagWeatherCollector     = "weather-collector"
+	flagWeatherExtremes      = "weather-extremes-interval"
+	flagWeatherHumidex       = "weather-humidex"
+	flagWeatherInterval      = "weather-interval"
+	flagEnableMetric         = "enable-metric"
+	flagDisableMetric        = "disable-metric"
+	flagPublicDataArea       = "public-data-area"
+	flagPrecision            = "precision"
+	flagMinimalLabels        = "minimal-labels"
+	flagMetricNaming         = "metric-naming"
+	flagMetricName           = "metric-name"
+	flagMetricPrefix         = "metric-prefix"
+	flagInstanceName         = "instance-name"
+	flagReplayDir            = "replay-dir"
+	flagFailUntilReady       = "fail-until-ready"
+	flagBoilerOnInterval     = "boiler-on-interval"
+	flagBoilerSampleInterval = "boiler-sample-interval"
+	flagExcludeHomes         = "exclude-homes"
+	flagIncludeHomes         = "include-homes"
+	flagHomeStatusDelay      = "home-status-delay"
+	flagHomeStatusRetries    = "home-status-retries"
+	flagHomeRetryDelay       = "home-status-retry-delay"
+	flagHomeStatusCache      = "home-status-cache-ttl"
+	flagHomeConcurrency      = "home-status-concurrency"
+	flagDeviceTypes          = "home-status-device-types"
+	flagHomesDataInterval    = "homes-data-interval"
+	flagThermostatInterval   = "thermostat-interval"
+	flagDualUnits            = "dual-units"
+	flagCameraCollector      = "camera-collector"
+	flagLegacyThermostat     = "legacy-thermostat-collector"
+	flagRequestTimeout       = "request-timeout"
+	flagCollectTimeout       = "collect-timeout"
+	flagAPIRetries           = "api-retries"
+	flagAPIRetryDelay        = "api-retry-delay"
+	flagAPIRetryMaxDelay     = "api-retry-max-delay"
+	flagAPIURL               = "api-url"
+	flagOAuthAuthURL         = "oauth-auth-url"
+	flagOAuthTokenURL        = "oauth-token-url"
+	flagAPIMaxIdleConns      = "api-max-idle-conns"
+	flagAPIMaxIdlePerHost    = "api-max-idle-conns-per-host"
+	flagAPIIdleConnTimeout   = "api-idle-conn-timeout"
+	flagAPILatencyPerHome    = "api-latency-per-home"
+	flagMaxHomes             = "max-homes"
+	flagRoomComfortScore     = "room-comfort-score"
+	flagMissingAsNaN         = "missing-as-nan"
+	flagRoomHeatingTime      = "room-heating-time"
+	flagUnderheatThreshold   = "underheating-threshold"
+	flagUnderheatDuration    = "underheating-duration"
+	flagHeatingSeason        = "heating-season-window"
+	flagBoilerStatusMode     = "boiler-status-mode"
+	flagBoilerAvailable      = "boiler-status-available"
+	flagAttentionBattery     = "attention-battery-percent"
+	flagAttentionRF          = "attention-rf-strength"
+	flagAttentionWifi        = "attention-wifi-strength"
 
 	defaultRefreshInterval = 8 * time.Minute
 	defaultStaleDuration   = 60 * time.Minute
+	defaultWeatherExtremes = time.Hour
+	defaultRequestTimeout  = 5 * time.Second
+	defaultCollectTimeout  = 30 * time.Second
+	defaultAPIRetries      = 3
+	defaultAPIRetryDelay   = 500 * time.Millisecond
+	defaultAPIRetryMax     = 10 * time.Second
+	defaultAPIMaxIdleConns = 10
+	defaultAPIIdleTimeout  = 5 * time.Minute
+	defaultHomeRetryDelay  = 2 * time.Second
+	defaultHomeConcurrency = 4
+	defaultHeatingSeason   = 6 * time.Hour
+	defaultBoilerStatus    = "mixed"
+	defaultMetricNaming    = "default"
+	defaultMetricPrefix    = "netatmo_"
+	defaultPushJob         = "netatmo_exporter"
+	defaultMQTTTopicPrefix = "netatmo"
+
+	defaultUnderheatingThreshold = 1.5
+	defaultUnderheatingDuration  = time.Hour
+
+	// minBoilerSample limits the boiler sampling, which makes one request per home, to conserve the rate-limit.
+	minBoilerSample = time.Minute
+
+	defaultAttentionBattery = 10
+	defaultAttentionRF      = 90
+	defaultAttentionWifi    = 86
 )
 
 var (
@@ -42,6 +197,33 @@ var (
 		LogLevel:        logLevel(logrus.InfoLevel),
 		RefreshInterval: defaultRefreshInterval,
 		StaleDuration:   defaultStaleDuration,
+		WeatherExtremes: defaultWeatherExtremes,
+		RequestTimeout:  defaultRequestTimeout,
+		CollectTimeout:  defaultCollectTimeout,
+		APIRetries:      defaultAPIRetries,
+		APIRetryDelay:   defaultAPIRetryDelay,
+
+		APIRetryMaxDelay:       defaultAPIRetryMax,
+		APIMaxIdleConns:        defaultAPIMaxIdleConns,
+		APIMaxIdleConnsPerHost: defaultAPIMaxIdleConns,
+		APIIdleConnTimeout:     defaultAPIIdleTimeout,
+		Precision:              -1,
+		MetricNaming:           defaultMetricNaming,
+		MetricPrefix:           defaultMetricPrefix,
+		RuntimeMetrics:         true,
+		PushJob:                defaultPushJob,
+		MQTTTopicPrefix:        defaultMQTTTopicPrefix,
+
+		BoilerStatusMode:      defaultBoilerStatus,
+		HomeStatusRetryDelay:  defaultHomeRetryDelay,
+		HomeStatusConcurrency: defaultHomeConcurrency,
+		UnderheatingThreshold: defaultUnderheatingThreshold,
+		UnderheatingDuration:  defaultUnderheatingDuration,
+		HeatingSeasonWindow:   defaultHeatingSeason,
+
+		AttentionBattery: defaultAttentionBattery,
+		AttentionRF:      defaultAttentionRF,
+		AttentionWifi:    defaultAttentionWifi,
 	}
 
 	errNoBinaryName          = errors.New("need the binary name as first argument")
@@ -49,6 +231,12 @@ var (
 	errNoTokenFile           = errors.New("need a token file to save the token")
 	errNoNetatmoClientID     = errors.New("need a NetAtmo client ID")
 	errNoNetatmoClientSecret = errors.New("need a NetAtmo client secret")
+	errNoPushJob             = errors.New("need a job name for pushing to the Pushgateway")
+
+	boilerStatusModes = []string{"mixed", "room", "boiler"}
+	metricNamings     = []string{"default", "energy-prefix", "celsius-suffix"}
+
+	metricNamePattern = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
 )
 
 type logLevel logrus.Level
@@ -71,16 +259,137 @@ func (l *logLevel) Set(value string) error {
 	return nil
 }
 
+// Area describes a geographic area using the coordinates of its south-west and north-east corners.
+type Area struct {
+	LatSW, LonSW float64
+	LatNE, LonNE float64
+}
+
+func (a *Area) Type() string {
+	return "area"
+}
+
+func (a *Area) String() string {
+	if a.IsZero() {
+		return ""
+	}
+
+	return fmt.Sprintf("%g,%g,%g,%g", a.LatSW, a.LonSW, a.LatNE, a.LonNE)
+}
+
+func (a *Area) Set(value string) error {
+	parts := strings.Split(value, ",")
+	if len(parts) != 4 {
+		return fmt.Errorf("area needs four coordinates, got %d", len(parts))
+	}
+
+	coords := make([]float64, len(parts))
+	for i, part := range parts {
+		coord, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return fmt.Errorf("invalid coordinate %q: %w", part, err)
+		}
+		coords[i] = coord
+	}
+
+	if coords[0] >= coords[2] || coords[1] >= coords[3] {
+		return errors.New("south-west corner needs to be south and west of the north-east corner")
+	}
+
+	*a = Area{
+		LatSW: coords[0],
+		LonSW: coords[1],
+		LatNE: coords[2],
+		LonNE: coords[3],
+	}
+	return nil
+}
+
+// IsZero returns true, if no area has been set.
+func (a Area) IsZero() bool {
+	return a == Area{}
+}
+
 // Config contains the configuration options.
 type Config struct {
 	Addr            string
 	ExternalURL     string
 	TokenFile       string
 	DebugHandlers   bool
+	DebugRooms      bool
+	RuntimeMetrics  bool
+	PushGateway     string
+	PushJob         string
+	MQTTBroker      string
+	MQTTTopicPrefix string
+	MQTTUsername    string
+	MQTTPassword    string
 	LogLevel        logLevel
 	RefreshInterval time.Duration
 	StaleDuration   time.Duration
 	Netatmo         netatmo.Config
+	EnabledMetrics  []string
+	DisabledMetrics []string
+	Precision       int
+	MinimalLabels   bool
+	MetricNaming    string
+	MetricNames     map[string]string
+	MetricPrefix    string
+	InstanceName    string
+	ReplayDir       string
+	FailUntilReady  bool
+	RequestTimeout  time.Duration
+	CollectTimeout  time.Duration
+	APIRetries      int
+	APIRetryDelay   time.Duration
+
+	APIRetryMaxDelay       time.Duration
+	APIURL                 string
+	OAuthAuthURL           string
+	OAuthTokenURL          string
+	APIMaxIdleConns        int
+	APIMaxIdleConnsPerHost int
+	APIIdleConnTimeout     time.Duration
+
+	APILatencyPerHome bool
+
+	WeatherCollector bool
+	WeatherExtremes  time.Duration
+	WeatherHumidex   bool
+	WeatherInterval  time.Duration
+
+	PublicDataArea Area
+
+	CameraCollector bool
+
+	LegacyThermostat bool
+
+	AttentionBattery int
+	AttentionRF      int
+	AttentionWifi    int
+
+	BoilerOnInterval      time.Duration
+	BoilerSampleInterval  time.Duration
+	ExcludeHomes          string
+	IncludeHomes          []string
+	HomeStatusDelay       time.Duration
+	HomeStatusRetries     int
+	HomeStatusRetryDelay  time.Duration
+	HomeStatusCacheTTL    time.Duration
+	HomeStatusConcurrency int
+	HomeStatusDeviceTypes []string
+	HomesDataInterval     time.Duration
+	ThermostatInterval    time.Duration
+	DualUnits             bool
+	MaxHomes              int
+	RoomComfortScore      bool
+	MissingAsNaN          bool
+	RoomHeatingTime       bool
+	UnderheatingThreshold float64
+	UnderheatingDuration  time.Duration
+	HeatingSeasonWindow   time.Duration
+	BoilerStatusMode      string
+	BoilerStatusAvailable bool
 }
 
 // Parse takes the arguments and environment variables provided and creates the Config from that.
@@ -96,11 +405,73 @@ func Parse(args []string, getEnv func(string) string) (Config, error) {
 	flagSet.StringVar(&cfg.ExternalURL, flagExternalURL, cfg.ExternalURL, "External URL to use as base for OAuth redirect URL.")
 	flagSet.StringVar(&cfg.TokenFile, flagTokenFile, cfg.TokenFile, "Path to token file for loading/persisting authentication token.")
 	flagSet.BoolVar(&cfg.DebugHandlers, flagDebugHandlers, cfg.DebugHandlers, "Enables debugging HTTP handlers.")
+	flagSet.BoolVar(&cfg.DebugRooms, flagDebugRooms, cfg.DebugRooms, "Logs the parsed status of every room on the debug log level before the thermostat metrics are created.")
+	flagSet.BoolVar(&cfg.RuntimeMetrics, flagRuntimeMetrics, cfg.RuntimeMetrics, "Reports the Go runtime and process metrics of the exporter itself. Use --runtime-metrics=false to disable them.")
+	flagSet.StringVar(&cfg.PushGateway, flagPushGateway, cfg.PushGateway, "URL of a Prometheus Pushgateway. If set, the metrics are collected once, pushed to the Pushgateway and the exporter exits.")
+	flagSet.StringVar(&cfg.PushJob, flagPushJob, cfg.PushJob, "Job name used when pushing the metrics to the Pushgateway.")
+	flagSet.StringVar(&cfg.MQTTBroker, flagMQTTBroker, cfg.MQTTBroker, "URL of an MQTT broker, for example \"tcp://localhost:1883\". If set, the state of the thermostats is additionally published to the broker.")
+	flagSet.StringVar(&cfg.MQTTTopicPrefix, flagMQTTTopicPrefix, cfg.MQTTTopicPrefix, "First level of the MQTT topics the thermostat state is published to.")
+	flagSet.StringVar(&cfg.MQTTUsername, flagMQTTUsername, cfg.MQTTUsername, "Username for the MQTT broker.")
+	flagSet.StringVar(&cfg.MQTTPassword, flagMQTTPassword, cfg.MQTTPassword, "Password for the MQTT broker.")
 	flagSet.Var(&cfg.LogLevel, flagLogLevel, "Sets the minimum level output through logging.")
 	flagSet.DurationVar(&cfg.RefreshInterval, flagRefreshInterval, cfg.RefreshInterval, "Time interval used for internal caching of NetAtmo sensor data.")
 	flagSet.DurationVar(&cfg.StaleDuration, flagStaleDuration, cfg.StaleDuration, "Data age to consider as stale. Stale data does not create metrics anymore.")
 	flagSet.StringVarP(&cfg.Netatmo.ClientID, flagNetatmoClientID, "i", cfg.Netatmo.ClientID, "Client ID for NetAtmo app.")
 	flagSet.StringVarP(&cfg.Netatmo.ClientSecret, flagNetatmoClientSecret, "s", cfg.Netatmo.ClientSecret, "Client secret for NetAtmo app.")
+	flagSet.StringSliceVar(&cfg.EnabledMetrics, flagEnableMetric, cfg.EnabledMetrics, "Only emit the metrics with these names (without \"netatmo_\" or the metric prefix). Can be repeated.")
+	flagSet.StringSliceVar(&cfg.DisabledMetrics, flagDisableMetric, cfg.DisabledMetrics, "Do not emit the metrics with these names (without \"netatmo_\" or the metric prefix). Can be repeated.")
+	flagSet.DurationVar(&cfg.RequestTimeout, flagRequestTimeout, cfg.RequestTimeout, "Timeout for a single request to the NetAtmo API. Zero disables the timeout.")
+	flagSet.DurationVar(&cfg.CollectTimeout, flagCollectTimeout, cfg.CollectTimeout, "Timeout for collecting the thermostat metrics of all homes. Zero disables the timeout.")
+	flagSet.IntVar(&cfg.APIRetries, flagAPIRetries, cfg.APIRetries, "Number of retries of requests to the NetAtmo API failing with a transient error. Zero disables retries.")
+	flagSet.DurationVar(&cfg.APIRetryDelay, flagAPIRetryDelay, cfg.APIRetryDelay, "Delay before the first retry of a request to the NetAtmo API. The delay is doubled for every further retry.")
+	flagSet.DurationVar(&cfg.APIRetryMaxDelay, flagAPIRetryMaxDelay, cfg.APIRetryMaxDelay, "Maximum delay before a retry of a request to the NetAtmo API. Requests are not retried if the API asks to wait longer. Zero disables the limit.")
+	flagSet.StringVar(&cfg.APIURL, flagAPIURL, cfg.APIURL, "Base URL of the NetAtmo API, for example of a proxy or a test server. Uses \"https://api.netatmo.com/api/\" by default.")
+	flagSet.StringVar(&cfg.OAuthAuthURL, flagOAuthAuthURL, cfg.OAuthAuthURL, "URL of the OAuth authorization endpoint, for example of a test server. Uses \"https://api.netatmo.com/oauth2/authorize\" by default.")
+	flagSet.StringVar(&cfg.OAuthTokenURL, flagOAuthTokenURL, cfg.OAuthTokenURL, "URL of the OAuth token endpoint used to create and refresh tokens, for example of a test server. Uses \"https://api.netatmo.com/oauth2/token\" by default.")
+	flagSet.IntVar(&cfg.APIMaxIdleConns, flagAPIMaxIdleConns, cfg.APIMaxIdleConns, "Maximum number of idle connections kept open for reuse by requests to the NetAtmo API. Zero means no limit.")
+	flagSet.IntVar(&cfg.APIMaxIdleConnsPerHost, flagAPIMaxIdlePerHost, cfg.APIMaxIdleConnsPerHost, "Maximum number of idle connections kept open for reuse per host of the NetAtmo API.")
+	flagSet.DurationVar(&cfg.APIIdleConnTimeout, flagAPIIdleConnTimeout, cfg.APIIdleConnTimeout, "Time after which idle connections to the NetAtmo API are closed. Zero keeps them open until the server closes them.")
+	flagSet.BoolVar(&cfg.APILatencyPerHome, flagAPILatencyPerHome, cfg.APILatencyPerHome, "Additionally labels the duration of homestatus requests with the home ID. Only recommended for accounts with few homes.")
+	flagSet.IntVar(&cfg.Precision, flagPrecision, cfg.Precision, "Number of decimal places measured values, like temperatures, are rounded to. Timestamps and ratios are not rounded. Negative values disable rounding.")
+	flagSet.BoolVar(&cfg.MinimalLabels, flagMinimalLabels, cfg.MinimalLabels, "Removes the names of homes, rooms, modules and cameras from the metrics and reports them in separate info metrics.")
+	flagSet.StringVar(&cfg.MetricNaming, flagMetricNaming, cfg.MetricNaming, "Selects how metrics are named: \"default\" keeps the names, \"energy-prefix\" uses a \"netatmo_energy_\" prefix for the Netatmo Energy metrics and \"celsius-suffix\" adds a \"_celsius\" suffix to temperatures in degrees Celsius.")
+	flagSet.StringToStringVar(&cfg.MetricNames, flagMetricName, cfg.MetricNames, "Reports the metric with a default name under a custom name, given as \"default=custom\" using the full names. Can be repeated.")
+	flagSet.StringVar(&cfg.MetricPrefix, flagMetricPrefix, cfg.MetricPrefix, "Prefix of the metric names, which replaces \"netatmo_\" in the names of the Netatmo Energy metrics.")
+	flagSet.StringVar(&cfg.InstanceName, flagInstanceName, cfg.InstanceName, "Adds an \"instance_name\" label with this value to all metrics of the exporter.")
+	flagSet.StringVar(&cfg.ReplayDir, flagReplayDir, cfg.ReplayDir, "Serves the responses recorded in this directory to the collectors instead of requesting the NetAtmo API, for testing the exporter offline.")
+	flagSet.BoolVar(&cfg.FailUntilReady, flagFailUntilReady, cfg.FailUntilReady, "Fails scrapes of the metrics with status 503 until data has been collected successfully for the first time.")
+	flagSet.BoolVar(&cfg.WeatherCollector, flagWeatherCollector, cfg.WeatherCollector, "Enables the additional weather collector, which makes its own requests to the NetAtmo API.")
+	flagSet.DurationVar(&cfg.WeatherExtremes, flagWeatherExtremes, cfg.WeatherExtremes, "Time interval for retrieving the daily temperature extremes of weather modules. Zero disables the extremes.")
+	flagSet.BoolVar(&cfg.WeatherHumidex, flagWeatherHumidex, cfg.WeatherHumidex, "Additionally reports the humidex of weather modules calculated from temperature and humidity.")
+	flagSet.DurationVar(&cfg.WeatherInterval, flagWeatherInterval, cfg.WeatherInterval, "Time interval for collecting the metrics of the weather collector in the background. Zero collects them on every scrape.")
+	flagSet.Var(&cfg.PublicDataArea, flagPublicDataArea, "Enables collecting data of public weather stations in an area given as \"lat_sw,lon_sw,lat_ne,lon_ne\".")
+	flagSet.BoolVar(&cfg.CameraCollector, flagCameraCollector, cfg.CameraCollector, "Enables the camera collector reporting persons and events of Netatmo Security cameras.")
+	flagSet.BoolVar(&cfg.LegacyThermostat, flagLegacyThermostat, cfg.LegacyThermostat, "Enables the collector for first-generation Netatmo thermostats using the legacy getthermostatsdata endpoint.")
+	flagSet.DurationVar(&cfg.BoilerOnInterval, flagBoilerOnInterval, cfg.BoilerOnInterval, "Time interval for retrieving the time the boiler was switched on by thermostats. Zero disables the boiler on-time.")
+	flagSet.DurationVar(&cfg.BoilerSampleInterval, flagBoilerSampleInterval, cfg.BoilerSampleInterval, "Time interval for additionally sampling the boiler status of all homes between scrapes for the boiler duty cycle. Needs to be at least one minute. Zero disables the duty cycle.")
+	flagSet.StringVar(&cfg.ExcludeHomes, flagExcludeHomes, cfg.ExcludeHomes, "Regular expression matching the names of homes to exclude from the thermostat metrics, for example demo homes.")
+	flagSet.StringSliceVar(&cfg.IncludeHomes, flagIncludeHomes, cfg.IncludeHomes, "Only collect the thermostat metrics of the homes with these IDs or names. Collects all homes by default.")
+	flagSet.DurationVar(&cfg.HomeStatusDelay, flagHomeStatusDelay, cfg.HomeStatusDelay, "Delay between the status requests of successive homes to avoid the rate-limit of the NetAtmo API.")
+	flagSet.IntVar(&cfg.HomeStatusRetries, flagHomeStatusRetries, cfg.HomeStatusRetries, "Number of additional retries of the status request of a single home failing with a server error. Zero disables these retries.")
+	flagSet.DurationVar(&cfg.HomeStatusRetryDelay, flagHomeRetryDelay, cfg.HomeStatusRetryDelay, "Delay before each additional retry of the status request of a single home.")
+	flagSet.DurationVar(&cfg.HomeStatusCacheTTL, flagHomeStatusCache, cfg.HomeStatusCacheTTL, "Time for which the status of a home is reused by further scrapes instead of requesting it again. Zero disables the cache.")
+	flagSet.IntVar(&cfg.HomeStatusConcurrency, flagHomeConcurrency, cfg.HomeStatusConcurrency, "Number of homes whose status is requested at the same time. One requests the homes one after another.")
+	flagSet.StringSliceVar(&cfg.HomeStatusDeviceTypes, flagDeviceTypes, cfg.HomeStatusDeviceTypes, "Only request the modules of these types, for example \"NAPlug,NATherm1,NRV\", in the status requests of the homes. Requests all modules by default.")
+	flagSet.DurationVar(&cfg.HomesDataInterval, flagHomesDataInterval, cfg.HomesDataInterval, "Time interval for retrieving the mostly static list of homes, rooms and schedules. The status of the homes is still retrieved on every scrape. Zero retrieves the list on every scrape.")
+	flagSet.DurationVar(&cfg.ThermostatInterval, flagThermostatInterval, cfg.ThermostatInterval, "Time interval for collecting the metrics of the thermostat collector in the background. Zero collects them on every scrape.")
+	flagSet.IntVar(&cfg.MaxHomes, flagMaxHomes, cfg.MaxHomes, "Maximum number of homes collected per scrape. Additional homes are collected round-robin in later scrapes. Zero disables the limit.")
+	flagSet.BoolVar(&cfg.RoomComfortScore, flagRoomComfortScore, cfg.RoomComfortScore, "Reports a comfort score for each room approximated from temperature and humidity.")
+	flagSet.BoolVar(&cfg.MissingAsNaN, flagMissingAsNaN, cfg.MissingAsNaN, "Reports the temperature and setpoint of rooms not reporting them as NaN instead of omitting them.")
+	flagSet.BoolVar(&cfg.RoomHeatingTime, flagRoomHeatingTime, cfg.RoomHeatingTime, "Reports the estimated time each room has been heating since local midnight.")
+	flagSet.Float64Var(&cfg.UnderheatingThreshold, flagUnderheatThreshold, cfg.UnderheatingThreshold, "Degrees Celsius a room needs to be below its setpoint to be considered underheating. Zero disables the underheating metric.")
+	flagSet.DurationVar(&cfg.UnderheatingDuration, flagUnderheatDuration, cfg.UnderheatingDuration, "Time a room needs to be below its setpoint by more than the underheating threshold to be reported as underheating.")
+	flagSet.DurationVar(&cfg.HeatingSeasonWindow, flagHeatingSeason, cfg.HeatingSeasonWindow, "Time window in which a room of a home needs to have called for heat for the home to be reported in the heating season. Zero disables the metric.")
+	flagSet.StringVar(&cfg.BoilerStatusMode, flagBoilerStatusMode, cfg.BoilerStatusMode, "Selects the thermostat boiler status reported: \"mixed\" per room where possible and per home otherwise, \"room\" the heating demand of each room or \"boiler\" the boiler state per home.")
+	flagSet.BoolVar(&cfg.BoilerStatusAvailable, flagBoilerAvailable, cfg.BoilerStatusAvailable, "Reports for each home whether any module reports a boiler status.")
+	flagSet.IntVar(&cfg.AttentionBattery, flagAttentionBattery, cfg.AttentionBattery, "Battery level in percent at or below which a weather module needs attention. Zero disables the check.")
+	flagSet.IntVar(&cfg.AttentionRF, flagAttentionRF, cfg.AttentionRF, "Radio signal strength at or above which a module needs attention (90: lowest, 60: highest). Zero disables the check.")
+	flagSet.IntVar(&cfg.AttentionWifi, flagAttentionWifi, cfg.AttentionWifi, "Wi-Fi signal strength at or above which a module needs attention (86: bad, 56: good). Zero disables the check.")
+	flagSet.BoolVar(&cfg.DualUnits, flagDualUnits, cfg.DualUnits, "Additionally reports temperatures in degrees Fahrenheit, wind strength in miles per hour and rain in inches.")
 
 	if err := flagSet.Parse(args[1:]); err != nil {
 		return Config{}, err
@@ -139,10 +510,137 @@ func Parse(args []string, getEnv func(string) string) (Config, error) {
 		return Config{}, errNoNetatmoClientSecret
 	}
 
+	if cfg.PushGateway != "" {
+		u, err := url.Parse(cfg.PushGateway)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid Pushgateway URL: %w", err)
+		}
+
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return Config{}, fmt.Errorf("invalid Pushgateway URL %q: needs to use http or https", cfg.PushGateway)
+		}
+
+		if cfg.PushJob == "" {
+			return Config{}, errNoPushJob
+		}
+	}
+
+	if cfg.APIURL != "" {
+		u, err := url.Parse(cfg.APIURL)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid API URL: %w", err)
+		}
+
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return Config{}, fmt.Errorf("invalid API URL %q: needs to use http or https", cfg.APIURL)
+		}
+
+		// The endpoints are appended to the base URL.
+		if !strings.HasSuffix(cfg.APIURL, "/") {
+			cfg.APIURL += "/"
+		}
+	}
+
+	if cfg.OAuthAuthURL != "" {
+		u, err := url.Parse(cfg.OAuthAuthURL)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid OAuth authorization URL: %w", err)
+		}
+
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return Config{}, fmt.Errorf("invalid OAuth authorization URL %q: needs to use http or https", cfg.OAuthAuthURL)
+		}
+	}
+
+	if cfg.OAuthTokenURL != "" {
+		u, err := url.Parse(cfg.OAuthTokenURL)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid OAuth token URL: %w", err)
+		}
+
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return Config{}, fmt.Errorf("invalid OAuth token URL %q: needs to use http or https", cfg.OAuthTokenURL)
+		}
+	}
+
+	if cfg.MQTTBroker != "" {
+		if _, err := url.Parse(cfg.MQTTBroker); err != nil {
+			return Config{}, fmt.Errorf("invalid MQTT broker URL: %w", err)
+		}
+
+		if cfg.MQTTTopicPrefix == "" || strings.ContainsAny(cfg.MQTTTopicPrefix, "+#") {
+			return Config{}, fmt.Errorf("invalid MQTT topic prefix %q: needs to be non-empty without wildcards", cfg.MQTTTopicPrefix)
+		}
+	}
+
+	if cfg.UnderheatingThreshold < 0 {
+		return Config{}, fmt.Errorf("underheating threshold can not be negative: %v", cfg.UnderheatingThreshold)
+	}
+
+	if cfg.UnderheatingDuration < 0 {
+		return Config{}, fmt.Errorf("underheating duration can not be negative: %s", cfg.UnderheatingDuration)
+	}
+
+	if cfg.HeatingSeasonWindow < 0 {
+		return Config{}, fmt.Errorf("heating season window can not be negative: %s", cfg.HeatingSeasonWindow)
+	}
+
+	if cfg.HomeStatusRetries < 0 {
+		return Config{}, fmt.Errorf("number of home status retries can not be negative: %d", cfg.HomeStatusRetries)
+	}
+
+	if cfg.HomeStatusConcurrency < 1 {
+		return Config{}, fmt.Errorf("home status concurrency needs to be at least one: %d", cfg.HomeStatusConcurrency)
+	}
+
+	if cfg.APIRetries < 0 {
+		return Config{}, fmt.Errorf("number of API retries can not be negative: %d", cfg.APIRetries)
+	}
+
+	if cfg.APIMaxIdleConns < 0 || cfg.APIMaxIdleConnsPerHost < 0 {
+		return Config{}, fmt.Errorf("number of idle API connections can not be negative: %d, %d per host", cfg.APIMaxIdleConns, cfg.APIMaxIdleConnsPerHost)
+	}
+
 	if cfg.StaleDuration < cfg.RefreshInterval {
 		return Config{}, fmt.Errorf("stale duration smaller than refresh interval: %s < %s", cfg.StaleDuration, cfg.RefreshInterval)
 	}
 
+	if cfg.WeatherExtremes != 0 && cfg.WeatherExtremes < cfg.RefreshInterval {
+		return Config{}, fmt.Errorf("weather extremes interval smaller than refresh interval: %s < %s", cfg.WeatherExtremes, cfg.RefreshInterval)
+	}
+
+	if cfg.BoilerOnInterval != 0 && cfg.BoilerOnInterval < cfg.RefreshInterval {
+		return Config{}, fmt.Errorf("boiler on-time interval smaller than refresh interval: %s < %s", cfg.BoilerOnInterval, cfg.RefreshInterval)
+	}
+
+	if cfg.BoilerSampleInterval != 0 && cfg.BoilerSampleInterval < minBoilerSample {
+		return Config{}, fmt.Errorf("boiler sample interval smaller than %s: %s", minBoilerSample, cfg.BoilerSampleInterval)
+	}
+
+	if !slices.Contains(boilerStatusModes, cfg.BoilerStatusMode) {
+		return Config{}, fmt.Errorf("invalid boiler status mode %q, needs to be one of %s", cfg.BoilerStatusMode, strings.Join(boilerStatusModes, ", "))
+	}
+
+	if !slices.Contains(metricNamings, cfg.MetricNaming) {
+		return Config{}, fmt.Errorf("invalid metric naming %q, needs to be one of %s", cfg.MetricNaming, strings.Join(metricNamings, ", "))
+	}
+
+	for name, custom := range cfg.MetricNames {
+		if !metricNamePattern.MatchString(name) || !metricNamePattern.MatchString(custom) {
+			return Config{}, fmt.Errorf("invalid metric name mapping %q=%q", name, custom)
+		}
+	}
+
+	if !metricNamePattern.MatchString(cfg.MetricPrefix) {
+		return Config{}, fmt.Errorf("invalid metric prefix %q", cfg.MetricPrefix)
+	}
+
+	if cfg.ExcludeHomes != "" {
+		if _, err := regexp.Compile(cfg.ExcludeHomes); err != nil {
+			return Config{}, fmt.Errorf("invalid pattern for excluded homes: %w", err)
+		}
+	}
+
 	return cfg, nil
 }
 
@@ -160,7 +658,54 @@ func applyEnvironment(cfg *Config, getenv func(string) string) error {
 	}
 
 	if envDebugHandlers := getenv(envVarDebugHandlers); envDebugHandlers != "" {
-		cfg.DebugHandlers = true
+		enabled, err := strconv.ParseBool(envDebugHandlers)
+		if err != nil {
+			return err
+		}
+
+		cfg.DebugHandlers = enabled
+	}
+
+	if envDebugRooms := getenv(envVarDebugRooms); envDebugRooms != "" {
+		enabled, err := strconv.ParseBool(envDebugRooms)
+		if err != nil {
+			return err
+		}
+
+		cfg.DebugRooms = enabled
+	}
+
+	if envRuntimeMetrics := getenv(envVarRuntimeMetrics); envRuntimeMetrics != "" {
+		enabled, err := strconv.ParseBool(envRuntimeMetrics)
+		if err != nil {
+			return err
+		}
+
+		cfg.RuntimeMetrics = enabled
+	}
+
+	if envPushGateway := getenv(envVarPushGateway); envPushGateway != "" {
+		cfg.PushGateway = envPushGateway
+	}
+
+	if envPushJob := getenv(envVarPushJob); envPushJob != "" {
+		cfg.PushJob = envPushJob
+	}
+
+	if envMQTTBroker := getenv(envVarMQTTBroker); envMQTTBroker != "" {
+		cfg.MQTTBroker = envMQTTBroker
+	}
+
+	if envMQTTTopicPrefix := getenv(envVarMQTTTopicPrefix); envMQTTTopicPrefix != "" {
+		cfg.MQTTTopicPrefix = envMQTTTopicPrefix
+	}
+
+	if envMQTTUsername := getenv(envVarMQTTUsername); envMQTTUsername != "" {
+		cfg.MQTTUsername = envMQTTUsername
+	}
+
+	if envMQTTPassword := getenv(envVarMQTTPassword); envMQTTPassword != "" {
+		cfg.MQTTPassword = envMQTTPassword
 	}
 
 	if envLogLevel := getenv(envVarLogLevel); envLogLevel != "" {
@@ -195,5 +740,439 @@ func applyEnvironment(cfg *Config, getenv func(string) string) error {
 		cfg.Netatmo.ClientSecret = envClientSecret
 	}
 
+	if envPublicDataArea := getenv(envVarPublicDataArea); envPublicDataArea != "" {
+		if err := cfg.PublicDataArea.Set(envPublicDataArea); err != nil {
+			return err
+		}
+	}
+
+	if envEnableMetrics := getenv(envVarEnableMetrics); envEnableMetrics != "" {
+		cfg.EnabledMetrics = splitList(envEnableMetrics)
+	}
+
+	if envDisableMetrics := getenv(envVarDisableMetrics); envDisableMetrics != "" {
+		cfg.DisabledMetrics = splitList(envDisableMetrics)
+	}
+
+	if envPrecision := getenv(envVarPrecision); envPrecision != "" {
+		precision, err := strconv.Atoi(envPrecision)
+		if err != nil {
+			return err
+		}
+
+		cfg.Precision = precision
+	}
+
+	if envMinimalLabels := getenv(envVarMinimalLabels); envMinimalLabels != "" {
+		enabled, err := strconv.ParseBool(envMinimalLabels)
+		if err != nil {
+			return err
+		}
+
+		cfg.MinimalLabels = enabled
+	}
+
+	if envMetricNaming := getenv(envVarMetricNaming); envMetricNaming != "" {
+		cfg.MetricNaming = envMetricNaming
+	}
+
+	if envMetricNames := getenv(envVarMetricNames); envMetricNames != "" {
+		names := map[string]string{}
+		for _, item := range splitList(envMetricNames) {
+			name, custom, ok := strings.Cut(item, "=")
+			if !ok {
+				return fmt.Errorf("metric name mapping needs to be \"default=custom\": %q", item)
+			}
+
+			names[strings.TrimSpace(name)] = strings.TrimSpace(custom)
+		}
+
+		cfg.MetricNames = names
+	}
+
+	if envMetricPrefix := getenv(envVarMetricPrefix); envMetricPrefix != "" {
+		cfg.MetricPrefix = envMetricPrefix
+	}
+
+	if envInstanceName := getenv(envVarInstanceName); envInstanceName != "" {
+		cfg.InstanceName = envInstanceName
+	}
+
+	if envReplayDir := getenv(envVarReplayDir); envReplayDir != "" {
+		cfg.ReplayDir = envReplayDir
+	}
+
+	if envFailUntilReady := getenv(envVarFailUntilReady); envFailUntilReady != "" {
+		enabled, err := strconv.ParseBool(envFailUntilReady)
+		if err != nil {
+			return err
+		}
+
+		cfg.FailUntilReady = enabled
+	}
+
+	if envRequestTimeout := getenv(envVarRequestTimeout); envRequestTimeout != "" {
+		duration, err := time.ParseDuration(envRequestTimeout)
+		if err != nil {
+			return err
+		}
+
+		cfg.RequestTimeout = duration
+	}
+
+	if envCollectTimeout := getenv(envVarCollectTimeout); envCollectTimeout != "" {
+		duration, err := time.ParseDuration(envCollectTimeout)
+		if err != nil {
+			return err
+		}
+
+		cfg.CollectTimeout = duration
+	}
+
+	if envAPIRetries := getenv(envVarAPIRetries); envAPIRetries != "" {
+		retries, err := strconv.Atoi(envAPIRetries)
+		if err != nil {
+			return err
+		}
+
+		cfg.APIRetries = retries
+	}
+
+	if envAPIRetryDelay := getenv(envVarAPIRetryDelay); envAPIRetryDelay != "" {
+		duration, err := time.ParseDuration(envAPIRetryDelay)
+		if err != nil {
+			return err
+		}
+
+		cfg.APIRetryDelay = duration
+	}
+
+	if envAPIRetryMaxDelay := getenv(envVarAPIRetryMaxDelay); envAPIRetryMaxDelay != "" {
+		duration, err := time.ParseDuration(envAPIRetryMaxDelay)
+		if err != nil {
+			return err
+		}
+
+		cfg.APIRetryMaxDelay = duration
+	}
+
+	if envAPIURL := getenv(envVarAPIURL); envAPIURL != "" {
+		cfg.APIURL = envAPIURL
+	}
+
+	if envOAuthAuthURL := getenv(envVarOAuthAuthURL); envOAuthAuthURL != "" {
+		cfg.OAuthAuthURL = envOAuthAuthURL
+	}
+
+	if envOAuthTokenURL := getenv(envVarOAuthTokenURL); envOAuthTokenURL != "" {
+		cfg.OAuthTokenURL = envOAuthTokenURL
+	}
+
+	if envMaxIdleConns := getenv(envVarAPIMaxIdleConns); envMaxIdleConns != "" {
+		conns, err := strconv.Atoi(envMaxIdleConns)
+		if err != nil {
+			return err
+		}
+
+		cfg.APIMaxIdleConns = conns
+	}
+
+	if envMaxIdlePerHost := getenv(envVarAPIMaxIdlePerHost); envMaxIdlePerHost != "" {
+		conns, err := strconv.Atoi(envMaxIdlePerHost)
+		if err != nil {
+			return err
+		}
+
+		cfg.APIMaxIdleConnsPerHost = conns
+	}
+
+	if envIdleConnTimeout := getenv(envVarAPIIdleConnTimeout); envIdleConnTimeout != "" {
+		duration, err := time.ParseDuration(envIdleConnTimeout)
+		if err != nil {
+			return err
+		}
+
+		cfg.APIIdleConnTimeout = duration
+	}
+
+	if envAPILatencyPerHome := getenv(envVarAPILatencyPerHome); envAPILatencyPerHome != "" {
+		enabled, err := strconv.ParseBool(envAPILatencyPerHome)
+		if err != nil {
+			return err
+		}
+
+		cfg.APILatencyPerHome = enabled
+	}
+
+	if envWeatherCollector := getenv(envVarWeatherCollector); envWeatherCollector != "" {
+		enabled, err := strconv.ParseBool(envWeatherCollector)
+		if err != nil {
+			return err
+		}
+
+		cfg.WeatherCollector = enabled
+	}
+
+	if envWeatherExtremes := getenv(envVarWeatherExtremes); envWeatherExtremes != "" {
+		duration, err := time.ParseDuration(envWeatherExtremes)
+		if err != nil {
+			return err
+		}
+
+		cfg.WeatherExtremes = duration
+	}
+
+	if envWeatherHumidex := getenv(envVarWeatherHumidex); envWeatherHumidex != "" {
+		enabled, err := strconv.ParseBool(envWeatherHumidex)
+		if err != nil {
+			return err
+		}
+
+		cfg.WeatherHumidex = enabled
+	}
+
+	if envWeatherInterval := getenv(envVarWeatherInterval); envWeatherInterval != "" {
+		duration, err := time.ParseDuration(envWeatherInterval)
+		if err != nil {
+			return err
+		}
+
+		cfg.WeatherInterval = duration
+	}
+
+	if envBoilerOnInterval := getenv(envVarBoilerOnInterval); envBoilerOnInterval != "" {
+		duration, err := time.ParseDuration(envBoilerOnInterval)
+		if err != nil {
+			return err
+		}
+
+		cfg.BoilerOnInterval = duration
+	}
+
+	if envBoilerSampleInterval := getenv(envVarBoilerSampleInterval); envBoilerSampleInterval != "" {
+		duration, err := time.ParseDuration(envBoilerSampleInterval)
+		if err != nil {
+			return err
+		}
+
+		cfg.BoilerSampleInterval = duration
+	}
+
+	if envExcludeHomes := getenv(envVarExcludeHomes); envExcludeHomes != "" {
+		cfg.ExcludeHomes = envExcludeHomes
+	}
+
+	if envIncludeHomes := getenv(envVarIncludeHomes); envIncludeHomes != "" {
+		cfg.IncludeHomes = splitList(envIncludeHomes)
+	}
+
+	if envHomeStatusDelay := getenv(envVarHomeStatusDelay); envHomeStatusDelay != "" {
+		duration, err := time.ParseDuration(envHomeStatusDelay)
+		if err != nil {
+			return err
+		}
+
+		cfg.HomeStatusDelay = duration
+	}
+
+	if envHomeStatusRetries := getenv(envVarHomeStatusRetries); envHomeStatusRetries != "" {
+		retries, err := strconv.Atoi(envHomeStatusRetries)
+		if err != nil {
+			return err
+		}
+
+		cfg.HomeStatusRetries = retries
+	}
+
+	if envHomeRetryDelay := getenv(envVarHomeRetryDelay); envHomeRetryDelay != "" {
+		duration, err := time.ParseDuration(envHomeRetryDelay)
+		if err != nil {
+			return err
+		}
+
+		cfg.HomeStatusRetryDelay = duration
+	}
+
+	if envHomeStatusCache := getenv(envVarHomeStatusCache); envHomeStatusCache != "" {
+		duration, err := time.ParseDuration(envHomeStatusCache)
+		if err != nil {
+			return err
+		}
+
+		cfg.HomeStatusCacheTTL = duration
+	}
+
+	if envHomeConcurrency := getenv(envVarHomeConcurrency); envHomeConcurrency != "" {
+		concurrency, err := strconv.Atoi(envHomeConcurrency)
+		if err != nil {
+			return err
+		}
+
+		cfg.HomeStatusConcurrency = concurrency
+	}
+
+	if envDeviceTypes := getenv(envVarDeviceTypes); envDeviceTypes != "" {
+		cfg.HomeStatusDeviceTypes = splitList(envDeviceTypes)
+	}
+
+	if envHomesDataInterval := getenv(envVarHomesDataInterval); envHomesDataInterval != "" {
+		duration, err := time.ParseDuration(envHomesDataInterval)
+		if err != nil {
+			return err
+		}
+
+		cfg.HomesDataInterval = duration
+	}
+
+	if envThermostatInterval := getenv(envVarThermostatInterval); envThermostatInterval != "" {
+		duration, err := time.ParseDuration(envThermostatInterval)
+		if err != nil {
+			return err
+		}
+
+		cfg.ThermostatInterval = duration
+	}
+
+	if envDualUnits := getenv(envVarDualUnits); envDualUnits != "" {
+		enabled, err := strconv.ParseBool(envDualUnits)
+		if err != nil {
+			return err
+		}
+
+		cfg.DualUnits = enabled
+	}
+
+	if envMaxHomes := getenv(envVarMaxHomes); envMaxHomes != "" {
+		maxHomes, err := strconv.Atoi(envMaxHomes)
+		if err != nil {
+			return err
+		}
+
+		cfg.MaxHomes = maxHomes
+	}
+
+	if envRoomComfortScore := getenv(envVarRoomComfortScore); envRoomComfortScore != "" {
+		enabled, err := strconv.ParseBool(envRoomComfortScore)
+		if err != nil {
+			return err
+		}
+
+		cfg.RoomComfortScore = enabled
+	}
+
+	if envMissingAsNaN := getenv(envVarMissingAsNaN); envMissingAsNaN != "" {
+		enabled, err := strconv.ParseBool(envMissingAsNaN)
+		if err != nil {
+			return err
+		}
+
+		cfg.MissingAsNaN = enabled
+	}
+
+	if envRoomHeatingTime := getenv(envVarRoomHeatingTime); envRoomHeatingTime != "" {
+		enabled, err := strconv.ParseBool(envRoomHeatingTime)
+		if err != nil {
+			return err
+		}
+
+		cfg.RoomHeatingTime = enabled
+	}
+
+	if envUnderheatingThreshold := getenv(envVarUnderheatThreshold); envUnderheatingThreshold != "" {
+		threshold, err := strconv.ParseFloat(envUnderheatingThreshold, 64)
+		if err != nil {
+			return err
+		}
+
+		cfg.UnderheatingThreshold = threshold
+	}
+
+	if envUnderheatingDuration := getenv(envVarUnderheatDuration); envUnderheatingDuration != "" {
+		duration, err := time.ParseDuration(envUnderheatingDuration)
+		if err != nil {
+			return err
+		}
+
+		cfg.UnderheatingDuration = duration
+	}
+
+	if envHeatingSeason := getenv(envVarHeatingSeason); envHeatingSeason != "" {
+		duration, err := time.ParseDuration(envHeatingSeason)
+		if err != nil {
+			return err
+		}
+
+		cfg.HeatingSeasonWindow = duration
+	}
+
+	if envBoilerStatusMode := getenv(envVarBoilerStatusMode); envBoilerStatusMode != "" {
+		cfg.BoilerStatusMode = envBoilerStatusMode
+	}
+
+	if envBoilerAvailable := getenv(envVarBoilerAvailable); envBoilerAvailable != "" {
+		enabled, err := strconv.ParseBool(envBoilerAvailable)
+		if err != nil {
+			return err
+		}
+
+		cfg.BoilerStatusAvailable = enabled
+	}
+
+	if envAttentionBattery := getenv(envVarAttentionBattery); envAttentionBattery != "" {
+		threshold, err := strconv.Atoi(envAttentionBattery)
+		if err != nil {
+			return err
+		}
+
+		cfg.AttentionBattery = threshold
+	}
+
+	if envAttentionRF := getenv(envVarAttentionRF); envAttentionRF != "" {
+		threshold, err := strconv.Atoi(envAttentionRF)
+		if err != nil {
+			return err
+		}
+
+		cfg.AttentionRF = threshold
+	}
+
+	if envAttentionWifi := getenv(envVarAttentionWifi); envAttentionWifi != "" {
+		threshold, err := strconv.Atoi(envAttentionWifi)
+		if err != nil {
+			return err
+		}
+
+		cfg.AttentionWifi = threshold
+	}
+
+	if envCameraCollector := getenv(envVarCameraCollector); envCameraCollector != "" {
+		enabled, err := strconv.ParseBool(envCameraCollector)
+		if err != nil {
+			return err
+		}
+
+		cfg.CameraCollector = enabled
+	}
+
+	if envLegacyThermostat := getenv(envVarLegacyThermostat); envLegacyThermostat != "" {
+		enabled, err := strconv.ParseBool(envLegacyThermostat)
+		if err != nil {
+			return err
+		}
+
+		cfg.LegacyThermostat = enabled
+	}
+
 	return nil
 }
+
+func splitList(value string) []string {
+	var result []string
+	for _, item := range strings.Split(value, ",") {
+		if item := strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+
+	return result
+}
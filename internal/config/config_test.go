@@ -47,6 +47,32 @@ func TestParseConfig(t *testing.T) {
 					ClientID:     "id",
 					ClientSecret: "secret",
 				},
+				Precision:       -1,
+				MetricNaming:    defaultMetricNaming,
+				MetricPrefix:    defaultMetricPrefix,
+				RuntimeMetrics:  true,
+				PushJob:         defaultPushJob,
+				MQTTTopicPrefix: defaultMQTTTopicPrefix,
+				WeatherExtremes: defaultWeatherExtremes,
+				RequestTimeout:  defaultRequestTimeout,
+				CollectTimeout:  defaultCollectTimeout,
+				APIRetries:      defaultAPIRetries,
+				APIRetryDelay:   defaultAPIRetryDelay,
+
+				APIRetryMaxDelay:       defaultAPIRetryMax,
+				APIMaxIdleConns:        defaultAPIMaxIdleConns,
+				APIMaxIdleConnsPerHost: defaultAPIMaxIdleConns,
+				APIIdleConnTimeout:     defaultAPIIdleTimeout,
+
+				BoilerStatusMode:      defaultBoilerStatus,
+				HomeStatusRetryDelay:  defaultHomeRetryDelay,
+				HomeStatusConcurrency: defaultHomeConcurrency,
+				UnderheatingThreshold: defaultUnderheatingThreshold,
+				UnderheatingDuration:  defaultUnderheatingDuration,
+				HeatingSeasonWindow:   defaultHeatingSeason,
+				AttentionBattery:      defaultAttentionBattery,
+				AttentionRF:           defaultAttentionRF,
+				AttentionWifi:         defaultAttentionWifi,
 			},
 			wantErr: nil,
 		},
@@ -56,14 +82,76 @@ func TestParseConfig(t *testing.T) {
 				"test-cmd",
 			},
 			env: map[string]string{
-				envVarListenAddress:       ":8080",
-				envVarExternalURL:         "http://example.com",
-				envVarTokenFile:           "token.json",
-				envVarLogLevel:            "debug",
-				envVarRefreshInterval:     "5m",
-				envVarStaleDuration:       "10m",
-				envVarNetatmoClientID:     "id",
-				envVarNetatmoClientSecret: "secret",
+				envVarListenAddress:        ":8080",
+				envVarExternalURL:          "http://example.com",
+				envVarTokenFile:            "token.json",
+				envVarLogLevel:             "debug",
+				envVarRefreshInterval:      "5m",
+				envVarStaleDuration:        "10m",
+				envVarNetatmoClientID:      "id",
+				envVarNetatmoClientSecret:  "secret",
+				envVarDisableMetrics:       "up, thermostat_boiler_status",
+				envVarWeatherCollector:     "true",
+				envVarWeatherExtremes:      "2h",
+				envVarWeatherHumidex:       "true",
+				envVarWeatherInterval:      "1m",
+				envVarPublicDataArea:       "48.1,11.5,48.2,11.6",
+				envVarPrecision:            "1",
+				envVarMinimalLabels:        "true",
+				envVarMetricNaming:         "energy-prefix",
+				envVarMetricNames:          "netatmo_up=netatmo_sensor_up, netatmo_thermostat_temperature=netatmo_room_temperature_celsius",
+				envVarMetricPrefix:         "company_netatmo_",
+				envVarInstanceName:         "upstairs",
+				envVarReplayDir:            "recorded",
+				envVarFailUntilReady:       "true",
+				envVarBoilerOnInterval:     "1h",
+				envVarBoilerSampleInterval: "2m",
+				envVarExcludeHomes:         "^Demo",
+				envVarIncludeHomes:         "home1, Home 2",
+				envVarHomeStatusDelay:      "500ms",
+				envVarHomeStatusRetries:    "2",
+				envVarHomeRetryDelay:       "5s",
+				envVarHomeStatusCache:      "2m",
+				envVarHomeConcurrency:      "4",
+				envVarDeviceTypes:          "NAPlug, NATherm1,NRV",
+				envVarRuntimeMetrics:       "false",
+				envVarPushGateway:          "http://pushgateway:9091",
+				envVarPushJob:              "netatmo",
+				envVarMQTTBroker:           "tcp://mqtt:1883",
+				envVarMQTTTopicPrefix:      "home/netatmo",
+				envVarMQTTUsername:         "exporter",
+				envVarMQTTPassword:         "password",
+				envVarHomesDataInterval:    "30m",
+				envVarThermostatInterval:   "10m",
+				envVarDualUnits:            "true",
+				envVarMaxHomes:             "3",
+				envVarRoomComfortScore:     "true",
+				envVarMissingAsNaN:         "true",
+				envVarRoomHeatingTime:      "true",
+				envVarUnderheatThreshold:   "2.5",
+				envVarUnderheatDuration:    "2h",
+				envVarHeatingSeason:        "12h",
+				envVarCameraCollector:      "true",
+				envVarLegacyThermostat:     "true",
+				envVarRequestTimeout:       "2s",
+				envVarCollectTimeout:       "1m",
+				envVarAPIRetries:           "1",
+				envVarAPIRetryDelay:        "2s",
+				envVarAPIRetryMaxDelay:     "30s",
+				envVarAPIURL:               "http://proxy.example.com/api",
+				envVarOAuthAuthURL:         "http://auth.example.com/authorize",
+				envVarOAuthTokenURL:        "http://auth.example.com/token",
+				envVarAPIMaxIdleConns:      "20",
+				envVarAPIMaxIdlePerHost:    "5",
+				envVarAPIIdleConnTimeout:   "1m",
+				envVarAPILatencyPerHome:    "true",
+				envVarDebugRooms:           "true",
+				envVarDebugHandlers:        "1",
+				envVarBoilerStatusMode:     "room",
+				envVarBoilerAvailable:      "true",
+				envVarAttentionBattery:     "20",
+				envVarAttentionRF:          "80",
+				envVarAttentionWifi:        "0",
 			},
 			wantConfig: Config{
 				Addr:            ":8080",
@@ -76,6 +164,76 @@ func TestParseConfig(t *testing.T) {
 					ClientID:     "id",
 					ClientSecret: "secret",
 				},
+				DisabledMetrics: []string{"up", "thermostat_boiler_status"},
+				Precision:       1,
+				MinimalLabels:   true,
+				MetricNaming:    "energy-prefix",
+				MetricNames: map[string]string{
+					"netatmo_up":                     "netatmo_sensor_up",
+					"netatmo_thermostat_temperature": "netatmo_room_temperature_celsius",
+				},
+				MetricPrefix:      "company_netatmo_",
+				InstanceName:      "upstairs",
+				ReplayDir:         "recorded",
+				FailUntilReady:    true,
+				PushGateway:       "http://pushgateway:9091",
+				PushJob:           "netatmo",
+				MQTTBroker:        "tcp://mqtt:1883",
+				MQTTTopicPrefix:   "home/netatmo",
+				MQTTUsername:      "exporter",
+				MQTTPassword:      "password",
+				RequestTimeout:    2 * time.Second,
+				CollectTimeout:    time.Minute,
+				APIRetries:        1,
+				APIRetryDelay:     2 * time.Second,
+				APIRetryMaxDelay:  30 * time.Second,
+				APIURL:            "http://proxy.example.com/api/",
+				OAuthAuthURL:      "http://auth.example.com/authorize",
+				OAuthTokenURL:     "http://auth.example.com/token",
+				APILatencyPerHome: true,
+				DebugRooms:        true,
+				DebugHandlers:     true,
+				WeatherCollector:  true,
+				WeatherExtremes:   2 * time.Hour,
+				WeatherHumidex:    true,
+				WeatherInterval:   time.Minute,
+
+				APIMaxIdleConns:        20,
+				APIMaxIdleConnsPerHost: 5,
+				APIIdleConnTimeout:     time.Minute,
+				PublicDataArea: Area{
+					LatSW: 48.1,
+					LonSW: 11.5,
+					LatNE: 48.2,
+					LonNE: 11.6,
+				},
+				CameraCollector:       true,
+				LegacyThermostat:      true,
+				AttentionBattery:      20,
+				AttentionRF:           80,
+				AttentionWifi:         0,
+				BoilerOnInterval:      time.Hour,
+				BoilerSampleInterval:  2 * time.Minute,
+				ExcludeHomes:          "^Demo",
+				IncludeHomes:          []string{"home1", "Home 2"},
+				HomeStatusDelay:       500 * time.Millisecond,
+				HomeStatusRetries:     2,
+				HomeStatusRetryDelay:  5 * time.Second,
+				HomeStatusCacheTTL:    2 * time.Minute,
+				HomeStatusConcurrency: 4,
+				HomeStatusDeviceTypes: []string{"NAPlug", "NATherm1", "NRV"},
+				HomesDataInterval:     30 * time.Minute,
+				ThermostatInterval:    10 * time.Minute,
+				DualUnits:             true,
+				MaxHomes:              3,
+				RoomComfortScore:      true,
+				MissingAsNaN:          true,
+				RoomHeatingTime:       true,
+				UnderheatingThreshold: 2.5,
+				UnderheatingDuration:  2 * time.Hour,
+				HeatingSeasonWindow:   12 * time.Hour,
+				BoilerStatusMode:      "room",
+				BoilerStatusAvailable: true,
 			},
 			wantErr: nil,
 		},
@@ -153,6 +311,24 @@ func TestParseConfig(t *testing.T) {
 			},
 			wantErr: errNoNetatmoClientSecret,
 		},
+		{
+			name: "no push job",
+			args: []string{
+				"test-cmd",
+				"--" + flagTokenFile,
+				"token-file",
+				"--" + flagNetatmoClientID,
+				"id",
+				"--" + flagNetatmoClientSecret,
+				"secret",
+				"--" + flagPushGateway,
+				"http://pushgateway:9091",
+				"--" + flagPushJob,
+				"",
+			},
+			env:     map[string]string{},
+			wantErr: errNoPushJob,
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,139 @@
+package mqtt
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	paho "github.com/eclipse/paho.mqtt.golang"
+	"github.com/sirupsen/logrus"
+
+	"github.com/xperimental/netatmo-exporter/v2/internal/collector"
+)
+
+const (
+	clientID       = "netatmo-exporter"
+	publishTimeout = 10 * time.Second
+)
+
+// topicReplacer replaces the characters which have a special meaning in MQTT topics.
+var topicReplacer = strings.NewReplacer("/", "_", "+", "_", "#", "_")
+
+// Config contains the settings for connecting to the MQTT broker.
+type Config struct {
+	// Broker is the URL of the broker, for example "tcp://localhost:1883".
+	Broker   string
+	Username string
+	Password string
+	// TopicPrefix is the first level of all topics.
+	TopicPrefix string
+}
+
+// Publisher implements collector.StatePublisher by publishing the state of the thermostats to an MQTT broker.
+// The state is handed over to a background goroutine, so that an unavailable broker does not delay the scrape.
+// If the previous state has not been published yet, the new state is dropped.
+type Publisher struct {
+	log         logrus.FieldLogger
+	client      paho.Client
+	topicPrefix string
+	states      chan []collector.ThermostatState
+}
+
+// message contains a single value to publish.
+type message struct {
+	Topic   string
+	Payload string
+}
+
+// New creates a Publisher and starts connecting to the broker in the background.
+func New(log logrus.FieldLogger, cfg Config) *Publisher {
+	opts := paho.NewClientOptions().
+		AddBroker(cfg.Broker).
+		SetClientID(clientID).
+		SetUsername(cfg.Username).
+		SetPassword(cfg.Password).
+		SetAutoReconnect(true).
+		SetConnectRetry(true).
+		SetConnectionLostHandler(func(_ paho.Client, err error) {
+			log.Warnf("MQTT: connection to %s lost: %s", cfg.Broker, err)
+		}).
+		SetOnConnectHandler(func(_ paho.Client) {
+			log.Infof("MQTT: connected to %s", cfg.Broker)
+		})
+
+	p := &Publisher{
+		log:         log,
+		client:      paho.NewClient(opts),
+		topicPrefix: cfg.TopicPrefix,
+		states:      make(chan []collector.ThermostatState, 1),
+	}
+
+	// With SetConnectRetry the client keeps trying to connect in the background.
+	p.client.Connect()
+	go p.run()
+
+	return p
+}
+
+// Publish implements collector.StatePublisher.
+func (p *Publisher) Publish(homes []collector.ThermostatState) {
+	select {
+	case p.states <- homes:
+	default:
+		p.log.Debug("MQTT: previous state not published yet, dropping state")
+	}
+}
+
+func (p *Publisher) run() {
+	for homes := range p.states {
+		for _, msg := range messages(p.topicPrefix, homes) {
+			token := p.client.Publish(msg.Topic, 0, true, msg.Payload)
+			if !token.WaitTimeout(publishTimeout) {
+				p.log.Warnf("MQTT: timeout publishing to %s", msg.Topic)
+				continue
+			}
+
+			if err := token.Error(); err != nil {
+				p.log.Warnf("MQTT: error publishing to %s: %s", msg.Topic, err)
+			}
+		}
+	}
+}
+
+// messages creates the messages for the state of the homes. Values which are not known are not published.
+func messages(prefix string, homes []collector.ThermostatState) []message {
+	result := []message{}
+	add := func(value *float64, levels ...string) {
+		if value == nil {
+			return
+		}
+
+		result = append(result, message{
+			Topic:   topic(prefix, levels...),
+			Payload: strconv.FormatFloat(*value, 'f', -1, 64),
+		})
+	}
+
+	for _, home := range homes {
+		add(home.BoilerStatus, home.HomeName, "boiler_status")
+
+		for _, room := range home.Rooms {
+			add(room.Temperature, home.HomeName, room.Name, "temperature")
+			add(room.Setpoint, home.HomeName, room.Name, "setpoint")
+			add(room.BoilerStatus, home.HomeName, room.Name, "boiler_status")
+		}
+	}
+
+	return result
+}
+
+// topic joins the levels to a topic below the prefix. Characters with a special meaning are removed from the
+// levels, so that names like "Kitchen/Dining" do not create additional levels.
+func topic(prefix string, levels ...string) string {
+	parts := []string{prefix}
+	for _, level := range levels {
+		parts = append(parts, topicReplacer.Replace(level))
+	}
+
+	return strings.Join(parts, "/")
+}
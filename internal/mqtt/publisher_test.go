@@ -0,0 +1,50 @@
+package mqtt
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/xperimental/netatmo-exporter/v2/internal/collector"
+)
+
+func TestMessages(t *testing.T) {
+	floatPtr := func(f float64) *float64 {
+		return &f
+	}
+
+	homes := []collector.ThermostatState{
+		{
+			HomeID:       "home",
+			HomeName:     "Home",
+			BoilerStatus: floatPtr(1),
+			Rooms: []collector.RoomState{
+				{
+					ID:           "living",
+					Name:         "Kitchen/Dining",
+					Temperature:  floatPtr(20.5),
+					Setpoint:     floatPtr(21),
+					BoilerStatus: floatPtr(0),
+				},
+				{
+					ID:          "bath",
+					Name:        "Bath #1",
+					Temperature: floatPtr(19),
+				},
+			},
+		},
+	}
+
+	want := []message{
+		{Topic: "netatmo/Home/boiler_status", Payload: "1"},
+		{Topic: "netatmo/Home/Kitchen_Dining/temperature", Payload: "20.5"},
+		{Topic: "netatmo/Home/Kitchen_Dining/setpoint", Payload: "21"},
+		{Topic: "netatmo/Home/Kitchen_Dining/boiler_status", Payload: "0"},
+		{Topic: "netatmo/Home/Bath _1/temperature", Payload: "19"},
+	}
+
+	got := messages("netatmo", homes)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("messages differ: -want +got\n%s", diff)
+	}
+}
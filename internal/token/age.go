@@ -0,0 +1,61 @@
+package token
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// RefreshTokenAge records since when the current refresh token is in use. Netatmo normally sends a new refresh
+// token with every refresh of the access token, so a growing age shows that the refresh token is not rotated.
+type RefreshTokenAge struct {
+	lock         sync.Mutex
+	clock        func() time.Time
+	refreshToken string
+	since        time.Time
+}
+
+// NewRefreshTokenAge creates a RefreshTokenAge, which has not seen a refresh token yet.
+func NewRefreshTokenAge() *RefreshTokenAge {
+	return &RefreshTokenAge{
+		clock: time.Now,
+	}
+}
+
+// Restore sets the time since when refreshToken is in use, for example from the token file.
+func (a *RefreshTokenAge) Restore(refreshToken string, since time.Time) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	a.refreshToken = refreshToken
+	a.since = since
+}
+
+// Observe records the refresh token of token and returns the time since when it is in use. A token without a
+// refresh token returns the zero time.
+func (a *RefreshTokenAge) Observe(token *oauth2.Token) time.Time {
+	if token == nil || token.RefreshToken == "" {
+		return time.Time{}
+	}
+
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if token.RefreshToken != a.refreshToken || a.since.IsZero() {
+		a.refreshToken = token.RefreshToken
+		a.since = a.clock()
+	}
+
+	return a.since
+}
+
+// age returns the time the refresh token of token has been in use.
+func (a *RefreshTokenAge) age(token *oauth2.Token) (time.Duration, bool) {
+	since := a.Observe(token)
+	if since.IsZero() {
+		return 0, false
+	}
+
+	return a.clock().Sub(since), true
+}
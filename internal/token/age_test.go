@@ -0,0 +1,69 @@
+package token
+
+import (
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+func TestRefreshTokenAge(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	age := NewRefreshTokenAge()
+	age.clock = func() time.Time {
+		return now
+	}
+	age.Restore("restored", now.Add(-time.Hour))
+
+	tt := []struct {
+		desc         string
+		advance      time.Duration
+		refreshToken string
+		wantAge      time.Duration
+		wantOk       bool
+	}{
+		{
+			desc:         "restored token",
+			refreshToken: "restored",
+			wantAge:      time.Hour,
+			wantOk:       true,
+		},
+		{
+			desc:         "rotated",
+			advance:      time.Minute,
+			refreshToken: "first",
+			wantAge:      0,
+			wantOk:       true,
+		},
+		{
+			desc:         "same token",
+			advance:      3 * time.Hour,
+			refreshToken: "first",
+			wantAge:      3 * time.Hour,
+			wantOk:       true,
+		},
+		{
+			desc:         "no refresh token",
+			advance:      time.Minute,
+			refreshToken: "",
+			wantAge:      0,
+			wantOk:       false,
+		},
+		{
+			desc:         "rotated again",
+			advance:      time.Minute,
+			refreshToken: "second",
+			wantAge:      0,
+			wantOk:       true,
+		},
+	}
+
+	for _, tc := range tt {
+		now = now.Add(tc.advance)
+
+		got, ok := age.age(&oauth2.Token{RefreshToken: tc.refreshToken})
+		if got != tc.wantAge || ok != tc.wantOk {
+			t.Errorf("%s: got age %s (%v), want %s (%v)", tc.desc, got, ok, tc.wantAge, tc.wantOk)
+		}
+	}
+}
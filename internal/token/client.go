@@ -0,0 +1,133 @@
+package token
+
+import (
+	"context"
+	"sync"
+
+	netatmo "github.com/exzz/netatmo-api-go"
+	"golang.org/x/oauth2"
+)
+
+// Endpoint contains the OAuth endpoints of the Netatmo API.
+var Endpoint = oauth2.Endpoint{
+	AuthURL:  "https://api.netatmo.com/oauth2/authorize",
+	TokenURL: "https://api.netatmo.com/oauth2/token",
+}
+
+// Client authenticates the exporter and refreshes its token using configurable OAuth endpoints. It replaces the
+// authentication of the netatmo-api-go client, whose endpoints can not be changed.
+type Client struct {
+	config   netatmo.Config
+	oauth    *oauth2.Config
+	callback func(*oauth2.Token)
+
+	lock   sync.Mutex
+	source oauth2.TokenSource
+	last   *oauth2.Token
+}
+
+// NewClient creates an unauthenticated Client using the OAuth endpoints. Empty URLs of the endpoint use the URLs of
+// the Netatmo API. The callback is called with every refreshed token.
+func NewClient(config netatmo.Config, endpoint oauth2.Endpoint, callback func(*oauth2.Token)) *Client {
+	if endpoint.AuthURL == "" {
+		endpoint.AuthURL = Endpoint.AuthURL
+	}
+	if endpoint.TokenURL == "" {
+		endpoint.TokenURL = Endpoint.TokenURL
+	}
+
+	return &Client{
+		config: config,
+		oauth: &oauth2.Config{
+			ClientID:     config.ClientID,
+			ClientSecret: config.ClientSecret,
+			Scopes:       []string{"read_station"},
+			Endpoint:     endpoint,
+		},
+		callback: callback,
+	}
+}
+
+// AuthCodeURL creates the URL of the authorization endpoint the user needs to be sent to.
+func (c *Client) AuthCodeURL(redirectURL, state string) string {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.oauth.RedirectURL = redirectURL
+	return c.oauth.AuthCodeURL(state)
+}
+
+// Exchange converts an authorization code into a token and authenticates the client using it.
+func (c *Client) Exchange(ctx context.Context, code, state string) error {
+	c.lock.Lock()
+	oauth := *c.oauth
+	c.lock.Unlock()
+
+	token, err := oauth.Exchange(ctx, code, oauth2.SetAuthURLParam("state", state))
+	if err != nil {
+		return err
+	}
+
+	c.InitWithToken(ctx, token)
+	return nil
+}
+
+// InitWithToken authenticates the client using an existing token, for example from the token file.
+func (c *Client) InitWithToken(ctx context.Context, token *oauth2.Token) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.source = c.oauth.TokenSource(ctx, token)
+	c.last = token
+}
+
+// CurrentToken returns a valid token, which is refreshed first if it has expired. It returns
+// netatmo.ErrNotAuthenticated if the client has not been authenticated yet.
+func (c *Client) CurrentToken() (*oauth2.Token, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.source == nil {
+		return nil, netatmo.ErrNotAuthenticated
+	}
+
+	token, err := c.source.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	if c.callback != nil && changed(c.last, token) {
+		c.callback(token)
+	}
+	c.last = token
+
+	return token, nil
+}
+
+// Read returns the weather stations of the account using the netatmo-api-go client. The client gets the current
+// access token without the refresh token, so that the token is only refreshed by this client.
+func (c *Client) Read() (*netatmo.DeviceCollection, error) {
+	token, err := c.CurrentToken()
+	if err != nil {
+		return nil, err
+	}
+
+	stations := netatmo.NewClient(c.config, nil)
+	stations.InitWithToken(context.Background(), &oauth2.Token{
+		AccessToken: token.AccessToken,
+		TokenType:   token.TokenType,
+		Expiry:      token.Expiry,
+	})
+
+	return stations.Read()
+}
+
+func changed(old, current *oauth2.Token) bool {
+	if old == nil {
+		return true
+	}
+
+	return old.AccessToken != current.AccessToken ||
+		old.RefreshToken != current.RefreshToken ||
+		!old.Expiry.Equal(current.Expiry)
+}
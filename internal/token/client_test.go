@@ -0,0 +1,68 @@
+package token
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	netatmo "github.com/exzz/netatmo-api-go"
+	"golang.org/x/oauth2"
+)
+
+func TestClient_Endpoint(t *testing.T) {
+	var refreshes int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/oauth2/token" || r.FormValue("refresh_token") != "old-refresh" {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+
+		refreshes++
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token":"new-access","refresh_token":"new-refresh","token_type":"bearer","expires_in":10800}`))
+	}))
+	defer server.Close()
+
+	var updated []string
+	client := NewClient(netatmo.Config{ClientID: "id", ClientSecret: "secret"}, oauth2.Endpoint{
+		AuthURL:  server.URL + "/oauth2/authorize",
+		TokenURL: server.URL + "/oauth2/token",
+	}, func(token *oauth2.Token) {
+		updated = append(updated, token.AccessToken)
+	})
+
+	if authURL := client.AuthCodeURL("http://127.0.0.1:9210/auth/callback", "state"); !strings.HasPrefix(authURL, server.URL+"/oauth2/authorize?") {
+		t.Errorf("got authorization URL %q, want the test server", authURL)
+	}
+
+	if _, err := client.CurrentToken(); err != netatmo.ErrNotAuthenticated {
+		t.Errorf("got error %v before authentication, want %v", err, netatmo.ErrNotAuthenticated)
+	}
+
+	client.InitWithToken(context.Background(), &oauth2.Token{
+		AccessToken:  "old-access",
+		RefreshToken: "old-refresh",
+		Expiry:       time.Now().Add(-time.Minute),
+	})
+	for i := 0; i < 2; i++ {
+		token, err := client.CurrentToken()
+		if err != nil {
+			t.Fatalf("got error: %s", err)
+		}
+
+		if token.AccessToken != "new-access" {
+			t.Errorf("got access token %q, want %q", token.AccessToken, "new-access")
+		}
+	}
+
+	if refreshes != 1 {
+		t.Errorf("got %d refreshes, want 1", refreshes)
+	}
+
+	if len(updated) != 1 || updated[0] != "new-access" {
+		t.Errorf("got updated tokens %v, want [new-access]", updated)
+	}
+}
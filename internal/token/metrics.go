@@ -1,6 +1,8 @@
 package token
 
 import (
+	"strings"
+
 	"github.com/prometheus/client_golang/prometheus"
 	"golang.org/x/oauth2"
 )
@@ -19,21 +21,44 @@ var (
 		prefix+"expiry_time",
 		"Set to the unix timestamp when the token will expire. 0 if no expiry is set.",
 		nil, nil)
+
+	scopesDesc = prometheus.NewDesc(
+		"netatmo_oauth_scopes_info",
+		"Contains the OAuth scopes granted to the token as a comma-separated list. Not reported while the scopes are unknown.",
+		[]string{"scopes"}, nil)
+
+	refreshTokenAgeDesc = prometheus.NewDesc(
+		"netatmo_refresh_token_age_seconds",
+		"Contains the number of seconds the current refresh token has been in use. Not reported without a refresh token.",
+		nil, nil)
 )
 
-func Metric(tokenFunc func() (*oauth2.Token, error)) prometheus.Collector {
+// RefreshCounter creates a counter for the refreshes of the access token. It needs to be incremented by the
+// callback of the NetAtmo client, which is only called when the token source returned a new token.
+func RefreshCounter() prometheus.Counter {
+	return prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "netatmo_oauth_token_refreshes_total",
+		Help: "Number of times the access token has been refreshed since the exporter was started.",
+	})
+}
+
+func Metric(tokenFunc func() (*oauth2.Token, error), age *RefreshTokenAge) prometheus.Collector {
 	return &tokenMetric{
 		tokenFunc: tokenFunc,
+		age:       age,
 	}
 }
 
 type tokenMetric struct {
 	tokenFunc func() (*oauth2.Token, error)
+	age       *RefreshTokenAge
 }
 
 func (t tokenMetric) Describe(dChan chan<- *prometheus.Desc) {
 	dChan <- validDesc
 	dChan <- expiryDesc
+	dChan <- scopesDesc
+	dChan <- refreshTokenAgeDesc
 }
 
 func (t tokenMetric) Collect(mChan chan<- prometheus.Metric) {
@@ -49,4 +74,12 @@ func (t tokenMetric) Collect(mChan chan<- prometheus.Metric) {
 
 	mChan <- prometheus.MustNewConstMetric(validDesc, prometheus.GaugeValue, validValue)
 	mChan <- prometheus.MustNewConstMetric(expiryDesc, prometheus.GaugeValue, expiryValue)
+
+	if scopes := Scopes(token); valid && len(scopes) > 0 {
+		mChan <- prometheus.MustNewConstMetric(scopesDesc, prometheus.GaugeValue, 1, strings.Join(scopes, ","))
+	}
+
+	if age, ok := t.age.age(token); ok {
+		mChan <- prometheus.MustNewConstMetric(refreshTokenAgeDesc, prometheus.GaugeValue, age.Seconds())
+	}
 }
@@ -0,0 +1,49 @@
+package token
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestRefreshCounter(t *testing.T) {
+	counter := RefreshCounter()
+
+	tt := []struct {
+		desc    string
+		updates int
+		want    int
+	}{
+		{
+			desc:    "before first refresh",
+			updates: 0,
+			want:    0,
+		},
+		{
+			desc:    "first refresh",
+			updates: 1,
+			want:    1,
+		},
+		{
+			desc:    "more refreshes",
+			updates: 2,
+			want:    3,
+		},
+	}
+
+	for _, tc := range tt {
+		for i := 0; i < tc.updates; i++ {
+			counter.Inc()
+		}
+
+		want := fmt.Sprintf(`# HELP netatmo_oauth_token_refreshes_total Number of times the access token has been refreshed since the exporter was started.
+# TYPE netatmo_oauth_token_refreshes_total counter
+netatmo_oauth_token_refreshes_total %d
+`, tc.want)
+		if err := testutil.CollectAndCompare(counter, strings.NewReader(want)); err != nil {
+			t.Errorf("%s: metrics differ: %s", tc.desc, err)
+		}
+	}
+}
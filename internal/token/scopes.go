@@ -0,0 +1,40 @@
+package token
+
+import (
+	"sort"
+	"strings"
+
+	"golang.org/x/oauth2"
+)
+
+// Scopes returns the OAuth scopes granted to the token. The scopes are taken from the "scope" field of the token
+// response, which Netatmo sends as a list. Tokens without this field, for example restored from an older token
+// file, return nil.
+func Scopes(token *oauth2.Token) []string {
+	if token == nil {
+		return nil
+	}
+
+	var scopes []string
+	switch raw := token.Extra("scope").(type) {
+	case string:
+		scopes = strings.FieldsFunc(raw, func(r rune) bool {
+			return r == ' ' || r == ','
+		})
+	case []string:
+		scopes = append(scopes, raw...)
+	case []interface{}:
+		for _, s := range raw {
+			if scope, ok := s.(string); ok {
+				scopes = append(scopes, scope)
+			}
+		}
+	}
+
+	if len(scopes) == 0 {
+		return nil
+	}
+
+	sort.Strings(scopes)
+	return scopes
+}
@@ -0,0 +1,61 @@
+package token
+
+import (
+	"reflect"
+	"testing"
+
+	"golang.org/x/oauth2"
+)
+
+func TestScopes(t *testing.T) {
+	tests := []struct {
+		name  string
+		token *oauth2.Token
+		want  []string
+	}{
+		{
+			name:  "no token",
+			token: nil,
+			want:  nil,
+		},
+		{
+			name:  "no scope",
+			token: &oauth2.Token{AccessToken: "token"},
+			want:  nil,
+		},
+		{
+			name: "json list",
+			token: (&oauth2.Token{}).WithExtra(map[string]interface{}{
+				"scope": []interface{}{"read_thermostat", "read_station"},
+			}),
+			want: []string{"read_station", "read_thermostat"},
+		},
+		{
+			name: "string list",
+			token: (&oauth2.Token{}).WithExtra(map[string]interface{}{
+				"scope": []string{"read_station"},
+			}),
+			want: []string{"read_station"},
+		},
+		{
+			name: "space separated",
+			token: (&oauth2.Token{}).WithExtra(map[string]interface{}{
+				"scope": "read_thermostat read_camera",
+			}),
+			want: []string{"read_camera", "read_thermostat"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := Scopes(tt.token)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got scopes %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package web
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// MetricsHandler creates a handler which serves the metrics of gatherer. If the request has a "home_id" parameter,
+// the metrics of the gatherer returned by homeFunc for that home are served instead, so that a single home can be
+// collected without waiting for all other homes.
+//
+// If readyFunc is not nil, the request fails with status 503 as long as it returns false after gathering the
+// metrics, so that Prometheus reports the target as down until the exporter is ready.
+func MetricsHandler(gatherer prometheus.Gatherer, readyFunc func() bool, homeFunc func(homeID string) prometheus.Gatherer) http.Handler {
+	return http.HandlerFunc(func(wr http.ResponseWriter, r *http.Request) {
+		source := gatherer
+		if homeID := r.URL.Query().Get("home_id"); homeID != "" {
+			source = homeFunc(homeID)
+		}
+
+		// The metrics are gathered before checking the readiness, because gathering them collects the data.
+		families, err := source.Gather()
+		if readyFunc != nil && !readyFunc() {
+			http.Error(wr, "No data has been collected successfully yet.", http.StatusServiceUnavailable)
+			return
+		}
+
+		gathered := prometheus.GathererFunc(func() ([]*dto.MetricFamily, error) {
+			return families, err
+		})
+		promhttp.HandlerFor(gathered, promhttp.HandlerOpts{}).ServeHTTP(wr, r)
+	})
+}
@@ -0,0 +1,101 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestMetricsHandler(t *testing.T) {
+	gatherer := func(value float64) prometheus.Gatherer {
+		registry := prometheus.NewRegistry()
+		gauge := prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "test_metric",
+			Help: "Test metric.",
+		})
+		gauge.Set(value)
+		registry.MustRegister(gauge)
+
+		return registry
+	}
+
+	tt := []struct {
+		desc     string
+		url      string
+		ready    func() bool
+		wantCode int
+		wantBody string
+	}{
+		{
+			desc:     "all homes",
+			url:      "/metrics",
+			wantCode: http.StatusOK,
+			wantBody: `# HELP test_metric Test metric.
+# TYPE test_metric gauge
+test_metric 1
+`,
+		},
+		{
+			desc:     "single home",
+			url:      "/metrics?home_id=house",
+			wantCode: http.StatusOK,
+			wantBody: `# HELP test_metric Test metric.
+# TYPE test_metric gauge
+test_metric 2
+`,
+		},
+		{
+			desc: "ready",
+			url:  "/metrics",
+			ready: func() bool {
+				return true
+			},
+			wantCode: http.StatusOK,
+			wantBody: `# HELP test_metric Test metric.
+# TYPE test_metric gauge
+test_metric 1
+`,
+		},
+		{
+			desc: "not ready",
+			url:  "/metrics",
+			ready: func() bool {
+				return false
+			},
+			wantCode: http.StatusServiceUnavailable,
+			wantBody: "No data has been collected successfully yet.\n",
+		},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, tc.url, nil)
+
+			h := MetricsHandler(gatherer(1), tc.ready, func(homeID string) prometheus.Gatherer {
+				if homeID != "house" {
+					t.Errorf("got home %q, want %q", homeID, "house")
+				}
+
+				return gatherer(2)
+			})
+
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tc.wantCode {
+				t.Errorf("got code %d, want %d", rec.Code, tc.wantCode)
+			}
+
+			body := rec.Body.String()
+			if diff := cmp.Diff(body, tc.wantBody); diff != "" {
+				t.Errorf("body differs: -got+want\n%s", diff)
+			}
+		})
+	}
+}
@@ -7,11 +7,11 @@ import (
 	"net/http"
 	"net/url"
 
-	"github.com/exzz/netatmo-api-go"
+	"github.com/xperimental/netatmo-exporter/v2/internal/token"
 	"golang.org/x/oauth2"
 )
 
-func AuthorizeHandler(externalURL string, client *netatmo.Client) http.HandlerFunc {
+func AuthorizeHandler(externalURL string, client *token.Client) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		redirectURL := externalURL + "/auth/callback"
 		authURL := client.AuthCodeURL(redirectURL, "definitelyrandom")
@@ -20,7 +20,7 @@ func AuthorizeHandler(externalURL string, client *netatmo.Client) http.HandlerFu
 	}
 }
 
-func CallbackHandler(ctx context.Context, client *netatmo.Client) http.HandlerFunc {
+func CallbackHandler(ctx context.Context, client *token.Client) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		values := r.URL.Query()
 		if err := doCallback(ctx, client, values); err != nil {
@@ -33,7 +33,7 @@ func CallbackHandler(ctx context.Context, client *netatmo.Client) http.HandlerFu
 	}
 }
 
-func doCallback(ctx context.Context, client *netatmo.Client, query url.Values) error {
+func doCallback(ctx context.Context, client *token.Client, query url.Values) error {
 	if err := query.Get("error"); err != "" {
 		return errors.New("user did not accept")
 	}
@@ -44,7 +44,7 @@ func doCallback(ctx context.Context, client *netatmo.Client, query url.Values) e
 	return client.Exchange(ctx, code, state)
 }
 
-func SetTokenHandler(ctx context.Context, client *netatmo.Client) http.HandlerFunc {
+func SetTokenHandler(ctx context.Context, client *token.Client) http.HandlerFunc {
 	return func(wr http.ResponseWriter, r *http.Request) {
 		refreshToken := r.FormValue("refresh_token")
 		if refreshToken == "" {
@@ -52,10 +52,9 @@ func SetTokenHandler(ctx context.Context, client *netatmo.Client) http.HandlerFu
 			return
 		}
 
-		token := &oauth2.Token{
+		client.InitWithToken(ctx, &oauth2.Token{
 			RefreshToken: refreshToken,
-		}
-		client.InitWithToken(ctx, token)
+		})
 
 		http.Redirect(wr, r, "/", http.StatusFound)
 	}
@@ -0,0 +1,30 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/xperimental/netatmo-exporter/v2/internal/collector"
+)
+
+// StateHandler creates a handler which returns the latest collected state of the thermostats as JSON.
+// It does not request any data from the Netatmo API.
+func StateHandler(log logrus.FieldLogger, statesFunc func() []collector.StoredState) http.Handler {
+	return http.HandlerFunc(func(wr http.ResponseWriter, r *http.Request) {
+		data := struct {
+			Homes []collector.StoredState `json:"homes"`
+		}{
+			Homes: statesFunc(),
+		}
+
+		wr.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(wr)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(data); err != nil {
+			log.Errorf("Can not encode state response: %s", err)
+			return
+		}
+	})
+}
@@ -0,0 +1,97 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sirupsen/logrus"
+
+	"github.com/xperimental/netatmo-exporter/v2/internal/collector"
+)
+
+func TestStateHandler(t *testing.T) {
+	temperature := 20.5
+
+	tt := []struct {
+		desc     string
+		states   []collector.StoredState
+		wantBody string
+	}{
+		{
+			desc:   "no state",
+			states: []collector.StoredState{},
+			wantBody: `{
+  "homes": []
+}
+`,
+		},
+		{
+			desc: "success",
+			states: []collector.StoredState{
+				{
+					ThermostatState: collector.ThermostatState{
+						HomeID:   "home",
+						HomeName: "Home",
+						Rooms: []collector.RoomState{
+							{
+								ID:          "living",
+								Name:        "Living Room",
+								Temperature: &temperature,
+							},
+						},
+					},
+					Updated: time.Unix(0, 0).UTC(),
+				},
+			},
+			wantBody: `{
+  "homes": [
+    {
+      "homeId": "home",
+      "homeName": "Home",
+      "boilerStatus": null,
+      "rooms": [
+        {
+          "id": "living",
+          "name": "Living Room",
+          "temperature": 20.5,
+          "setpoint": null,
+          "boilerStatus": null
+        }
+      ],
+      "updated": "1970-01-01T00:00:00Z"
+    }
+  ]
+}
+`,
+		},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			t.Parallel()
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+
+			log := logrus.New()
+			h := StateHandler(log, func() []collector.StoredState {
+				return tc.states
+			})
+
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusOK {
+				t.Errorf("got code %d, want %d", rec.Code, http.StatusOK)
+			}
+
+			body := rec.Body.String()
+			if diff := cmp.Diff(body, tc.wantBody); diff != "" {
+				t.Errorf("body differs: -got+want\n%s", diff)
+			}
+		})
+	}
+}
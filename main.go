@@ -7,12 +7,14 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"regexp"
 	"syscall"
 	"time"
+	_ "time/tzdata"
 
 	netatmo "github.com/exzz/netatmo-api-go"
 	"github.com/prometheus/client_golang/prometheus"
-	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/pflag"
 	"golang.org/x/oauth2"
@@ -20,6 +22,7 @@ import (
 	"github.com/xperimental/netatmo-exporter/v2/internal/collector"
 	"github.com/xperimental/netatmo-exporter/v2/internal/config"
 	"github.com/xperimental/netatmo-exporter/v2/internal/logger"
+	"github.com/xperimental/netatmo-exporter/v2/internal/mqtt"
 	"github.com/xperimental/netatmo-exporter/v2/internal/token"
 	"github.com/xperimental/netatmo-exporter/v2/internal/web"
 )
@@ -46,16 +49,24 @@ func main() {
 	log.SetLevel(logrus.Level(cfg.LogLevel))
 	log.Infof("netatmo-exporter %s (commit: %s)", Version, GitCommit)
 
-	client := netatmo.NewClient(cfg.Netatmo, tokenUpdated(cfg.TokenFile))
+	tokenRefreshes := token.RefreshCounter()
+	refreshTokenAge := token.NewRefreshTokenAge()
+	endpoint := oauth2.Endpoint{
+		AuthURL:  cfg.OAuthAuthURL,
+		TokenURL: cfg.OAuthTokenURL,
+	}
+	client := token.NewClient(cfg.Netatmo, endpoint, tokenUpdated(cfg.TokenFile, tokenRefreshes, refreshTokenAge))
 
 	if cfg.TokenFile != "" {
-		token, err := loadToken(cfg.TokenFile)
+		token, err := loadToken(cfg.TokenFile, refreshTokenAge)
 		switch {
 		case os.IsNotExist(err):
 			// no token file yet
 		case err != nil:
 			log.Fatalf("Error loading token: %s", err)
-		case !token.Expiry.IsZero() && token.Expiry.Before(time.Now()):
+		case !token.Expiry.IsZero() && token.Expiry.Before(time.Now()) && (cfg.PushGateway == "" || token.RefreshToken == ""):
+			// In push mode the exporter usually runs less often than the token expires, so an expired token is
+			// kept if it can be refreshed.
 			log.Warn("Restored token has expired! Token has been ignored.")
 		default:
 			if token.RefreshToken == "" {
@@ -69,19 +80,176 @@ func main() {
 			client.InitWithToken(context.Background(), token)
 		}
 
-		registerSignalHandler(client, cfg.TokenFile)
+		registerSignalHandler(client, cfg.TokenFile, refreshTokenAge)
 	} else {
 		log.Warn("No token-file set! Authentication will be lost on restart.")
 	}
 
+	if !cfg.RuntimeMetrics {
+		// The default registry contains the Go runtime and process collectors.
+		prometheus.Unregister(collectors.NewGoCollector())
+		prometheus.Unregister(collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+	}
+
+	filter := collector.NewMetricFilter(cfg.EnabledMetrics, cfg.DisabledMetrics, cfg.MetricPrefix)
+	registerer := prometheus.DefaultRegisterer
+	if cfg.InstanceName != "" {
+		registerer = prometheus.WrapRegistererWith(prometheus.Labels{"instance_name": cfg.InstanceName}, registerer)
+	}
+	output := collector.NewOutput(collector.OutputConfig{
+		Filter:        filter,
+		Naming:        collector.NewMetricNaming(collector.NamingStrategy(cfg.MetricNaming), cfg.MetricNames),
+		Precision:     cfg.Precision,
+		MinimalLabels: cfg.MinimalLabels,
+	})
+	register := func(c prometheus.Collector) {
+		registerer.MustRegister(output.Wrap(c))
+	}
+	registerer.MustRegister(output)
+
+	readiness := collector.NewReadiness()
+	register(readiness)
+
 	metrics := collector.New(log, client.Read, cfg.RefreshInterval, cfg.StaleDuration)
-	prometheus.MustRegister(metrics)
+	metrics.DualUnits = cfg.DualUnits
+	metrics.Readiness = readiness
+	register(metrics)
 
-	thermostatMetrics := collector.NewThermostatCollector(log, client.CurrentToken)
-	prometheus.MustRegister(thermostatMetrics)
+	apiStats := collector.NewAPIStats(log, cfg.APILatencyPerHome)
+	register(apiStats)
+
+	retry := collector.RetryConfig{
+		MaxRetries: cfg.APIRetries,
+		BaseDelay:  cfg.APIRetryDelay,
+		MaxDelay:   cfg.APIRetryMaxDelay,
+	}
+	transport := collector.TransportConfig{
+		MaxIdleConns:        cfg.APIMaxIdleConns,
+		MaxIdleConnsPerHost: cfg.APIMaxIdleConnsPerHost,
+		IdleConnTimeout:     cfg.APIIdleConnTimeout,
+	}
+	netatmoClient := collector.NewNetatmoClient(log, cfg.APIURL, client.CurrentToken, apiStats, cfg.RequestTimeout, retry, transport)
+	if cfg.ReplayDir != "" {
+		netatmoClient, err = collector.NewReplayClient(cfg.ReplayDir, apiStats)
+		if err != nil {
+			log.Fatalf("Error creating replay client: %s", err)
+		}
+		log.Warnf("Replaying responses recorded in %s instead of requesting the NetAtmo API.", cfg.ReplayDir)
+	}
+	apiClient := collector.NewSharedClient(netatmoClient)
+
+	attention := collector.AttentionThresholds{
+		BatteryPercent: cfg.AttentionBattery,
+		RFStrength:     cfg.AttentionRF,
+		WifiStrength:   cfg.AttentionWifi,
+	}
 
-	tokenMetric := token.Metric(client.CurrentToken)
-	prometheus.MustRegister(tokenMetric)
+	thermostatOpts := []collector.ThermostatOption{
+		collector.WithBoilerOnInterval(cfg.BoilerOnInterval),
+		collector.WithHomeStatusDelay(cfg.HomeStatusDelay),
+		collector.WithHomeStatusRetries(cfg.HomeStatusRetries, cfg.HomeStatusRetryDelay),
+		collector.WithHomeStatusCache(cfg.HomeStatusCacheTTL),
+		collector.WithHomeStatusConcurrency(cfg.HomeStatusConcurrency),
+		collector.WithHomeStatusDeviceTypes(cfg.HomeStatusDeviceTypes),
+		collector.WithHomesDataInterval(cfg.HomesDataInterval),
+		collector.WithBoilerSampleInterval(cfg.BoilerSampleInterval),
+		collector.WithDualUnits(cfg.DualUnits),
+		collector.WithCollectTimeout(cfg.CollectTimeout),
+		collector.WithMaxHomes(cfg.MaxHomes),
+		collector.WithComfortScore(cfg.RoomComfortScore),
+		collector.WithMissingAsNaN(cfg.MissingAsNaN),
+		collector.WithRoomHeatingTime(cfg.RoomHeatingTime),
+		collector.WithAttentionThresholds(attention),
+		collector.WithBoilerStatusMode(collector.BoilerStatusMode(cfg.BoilerStatusMode)),
+		collector.WithBoilerStatusAvailable(cfg.BoilerStatusAvailable),
+		collector.WithRoomDebug(cfg.DebugRooms),
+		collector.WithUnderheating(cfg.UnderheatingThreshold, cfg.UnderheatingDuration),
+		collector.WithHeatingSeason(cfg.HeatingSeasonWindow),
+		collector.WithReadiness(readiness),
+		collector.WithMetricPrefix(cfg.MetricPrefix),
+	}
+	stateStore := collector.NewStateStore()
+	thermostatOpts = append(thermostatOpts, collector.WithStatePublisher(stateStore))
+	if cfg.MQTTBroker != "" {
+		publisher := mqtt.New(log, mqtt.Config{
+			Broker:      cfg.MQTTBroker,
+			Username:    cfg.MQTTUsername,
+			Password:    cfg.MQTTPassword,
+			TopicPrefix: cfg.MQTTTopicPrefix,
+		})
+		thermostatOpts = append(thermostatOpts, collector.WithStatePublisher(publisher))
+	}
+	if len(cfg.IncludeHomes) > 0 {
+		thermostatOpts = append(thermostatOpts, collector.WithIncludedHomes(cfg.IncludeHomes))
+	}
+	if cfg.ExcludeHomes != "" {
+		thermostatOpts = append(thermostatOpts, collector.WithExcludedHomes(regexp.MustCompile(cfg.ExcludeHomes)))
+	}
+
+	thermostatMetrics := collector.NewThermostatCollector(log, apiClient, thermostatOpts...)
+	register(collector.Cached(thermostatMetrics, "thermostat", cfg.ThermostatInterval))
+	collectors := []string{"sensor", "thermostat"}
+
+	if cfg.WeatherCollector {
+		weatherMetrics := collector.NewWeatherCollector(log, apiClient, cfg.WeatherExtremes, cfg.DualUnits, attention, cfg.WeatherHumidex)
+		register(collector.Cached(weatherMetrics, "weather", cfg.WeatherInterval))
+		collectors = append(collectors, "weather")
+	}
+
+	if area := cfg.PublicDataArea; !area.IsZero() {
+		publicMetrics := collector.NewPublicDataCollector(log, apiClient, collector.BoundingBox{
+			LatSW: area.LatSW,
+			LonSW: area.LonSW,
+			LatNE: area.LatNE,
+			LonNE: area.LonNE,
+		})
+		register(publicMetrics)
+		collectors = append(collectors, "public")
+	}
+
+	if cfg.CameraCollector {
+		cameraMetrics := collector.NewCameraCollector(log, apiClient)
+		register(cameraMetrics)
+		collectors = append(collectors, "camera")
+	}
+
+	if cfg.LegacyThermostat {
+		legacyMetrics := collector.NewLegacyThermostatCollector(log, apiClient)
+		register(legacyMetrics)
+		collectors = append(collectors, "legacy_thermostat")
+	}
+
+	register(collector.ConfigInfo{
+		RefreshInterval: cfg.RefreshInterval,
+		DualUnits:       cfg.DualUnits,
+		Collectors:      collectors,
+		APIURL:          cfg.APIURL,
+		Replay:          cfg.ReplayDir != "",
+		MaxHomes:        cfg.MaxHomes,
+		Concurrency:     cfg.HomeStatusConcurrency,
+	})
+
+	// The token metrics are created by another package, so they are filtered after gathering them.
+	tokenRegistry := prometheus.NewRegistry()
+	tokenRegisterer := prometheus.Registerer(tokenRegistry)
+	if cfg.InstanceName != "" {
+		tokenRegisterer = prometheus.WrapRegistererWith(prometheus.Labels{"instance_name": cfg.InstanceName}, tokenRegistry)
+	}
+	tokenRegisterer.MustRegister(token.Metric(client.CurrentToken, refreshTokenAge), tokenRefreshes)
+	gatherer := prometheus.Gatherers{prometheus.DefaultGatherer, collector.FilterGatherer(tokenRegistry, filter)}
+
+	if cfg.PushGateway != "" {
+		if _, err := client.CurrentToken(); err != nil {
+			log.Fatalf("Push mode needs a valid token in the token file, start the exporter without --push-gateway once to authenticate: %s", err)
+		}
+
+		if err := pushMetrics(cfg, gatherer, metrics); err != nil {
+			log.Fatalf("Error pushing metrics: %s", err)
+		}
+
+		log.Infof("Pushed metrics to %s.", cfg.PushGateway)
+		return
+	}
 
 	if cfg.DebugHandlers {
 		http.Handle("/debug/data", web.DebugDataHandler(log, client.Read))
@@ -91,34 +259,62 @@ func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	go thermostatMetrics.RunBoilerSampler(ctx)
+
 	http.Handle("/auth/authorize", web.AuthorizeHandler(cfg.ExternalURL, client))
 	http.Handle("/auth/callback", web.CallbackHandler(ctx, client))
 	http.Handle("/auth/settoken", web.SetTokenHandler(ctx, client))
 
-	http.Handle("/metrics", promhttp.HandlerFor(prometheus.DefaultGatherer, promhttp.HandlerOpts{}))
+	var readyFunc func() bool
+	if cfg.FailUntilReady {
+		readyFunc = readiness.Ready
+	}
+	http.Handle("/metrics", web.MetricsHandler(gatherer, readyFunc, func(homeID string) prometheus.Gatherer {
+		registry := prometheus.NewRegistry()
+		homeRegisterer := prometheus.Registerer(registry)
+		if cfg.InstanceName != "" {
+			homeRegisterer = prometheus.WrapRegistererWith(prometheus.Labels{"instance_name": cfg.InstanceName}, registry)
+		}
+		homeRegisterer.MustRegister(output.WrapTransient(thermostatMetrics.Home(homeID)))
+
+		return registry
+	}))
 	http.Handle("/version", versionHandler(log))
+	http.Handle("/api/state", web.StateHandler(log, stateStore.States))
 	http.Handle("/", web.HomeHandler(client.CurrentToken))
 
 	log.Infof("Listen on %s...", cfg.Addr)
 	log.Fatal(http.ListenAndServe(cfg.Addr, nil))
 }
 
-func loadToken(fileName string) (*oauth2.Token, error) {
+func loadToken(fileName string, age *token.RefreshTokenAge) (*oauth2.Token, error) {
 	file, err := os.Open(fileName)
 	if err != nil {
 		return nil, err
 	}
 	defer file.Close()
 
-	var token oauth2.Token
-	if err := json.NewDecoder(file).Decode(&token); err != nil {
+	stored := tokenFile{
+		Token: &oauth2.Token{},
+	}
+	if err := json.NewDecoder(file).Decode(&stored); err != nil {
 		return nil, err
 	}
 
-	return &token, nil
+	if stored.RefreshTokenSince != nil && stored.RefreshToken != "" {
+		age.Restore(stored.RefreshToken, *stored.RefreshTokenSince)
+	}
+
+	if len(stored.Scope) == 0 {
+		return stored.Token, nil
+	}
+
+	return stored.Token.WithExtra(map[string]interface{}{
+		"scope": stored.Scope,
+	}), nil
 }
 
-func registerSignalHandler(client *netatmo.Client, fileName string) {
+func registerSignalHandler(client *token.Client, fileName string, age *token.RefreshTokenAge) {
 	ch := make(chan os.Signal, 1)
 	signal.Notify(ch, signals...)
 
@@ -128,7 +324,7 @@ func registerSignalHandler(client *netatmo.Client, fileName string) {
 
 		log.Debugf("Got signal: %s", sig)
 
-		if err := saveToken(client, fileName); err != nil {
+		if err := saveToken(client, fileName, age); err != nil {
 			log.Errorf("Error persisting token: %s", err)
 		}
 
@@ -136,21 +332,23 @@ func registerSignalHandler(client *netatmo.Client, fileName string) {
 	}()
 }
 
-func tokenUpdated(fileName string) netatmo.TokenUpdateFunc {
-	if fileName == "" {
-		return nil
-	}
+func tokenUpdated(fileName string, refreshes prometheus.Counter, age *token.RefreshTokenAge) func(*oauth2.Token) {
+	return func(t *oauth2.Token) {
+		log.Debugf("Token updated. Expires: %s", t.Expiry)
+		refreshes.Inc()
+		age.Observe(t)
 
-	return func(token *oauth2.Token) {
-		log.Debugf("Token updated. Expires: %s", token.Expiry)
+		if fileName == "" {
+			return
+		}
 
-		if err := saveTokenFile(fileName, token); err != nil {
+		if err := saveTokenFile(fileName, t, age); err != nil {
 			log.Errorf("Error saving token: %s", err)
 		}
 	}
 }
 
-func saveToken(client *netatmo.Client, fileName string) error {
+func saveToken(client *token.Client, fileName string, age *token.RefreshTokenAge) error {
 	token, err := client.CurrentToken()
 	switch {
 	case err == netatmo.ErrNotAuthenticated:
@@ -162,11 +360,27 @@ func saveToken(client *netatmo.Client, fileName string) error {
 
 	log.Infof("Saving token to %s ...", fileName)
 
-	return saveTokenFile(fileName, token)
+	return saveTokenFile(fileName, token, age)
+}
+
+// tokenFile is the content of the token file. The scopes and the time since when the refresh token is in use
+// are stored next to the token, because they are not part of the exported fields of oauth2.Token.
+type tokenFile struct {
+	*oauth2.Token
+	Scope             []string   `json:"scope,omitempty"`
+	RefreshTokenSince *time.Time `json:"refresh_token_since,omitempty"`
 }
 
-func saveTokenFile(fileName string, token *oauth2.Token) error {
-	data, err := json.Marshal(token)
+func saveTokenFile(fileName string, t *oauth2.Token, age *token.RefreshTokenAge) error {
+	stored := tokenFile{
+		Token: t,
+		Scope: token.Scopes(t),
+	}
+	if since := age.Observe(t); !since.IsZero() {
+		stored.RefreshTokenSince = &since
+	}
+
+	data, err := json.Marshal(stored)
 	if err != nil {
 		return fmt.Errorf("error marshalling token: %w", err)
 	}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/push"
+
+	"github.com/xperimental/netatmo-exporter/v2/internal/collector"
+	"github.com/xperimental/netatmo-exporter/v2/internal/config"
+)
+
+// pushMetrics runs one collection of all registered collectors and pushes the result to the Pushgateway. The
+// weather data is refreshed before, because the NetatmoCollector otherwise only refreshes in the background.
+func pushMetrics(cfg config.Config, gatherer prometheus.Gatherer, metrics *collector.NetatmoCollector) error {
+	metrics.RefreshData(time.Now())
+
+	pusher := push.New(cfg.PushGateway, cfg.PushJob).Gatherer(gatherer)
+	if cfg.InstanceName != "" {
+		// Exporters pushing with the same job would replace each other's metrics without a grouping key.
+		pusher = pusher.Grouping("instance", cfg.InstanceName)
+	}
+
+	if err := pusher.Push(); err != nil {
+		return fmt.Errorf("error pushing to %s: %w", cfg.PushGateway, err)
+	}
+
+	return nil
+}